@@ -27,11 +27,15 @@ import (
 	"os"
 	"os/signal"
 	"time"
+	_ "time/tzdata" // timezone database for profile validation; the runtime image does not ship one
 
 	"github.com/joho/godotenv"
 	"github.com/kfeuerschvenger/task-manager-api/database"
 	_ "github.com/kfeuerschvenger/task-manager-api/docs"
+	"github.com/kfeuerschvenger/task-manager-api/models"
 	"github.com/kfeuerschvenger/task-manager-api/routes"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
 )
 
 func main() {
@@ -56,6 +60,22 @@ func main() {
 		}
 		runMigrations(direction)
 		return
+	case "make-admin":
+		if len(args) < 2 {
+			log.Fatal("Usage: make-admin <email>")
+		}
+		makeAdmin(args[1])
+		return
+	case "generate-key":
+		keyType := "ed25519"
+		if len(args) > 1 {
+			keyType = args[1]
+		}
+		generateKey(keyType)
+		return
+	case "purge-accounts":
+		purgeAccounts()
+		return
 	case "serve":
 		// Automatically apply migrations before starting
 		if err := database.MigrateUp(); err != nil {
@@ -67,11 +87,15 @@ func main() {
 			log.Fatalf("Database connection error: %v", err)
 		}
 
+		go runAccountPurger()
 		startServer()
 	default:
 		fmt.Println("Usage:")
-		fmt.Println("  migrate [up|down]  Run database migrations")
-		fmt.Println("  serve              Start the server (default)")
+		fmt.Println("  migrate [up|down]           Run database migrations")
+		fmt.Println("  make-admin <email>          Grant the admin role to a registered user")
+		fmt.Println("  generate-key [ed25519|rsa]  Add a JWT signing key to JWT_KEYS_DIR")
+		fmt.Println("  purge-accounts              Delete accounts whose deletion grace period has ended")
+		fmt.Println("  serve                       Start the server (default)")
 		os.Exit(1)
 	}
 }
@@ -94,6 +118,58 @@ func runMigrations(direction string) {
 	}
 }
 
+// makeAdmin grants the admin role to the user with the given email
+func makeAdmin(email string) {
+	if err := database.Connect(); err != nil {
+		log.Fatalf("Database connection error: %v", err)
+	}
+
+	if err := services.SetUserRoleByEmail(email, models.RoleAdmin); err != nil {
+		log.Fatalf("Failed to grant admin role: %v", err)
+	}
+	log.Printf("%s is now an admin", email)
+}
+
+// generateKey adds a new JWT signing key to the key directory
+func generateKey(keyType string) {
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		log.Fatal("JWT_KEYS_DIR is not set")
+	}
+
+	kid, err := utils.GenerateSigningKey(dir, keyType)
+	if err != nil {
+		log.Fatalf("Failed to generate key: %v", err)
+	}
+	log.Printf("Generated signing key %s; it will be used for signing after JWT_KEY_PUBLISH_DELAY", kid)
+}
+
+// purgeAccounts deletes the accounts whose deletion grace period has ended
+func purgeAccounts() {
+	if err := database.Connect(); err != nil {
+		log.Fatalf("Database connection error: %v", err)
+	}
+
+	purged, err := services.PurgeDeletedAccounts(time.Now())
+	if err != nil {
+		log.Fatalf("Failed to purge accounts: %v", err)
+	}
+	log.Printf("Purged %d deleted accounts", purged)
+}
+
+// runAccountPurger periodically deletes accounts whose grace period has ended (ACCOUNT_PURGE_INTERVAL)
+func runAccountPurger() {
+	interval := utils.DurationFromEnv("ACCOUNT_PURGE_INTERVAL", time.Hour)
+	for {
+		if purged, err := services.PurgeDeletedAccounts(time.Now()); err != nil {
+			log.Printf("Failed to purge deleted accounts: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d deleted accounts", purged)
+		}
+		time.Sleep(interval)
+	}
+}
+
 // startServer bootstraps and runs the HTTP server with graceful shutdown
 func startServer() {
 	router := routes.SetupRoutes()
@@ -108,7 +184,9 @@ func startServer() {
 	}
 
 	// Graceful shutdown
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		sig := make(chan os.Signal, 1)
 		signal.Notify(sig, os.Interrupt)
 		<-sig
@@ -120,10 +198,12 @@ func startServer() {
 		if err := srv.Shutdown(ctx); err != nil {
 			log.Printf("Shutdown error: %v", err)
 		}
+		services.FlushSessionActivity()
 	}()
 
 	log.Printf("Server listening on port %s", port)
 	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Fatalf("Server error: %v", err)
 	}
+	<-done
 }
\ No newline at end of file
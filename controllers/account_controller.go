@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/middleware"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+)
+
+// DeleteMe godoc
+// @Summary Delete current user
+// @Description Schedules the authenticated user's account for deletion after a grace period (ACCOUNT_DELETION_GRACE_PERIOD) and logs them out everywhere.
+// @Description Until then the user can log in and restore the account. Tasks shared with other users are reassigned, not deleted.
+// @Router /me [delete]
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param   input body dto.DeleteAccountRequest true "Current password"
+// @Success 202 {object} dto.AccountDeletionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "The last admin cannot delete their account"
+// @Security BearerAuth
+func DeleteMe(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var req dto.DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if strings.TrimSpace(req.Password) == "" {
+		utils.Error(w, http.StatusBadRequest, "password is required")
+		return
+	}
+
+	deletion, err := services.ScheduleAccountDeletion(userID, req.Password)
+	if err != nil {
+		writeAccountError(w, err, "Failed to delete account")
+		return
+	}
+
+	utils.JSON(w, http.StatusAccepted, deletion)
+}
+
+// RestoreMe godoc
+// @Summary Restore current user
+// @Description Cancels the scheduled deletion of the authenticated user's account.
+// @Router /me/restore [post]
+// @Tags users
+// @Produce  json
+// @Success 200 {object} dto.UserResponse
+// @Failure 400 {object} dto.ErrorResponse "Deletion is not scheduled"
+// @Failure 401 {object} dto.ErrorResponse
+// @Security BearerAuth
+func RestoreMe(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	user, err := services.CancelAccountDeletion(userID)
+	if err != nil {
+		writeAccountError(w, err, "Failed to restore account")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, user)
+}
+
+// ExportMe godoc
+// @Summary Export personal data
+// @Description Downloads a JSON archive of the authenticated user's profile, tasks, sessions, tokens, linked identities and sent invitations.
+// @Router /me/export [get]
+// @Tags users
+// @Produce  json
+// @Success 200 {object} dto.AccountExport
+// @Failure 401 {object} dto.ErrorResponse
+// @Security BearerAuth
+func ExportMe(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	export, err := services.ExportAccount(userID)
+	if err != nil {
+		writeAccountError(w, err, "Failed to export account")
+		return
+	}
+
+	filename := fmt.Sprintf("account-export-%s.json", export.ExportedAt.Format("20060102"))
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	utils.JSON(w, http.StatusOK, export)
+}
+
+// writeAccountError maps account service errors to HTTP responses.
+func writeAccountError(w http.ResponseWriter, err error, fallback string) {
+	switch err.(type) {
+	case *errors.ValidationError:
+		utils.Error(w, http.StatusBadRequest, err.Error())
+	case *errors.AuthError:
+		utils.Error(w, http.StatusUnauthorized, err.Error())
+	case *errors.ConflictError:
+		utils.Error(w, http.StatusConflict, err.Error())
+	default:
+		if err.Error() == "user not found" {
+			utils.Error(w, http.StatusNotFound, "User not found")
+			return
+		}
+		utils.Error(w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/middleware"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"github.com/kfeuerschvenger/task-manager-api/validators"
+)
+
+// AdminListUsers godoc
+// @Summary List users (admin)
+// @Description Lists every user account, including disabled ones. The optional query matches the start
+// @Description of the first name, last name, full name or email.
+// @Router /admin/users [get]
+// @Tags admin
+// @Produce  json
+// @Param   query query string false "Name or email prefix"
+// @Param   page  query int    false "Page number (default 1)"
+// @Param   limit query int    false "Page size (default 20, max 100)"
+// @Success 200 {object} dto.AdminUserListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse "Admin role required"
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+func AdminListUsers(w http.ResponseWriter, r *http.Request) {
+	page, limit, err := utils.ParsePagination(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	users, err := services.ListUsersForAdmin(r.URL.Query().Get("query"), page, limit)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "Failed to retrieve users")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, users)
+}
+
+// AdminUpdateUserRole godoc
+// @Summary Change a user's role (admin)
+// @Description Sets the role of a user. Their access tokens are revoked so the new role applies on the next refresh.
+// @Router /admin/users/{id}/role [put]
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param   id    path string                true "User ID"
+// @Param   input body dto.UpdateRoleRequest true "New role"
+// @Success 200 {object} dto.AdminUserResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse "Admin role required"
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+func AdminUpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	actorID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var req dto.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := validators.ValidateUpdateRoleInput(req); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := services.SetUserRole(actorID, mux.Vars(r)["id"], req.Role)
+	if err != nil {
+		writeAdminError(w, err, "Failed to update user")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, user)
+}
+
+// AdminDisableUser godoc
+// @Summary Disable a user (admin)
+// @Description Disables a user account, revoking all of its sessions. Disabled users cannot log in or use personal access tokens.
+// @Router /admin/users/{id}/disable [post]
+// @Tags admin
+// @Produce  json
+// @Param   id path string true "User ID"
+// @Success 200 {object} dto.AdminUserResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse "Admin role required"
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+func AdminDisableUser(w http.ResponseWriter, r *http.Request) {
+	setUserDisabled(w, r, true)
+}
+
+// AdminEnableUser godoc
+// @Summary Enable a user (admin)
+// @Description Re-enables a disabled user account.
+// @Router /admin/users/{id}/enable [post]
+// @Tags admin
+// @Produce  json
+// @Param   id path string true "User ID"
+// @Success 200 {object} dto.AdminUserResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse "Admin role required"
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+func AdminEnableUser(w http.ResponseWriter, r *http.Request) {
+	setUserDisabled(w, r, false)
+}
+
+// setUserDisabled handles both the disable and enable endpoints.
+func setUserDisabled(w http.ResponseWriter, r *http.Request, disabled bool) {
+	actorID := r.Context().Value(middleware.UserIDKey).(string)
+
+	user, err := services.SetUserDisabled(actorID, mux.Vars(r)["id"], disabled)
+	if err != nil {
+		writeAdminError(w, err, "Failed to update user")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, user)
+}
+
+// AdminForcePasswordReset godoc
+// @Summary Force a password reset (admin)
+// @Description Invalidates the user's password and sessions and emails them a password reset link.
+// @Router /admin/users/{id}/password-reset [post]
+// @Tags admin
+// @Produce  json
+// @Param   id path string true "User ID"
+// @Success 202 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse "Admins cannot reset their own password"
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse "Admin role required"
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+func AdminForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	actorID := r.Context().Value(middleware.UserIDKey).(string)
+
+	if err := services.ForcePasswordReset(actorID, mux.Vars(r)["id"]); err != nil {
+		writeAdminError(w, err, "Failed to reset password")
+		return
+	}
+
+	utils.JSON(w, http.StatusAccepted, dto.MessageResponse{Message: "Password reset email sent"})
+}
+
+// AdminListUserTasks godoc
+// @Summary List a user's tasks (admin)
+// @Description Lists the tasks created by or assigned to a user, e.g. to reassign the work of someone who left.
+// @Description Accepts the same filters, sort order and pagination parameters as GET /tasks.
+// @Router /admin/users/{id}/tasks [get]
+// @Tags admin
+// @Produce  json
+// @Param   id            path  string true  "User ID"
+// @Param   status        query string false "Filter by task status (pending, in_progress, complete), comma-separated"
+// @Param   priority      query string false "Filter by task priority (low, medium, high), comma-separated"
+// @Param   role          query string false "Only tasks the user created (creator) or is assigned to (assignee)"
+// @Param   assignee_id   query string false "Only tasks assigned to this user"
+// @Param   due_after     query string false "Only tasks due at or after this RFC 3339 date"
+// @Param   due_before    query string false "Only tasks due before this RFC 3339 date"
+// @Param   created_after query string false "Only tasks created at or after this RFC 3339 date"
+// @Param   overdue       query bool   false "Only tasks past their due date and not complete"
+// @Param   q             query string false "Only tasks matching these full-text search terms"
+// @Param   label         query string false "Only tasks carrying these labels, by comma-separated IDs"
+// @Param   label_match   query string false "Whether tasks must carry any (default) or all of the labels"
+// @Param   sort          query string false "Comma-separated sort fields; prefix with - for descending. Default: due_date"
+// @Param   cursor        query string false "Opaque cursor from the previous page"
+// @Param   limit         query int    false "Page size (1-100, default 20)"
+// @Success 200 {object} dto.TaskListResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid filter, sort, cursor or limit"
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse "Admin role required"
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+func AdminListUserTasks(w http.ResponseWriter, r *http.Request) {
+	query, err := taskListQuery(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tasks, next, err := services.ListUserTasksForAdmin(mux.Vars(r)["id"], query)
+	if err != nil {
+		writeAdminError(w, err, "Failed to retrieve tasks")
+		return
+	}
+
+	writeTaskList(w, r, tasks, next, query.Limit)
+}
+
+// writeAdminError maps admin service errors to HTTP responses.
+func writeAdminError(w http.ResponseWriter, err error, fallback string) {
+	switch err.(type) {
+	case *errors.ValidationError:
+		utils.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		if err.Error() == "user not found" {
+			utils.Error(w, http.StatusNotFound, "User not found")
+			return
+		}
+		utils.Error(w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	goerrors "errors"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/middleware"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+)
+
+// multipartOverhead is the room left in upload requests for the multipart boundaries and headers.
+const multipartOverhead = 64 << 10
+
+// ListAttachments godoc
+// @Summary List a task's attachments
+// @Description Lists the files attached to a task, oldest first.
+// @Router /tasks/{id}/attachments [get]
+// @Tags attachments
+// @Produce  json
+// @Param   id path string true "Task ID"
+// @Success 200 {array} dto.AttachmentResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Task not found"
+// @Security BearerAuth
+func ListAttachments(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+
+	attachments, err := services.ListAttachments(mux.Vars(r)["id"], userID, role)
+	if err != nil {
+		writeAttachmentError(w, err, "Failed to retrieve attachments")
+		return
+	}
+
+	resp := make([]dto.AttachmentResponse, 0, len(attachments))
+	for _, a := range attachments {
+		resp = append(resp, toAttachmentResponse(a))
+	}
+	utils.JSON(w, http.StatusOK, resp)
+}
+
+// UploadAttachment godoc
+// @Summary Attach a file to a task
+// @Description Uploads a file as the "file" field of a multipart form. Files may be at most ATTACHMENT_MAX_SIZE bytes,
+// @Description and their type, detected from their contents, must be one of ATTACHMENT_ALLOWED_TYPES.
+// @Router /tasks/{id}/attachments [post]
+// @Tags attachments
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param   id path string true "Task ID"
+// @Param   file formData file true "File to attach"
+// @Success 201 {object} dto.AttachmentResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Task not found"
+// @Failure 413 {object} dto.ErrorResponse "File too large"
+// @Failure 415 {object} dto.ErrorResponse "File type not allowed"
+// @Security BearerAuth
+func UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+
+	maxSize := services.AttachmentMaxSize()
+	if r.ContentLength > maxSize+multipartOverhead {
+		utils.Error(w, http.StatusRequestEntityTooLarge, "File is too large")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+multipartOverhead)
+
+	// Files are spooled to disk past the first 1 MiB
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if goerrors.As(err, &tooLarge) {
+			utils.Error(w, http.StatusRequestEntityTooLarge, "File is too large")
+			return
+		}
+		utils.Error(w, http.StatusBadRequest, "Invalid multipart form")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, "file is required")
+		return
+	}
+	defer file.Close()
+	if header.Size > maxSize {
+		utils.Error(w, http.StatusRequestEntityTooLarge, "File is too large")
+		return
+	}
+
+	attachment, err := services.UploadAttachment(r.Context(), mux.Vars(r)["id"], userID, role, header.Filename, file, header.Size)
+	if err != nil {
+		writeAttachmentError(w, err, "Failed to upload attachment")
+		return
+	}
+
+	utils.JSON(w, http.StatusCreated, toAttachmentResponse(attachment))
+}
+
+// DownloadAttachment godoc
+// @Summary Download an attachment
+// @Description Streams the contents of an attachment. Range requests are supported, e.g. to resume a download.
+// @Router /tasks/{id}/attachments/{attachmentId} [get]
+// @Tags attachments
+// @Produce  octet-stream
+// @Param   id path string true "Task ID"
+// @Param   attachmentId path string true "Attachment ID"
+// @Param   Range header string false "Byte range, e.g. bytes=0-1023"
+// @Success 200 {file} file
+// @Success 206 {file} file "Partial content"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Task or attachment not found"
+// @Failure 416 {string} string "Range not satisfiable"
+// @Security BearerAuth
+func DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+	vars := mux.Vars(r)
+
+	attachment, content, err := services.OpenAttachment(r.Context(), vars["id"], vars["attachmentId"], userID, role)
+	if err != nil {
+		writeAttachmentError(w, err, "Failed to download attachment")
+		return
+	}
+	defer content.Close()
+
+	// Always download rather than render uploads, so that they cannot run scripts on our origin
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("ETag", `"`+attachment.Checksum+`"`)
+	w.Header().Set("Cache-Control", "private")
+	http.ServeContent(w, r, "", attachment.CreatedAt, content)
+}
+
+// DeleteAttachment godoc
+// @Summary Delete an attachment
+// @Description Deletes an attachment and its contents. Only the uploader, the task's creator or an admin may delete it.
+// @Router /tasks/{id}/attachments/{attachmentId} [delete]
+// @Tags attachments
+// @Param   id path string true "Task ID"
+// @Param   attachmentId path string true "Attachment ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Task or attachment not found"
+// @Security BearerAuth
+func DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+	vars := mux.Vars(r)
+
+	if err := services.DeleteAttachment(vars["id"], vars["attachmentId"], userID, role); err != nil {
+		writeAttachmentError(w, err, "Failed to delete attachment")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeAttachmentError maps attachment service errors to HTTP responses.
+func writeAttachmentError(w http.ResponseWriter, err error, fallback string) {
+	switch err.(type) {
+	case *errors.ValidationError:
+		if strings.HasPrefix(err.Error(), "unsupported file type") {
+			utils.Error(w, http.StatusUnsupportedMediaType, err.Error())
+			return
+		}
+		utils.Error(w, http.StatusBadRequest, err.Error())
+	case *errors.ForbiddenError:
+		utils.Error(w, http.StatusForbidden, err.Error())
+	default:
+		switch err.Error() {
+		case "task not found":
+			utils.Error(w, http.StatusNotFound, "Task not found")
+		case "attachment not found":
+			utils.Error(w, http.StatusNotFound, "Attachment not found")
+		case "invalid attachment ID":
+			utils.Error(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.Error(w, http.StatusInternalServerError, fallback)
+		}
+	}
+}
+
+// toAttachmentResponse maps an attachment model to its response DTO.
+func toAttachmentResponse(a models.Attachment) dto.AttachmentResponse {
+	var uploaderID *string
+	if a.UploaderID != nil {
+		id := a.UploaderID.String()
+		uploaderID = &id
+	}
+
+	return dto.AttachmentResponse{
+		ID:          a.ID.String(),
+		TaskID:      a.TaskID.String(),
+		UploaderID:  uploaderID,
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		SHA256:      a.Checksum,
+		DownloadURL: utils.AppURL() + "/tasks/" + a.TaskID.String() + "/attachments/" + a.ID.String(),
+		CreatedAt:   a.CreatedAt,
+	}
+}
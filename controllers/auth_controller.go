@@ -2,10 +2,13 @@ package controllers
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"strings"
 
 	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/middleware"
 	"github.com/kfeuerschvenger/task-manager-api/services"
 	"github.com/kfeuerschvenger/task-manager-api/utils"
 	"github.com/kfeuerschvenger/task-manager-api/validators"
@@ -14,13 +17,17 @@ import (
 // Register godoc
 // @Summary New User Registration
 // @Description Creates a new user account with the provided registration details.
+// @Description An invite token from an invitation email assigns the invited role and skips email verification;
+// @Description it is required when registration is invite-only.
 // @Router /auth/register [post]
 // @Tags auth
 // @Accept  json
 // @Produce  json
 // @Param   input body dto.RegisterRequest true "Registration details"
-// @Success 201 {object} map[string]string
+// @Success 201 {object} dto.AuthResponse
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} dto.ErrorResponse "Registration requires an invitation"
+// @Failure 409 {object} dto.ErrorResponse "Email already registered"
 func Register(w http.ResponseWriter, r *http.Request) {
 	var req dto.RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -33,30 +40,35 @@ func Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := services.RegisterUser(req)
+	tokens, err := services.RegisterUser(req, utils.Client(r))
 	if err != nil {
     if strings.Contains(err.Error(), "email already registered") {
         utils.Error(w, http.StatusConflict, err.Error())
+    } else if _, ok := err.(*errors.ForbiddenError); ok {
+        utils.Error(w, http.StatusForbidden, err.Error())
     } else {
         utils.Error(w, http.StatusBadRequest, err.Error())
     }
     return
 	}
 
-	utils.JSON(w, http.StatusCreated, map[string]string{"token": token})
+	utils.JSON(w, http.StatusCreated, tokens)
 }
 
 // Login godoc
 // @Summary User Login
-// @Description Authenticates a user and returns a JWT token.
+// @Description Authenticates a user and returns a short-lived JWT access token and a refresh token.
+// @Description When two-factor authentication is enabled, a challenge token is returned instead (see /auth/2fa/verify).
 // @Router /auth/login [post]
 // @Tags auth
 // @Accept  json
 // @Produce  json
 // @Param   input body dto.LoginRequest true "Login details"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} dto.AuthResponse
+// @Success 200 {object} dto.MFAChallengeResponse "Two-factor authentication required"
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 429 {object} dto.ErrorResponse "Too many failed attempts; see the Retry-After header"
 func Login(w http.ResponseWriter, r *http.Request) {
 	var req dto.LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -69,11 +81,245 @@ func Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := services.AuthenticateUser(req)
+	tokens, challenge, err := services.AuthenticateUser(req, utils.Client(r))
 	if err != nil {
+		if tooMany, ok := err.(*errors.TooManyRequestsError); ok {
+			utils.TooManyRequests(w, tooMany.RetryAfter, err.Error())
+			return
+		}
 		utils.Error(w, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	utils.JSON(w, http.StatusOK, map[string]string{"token": token})
-}
\ No newline at end of file
+	if challenge != nil {
+		utils.JSON(w, http.StatusOK, challenge)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, tokens)
+}
+
+// Refresh godoc
+// @Summary Refresh Tokens
+// @Description Exchanges a refresh token for a new access token and a new refresh token.
+// @Description The presented refresh token is rotated and cannot be used again; replaying it revokes the whole token family.
+// @Router /auth/refresh [post]
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   input body dto.RefreshRequest true "Refresh token"
+// @Success 200 {object} dto.AuthResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+func Refresh(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if strings.TrimSpace(req.RefreshToken) == "" {
+		utils.Error(w, http.StatusBadRequest, "refresh token is required")
+		return
+	}
+
+	tokens, err := services.RefreshTokens(req.RefreshToken, utils.Client(r))
+	if err != nil {
+		switch err.(type) {
+		case *errors.AuthError:
+			utils.Error(w, http.StatusUnauthorized, err.Error())
+		default:
+			utils.Error(w, http.StatusInternalServerError, "Failed to refresh token")
+		}
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, tokens)
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description Revokes the access token used for the request and, if provided, the refresh token of the same session.
+// @Router /auth/logout [post]
+// @Tags auth
+// @Accept  json
+// @Param   input body dto.LogoutRequest false "Refresh token to revoke"
+// @Success 204 {object} nil
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+func Logout(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.ClaimsKey).(*utils.Claims)
+
+	var req dto.LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if req.RefreshToken != "" {
+		if err := services.RevokeRefreshToken(claims.UserID, req.RefreshToken); err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Failed to revoke refresh token")
+			return
+		}
+	}
+
+	if err := services.RevokeAccessToken(claims); err != nil {
+		utils.Error(w, http.StatusInternalServerError, "Failed to revoke token")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutAll godoc
+// @Summary Logout From All Devices
+// @Description Revokes every refresh token of the authenticated user and every access token issued so far.
+// @Router /auth/logout-all [post]
+// @Tags auth
+// @Success 204 {object} nil
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+func LogoutAll(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.ClaimsKey).(*utils.Claims)
+
+	if err := services.RevokeAllUserTokens(claims.UserID); err != nil {
+		utils.Error(w, http.StatusInternalServerError, "Failed to revoke tokens")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ForgotPassword godoc
+// @Summary Request Password Reset
+// @Description Sends a single-use password reset link to the given email address.
+// @Description The response is the same whether or not the email is registered.
+// @Router /auth/password/forgot [post]
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   input body dto.ForgotPasswordRequest true "Account email"
+// @Success 202 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse "Too many reset requests for the email or from the client"
+func ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := validators.ValidateForgotPasswordInput(req); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := services.RequestPasswordReset(req.Email, utils.ClientIP(r)); err != nil {
+		if tooMany, ok := err.(*errors.TooManyRequestsError); ok {
+			utils.TooManyRequests(w, tooMany.RetryAfter, err.Error())
+			return
+		}
+		utils.Error(w, http.StatusInternalServerError, "Failed to process request")
+		return
+	}
+
+	utils.JSON(w, http.StatusAccepted, dto.MessageResponse{
+		Message: "If the email is registered, a reset link has been sent",
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset Password
+// @Description Sets a new password using a token from the reset email and revokes all existing sessions.
+// @Router /auth/password/reset [post]
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   input body dto.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+func ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := validators.ValidateResetPasswordInput(req); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := services.ResetPassword(req.Token, req.Password); err != nil {
+		switch err.(type) {
+		case *errors.ValidationError:
+			utils.Error(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.Error(w, http.StatusInternalServerError, "Failed to reset password")
+		}
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, dto.MessageResponse{Message: "Password has been reset"})
+}
+
+// VerifyEmail godoc
+// @Summary Verify Email Address
+// @Description Confirms the user's email address using the token from the verification email.
+// @Router /auth/verify [get]
+// @Tags auth
+// @Produce  json
+// @Param   token query string true "Verification token"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+func VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utils.Error(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	if err := services.VerifyEmail(token); err != nil {
+		switch err.(type) {
+		case *errors.ValidationError:
+			utils.Error(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.Error(w, http.StatusInternalServerError, "Failed to verify email")
+		}
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, dto.MessageResponse{Message: "Email address verified"})
+}
+
+// ResendVerification godoc
+// @Summary Resend Verification Email
+// @Description Sends a new verification link to the authenticated user's email address.
+// @Router /auth/verify/resend [post]
+// @Tags auth
+// @Produce  json
+// @Success 202 {object} dto.MessageResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "Email already verified"
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+func ResendVerification(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	if err := services.ResendVerificationEmail(userID); err != nil {
+		switch err.(type) {
+		case *errors.ConflictError:
+			utils.Error(w, http.StatusConflict, err.Error())
+		default:
+			utils.Error(w, http.StatusInternalServerError, "Failed to send verification email")
+		}
+		return
+	}
+
+	utils.JSON(w, http.StatusAccepted, dto.MessageResponse{Message: "Verification email sent"})
+}
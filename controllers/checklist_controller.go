@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/middleware"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"github.com/kfeuerschvenger/task-manager-api/validators"
+)
+
+// ListChecklist godoc
+// @Summary List a task's checklist
+// @Description Lists the checklist items of a task, in order.
+// @Router /tasks/{id}/checklist [get]
+// @Tags tasks
+// @Produce  json
+// @Param   id path string true "Task ID"
+// @Success 200 {array} dto.ChecklistItemResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Task not found"
+// @Security BearerAuth
+func ListChecklist(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+
+	items, err := services.ListChecklist(mux.Vars(r)["id"], userID, role)
+	if err != nil {
+		writeChecklistError(w, err, "Failed to retrieve checklist")
+		return
+	}
+
+	resp := make([]dto.ChecklistItemResponse, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toChecklistItemResponse(item))
+	}
+	utils.JSON(w, http.StatusOK, resp)
+}
+
+// AddChecklistItem godoc
+// @Summary Add a checklist item
+// @Description Adds an item to a task's checklist, at the end or at the given position. Anyone who can see the task can edit its checklist.
+// @Router /tasks/{id}/checklist [post]
+// @Tags tasks
+// @Accept  json
+// @Produce  json
+// @Param   id path string true "Task ID"
+// @Param   input body dto.CreateChecklistItemRequest true "Item text and position"
+// @Success 201 {object} dto.ChecklistItemResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Task not found"
+// @Security BearerAuth
+func AddChecklistItem(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+
+	var req dto.CreateChecklistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := validators.ValidateCreateChecklistItemInput(req); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	item, err := services.AddChecklistItem(mux.Vars(r)["id"], userID, role, req)
+	if err != nil {
+		writeChecklistError(w, err, "Failed to add checklist item")
+		return
+	}
+
+	utils.JSON(w, http.StatusCreated, toChecklistItemResponse(item))
+}
+
+// UpdateChecklistItem godoc
+// @Summary Update a checklist item
+// @Description Edits the text of a checklist item, checks or unchecks it, or moves it to another position.
+// @Router /tasks/{id}/checklist/{itemId} [put]
+// @Tags tasks
+// @Accept  json
+// @Produce  json
+// @Param   id path string true "Task ID"
+// @Param   itemId path string true "Checklist item ID"
+// @Param   input body dto.UpdateChecklistItemRequest true "Fields to change"
+// @Success 200 {object} dto.ChecklistItemResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Task or checklist item not found"
+// @Security BearerAuth
+func UpdateChecklistItem(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+	vars := mux.Vars(r)
+
+	var req dto.UpdateChecklistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := validators.ValidateUpdateChecklistItemInput(req); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	item, err := services.UpdateChecklistItem(vars["id"], vars["itemId"], userID, role, req)
+	if err != nil {
+		writeChecklistError(w, err, "Failed to update checklist item")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, toChecklistItemResponse(item))
+}
+
+// DeleteChecklistItem godoc
+// @Summary Delete a checklist item
+// @Description Removes an item from a task's checklist.
+// @Router /tasks/{id}/checklist/{itemId} [delete]
+// @Tags tasks
+// @Param   id path string true "Task ID"
+// @Param   itemId path string true "Checklist item ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Task or checklist item not found"
+// @Security BearerAuth
+func DeleteChecklistItem(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+	vars := mux.Vars(r)
+
+	if err := services.DeleteChecklistItem(vars["id"], vars["itemId"], userID, role); err != nil {
+		writeChecklistError(w, err, "Failed to delete checklist item")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeChecklistError maps checklist service errors to HTTP responses.
+func writeChecklistError(w http.ResponseWriter, err error, fallback string) {
+	switch err.(type) {
+	case *errors.ValidationError:
+		utils.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		switch err.Error() {
+		case "task not found":
+			utils.Error(w, http.StatusNotFound, "Task not found")
+		case "checklist item not found":
+			utils.Error(w, http.StatusNotFound, "Checklist item not found")
+		case "invalid checklist item ID":
+			utils.Error(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.Error(w, http.StatusInternalServerError, fallback)
+		}
+	}
+}
+
+// toChecklistItemResponse maps a checklist item model to its response DTO.
+func toChecklistItemResponse(item models.ChecklistItem) dto.ChecklistItemResponse {
+	return dto.ChecklistItemResponse{
+		ID:        item.ID.String(),
+		Text:      item.Text,
+		Done:      item.Done,
+		Position:  item.Position,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/middleware"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"github.com/kfeuerschvenger/task-manager-api/validators"
+)
+
+// ListComments godoc
+// @Summary List a task's comments
+// @Description Lists the comments of a task visible to the user, oldest first. Pages are keyset-paginated:
+// @Description follow next_cursor, or the Link header, to fetch the next page.
+// @Router /tasks/{id}/comments [get]
+// @Tags comments
+// @Produce  json
+// @Param   id path string true "Task ID"
+// @Param   cursor query string false "Cursor of the next page, from a previous response"
+// @Param   limit query int false "Page size (1-100, default 20)"
+// @Success 200 {object} dto.CommentListResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid cursor or limit"
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Task not found"
+// @Security BearerAuth
+func ListComments(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+
+	cursor, limit, err := utils.ParseCursorPagination(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	comments, next, err := services.ListComments(mux.Vars(r)["id"], userID, role, cursor, limit)
+	if err != nil {
+		writeCommentError(w, err, "Failed to retrieve comments")
+		return
+	}
+
+	resp := dto.CommentListResponse{
+		Comments: make([]dto.CommentResponse, 0, len(comments)),
+		Limit:    limit,
+	}
+	for _, c := range comments {
+		resp.Comments = append(resp.Comments, toCommentResponse(c))
+	}
+	if next != "" {
+		resp.NextCursor = &next
+	}
+
+	utils.SetPaginationLinks(w, r, next)
+	utils.JSON(w, http.StatusOK, resp)
+}
+
+// CreateComment godoc
+// @Summary Comment on a task
+// @Description Adds a comment to a task visible to the user. The body is Markdown and is stored as written.
+// @Router /tasks/{id}/comments [post]
+// @Tags comments
+// @Accept  json
+// @Produce  json
+// @Param   id path string true "Task ID"
+// @Param   input body dto.CommentRequest true "Comment body"
+// @Success 201 {object} dto.CommentResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Task not found"
+// @Security BearerAuth
+func CreateComment(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+
+	var req dto.CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := validators.ValidateCommentInput(req); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	comment, err := services.CreateComment(mux.Vars(r)["id"], userID, role, req)
+	if err != nil {
+		writeCommentError(w, err, "Failed to create comment")
+		return
+	}
+
+	utils.JSON(w, http.StatusCreated, toCommentResponse(comment))
+}
+
+// UpdateComment godoc
+// @Summary Edit a comment
+// @Description Replaces the body of a comment and records when it was edited. Only the author may edit a comment.
+// @Router /comments/{id} [patch]
+// @Tags comments
+// @Accept  json
+// @Produce  json
+// @Param   id path string true "Comment ID"
+// @Param   input body dto.CommentRequest true "New comment body"
+// @Success 200 {object} dto.CommentResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse "Not the author of the comment"
+// @Failure 404 {object} dto.ErrorResponse "Comment not found"
+// @Security BearerAuth
+func UpdateComment(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+
+	var req dto.CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := validators.ValidateCommentInput(req); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	comment, err := services.UpdateComment(mux.Vars(r)["id"], userID, role, req)
+	if err != nil {
+		writeCommentError(w, err, "Failed to update comment")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, toCommentResponse(comment))
+}
+
+// DeleteComment godoc
+// @Summary Delete a comment
+// @Description Deletes a comment. Only the author may delete a comment.
+// @Router /comments/{id} [delete]
+// @Tags comments
+// @Param   id path string true "Comment ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse "Not the author of the comment"
+// @Failure 404 {object} dto.ErrorResponse "Comment not found"
+// @Security BearerAuth
+func DeleteComment(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+
+	if err := services.DeleteComment(mux.Vars(r)["id"], userID, role); err != nil {
+		writeCommentError(w, err, "Failed to delete comment")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeCommentError maps comment service errors to HTTP responses.
+func writeCommentError(w http.ResponseWriter, err error, fallback string) {
+	switch err.(type) {
+	case *errors.ValidationError:
+		utils.Error(w, http.StatusBadRequest, err.Error())
+	case *errors.ForbiddenError:
+		utils.Error(w, http.StatusForbidden, err.Error())
+	default:
+		switch err.Error() {
+		case "task not found":
+			utils.Error(w, http.StatusNotFound, "Task not found")
+		case "comment not found":
+			utils.Error(w, http.StatusNotFound, "Comment not found")
+		case "invalid comment ID":
+			utils.Error(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.Error(w, http.StatusInternalServerError, fallback)
+		}
+	}
+}
+
+// toCommentResponse maps a comment model to its response DTO.
+func toCommentResponse(c models.Comment) dto.CommentResponse {
+	author := dto.CommentAuthor{FirstName: "Deleted user"}
+	if c.Author != nil {
+		id := c.Author.ID.String()
+		author = dto.CommentAuthor{ID: &id, FirstName: c.Author.FirstName, LastName: c.Author.LastName}
+	}
+
+	return dto.CommentResponse{
+		ID:        c.ID.String(),
+		TaskID:    c.TaskID.String(),
+		Author:    author,
+		Body:      c.Body,
+		CreatedAt: c.CreatedAt,
+		EditedAt:  c.EditedAt,
+	}
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/middleware"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+)
+
+// AddTaskDependency godoc
+// @Summary Mark a task as blocked by another task
+// @Description Records that a task cannot be started or completed until another task is complete. Both tasks must be visible to the user.
+// @Description Dependencies that would create a cycle are rejected; adding an existing dependency has no effect.
+// @Router /tasks/{id}/dependencies [post]
+// @Tags tasks
+// @Accept  json
+// @Produce  json
+// @Param   id path string true "ID of the blocked task"
+// @Param   input body dto.AddDependencyRequest true "ID of the blocking task"
+// @Success 201 {object} dto.TaskResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid input, unknown blocking task or cycle"
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Task not found"
+// @Security BearerAuth
+func AddTaskDependency(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+
+	var req dto.AddDependencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+	if req.BlockedByID == "" {
+		utils.Error(w, http.StatusBadRequest, "blocked_by_id is required")
+		return
+	}
+
+	task, err := services.AddDependency(mux.Vars(r)["id"], req.BlockedByID, userID, role)
+	if err != nil {
+		writeDependencyError(w, err, "Failed to add dependency")
+		return
+	}
+
+	utils.JSON(w, http.StatusCreated, toTaskResponse(*task))
+}
+
+// RemoveTaskDependency godoc
+// @Summary Remove a task dependency
+// @Description Records that a task no longer waits for another task.
+// @Router /tasks/{id}/dependencies/{blockedById} [delete]
+// @Tags tasks
+// @Param   id path string true "ID of the blocked task"
+// @Param   blockedById path string true "ID of the blocking task"
+// @Success 204 {object} nil
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Task or dependency not found"
+// @Security BearerAuth
+func RemoveTaskDependency(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+	vars := mux.Vars(r)
+
+	if err := services.RemoveDependency(vars["id"], vars["blockedById"], userID, role); err != nil {
+		writeDependencyError(w, err, "Failed to remove dependency")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeDependencyError maps dependency service errors to HTTP responses.
+func writeDependencyError(w http.ResponseWriter, err error, fallback string) {
+	switch err.(type) {
+	case *errors.ValidationError:
+		utils.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		switch err.Error() {
+		case "task not found":
+			utils.Error(w, http.StatusNotFound, "Task not found")
+		case "dependency not found":
+			utils.Error(w, http.StatusNotFound, "Dependency not found")
+		case "invalid blocking task ID":
+			utils.Error(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.Error(w, http.StatusInternalServerError, fallback)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/middleware"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"github.com/kfeuerschvenger/task-manager-api/validators"
+)
+
+// CreateInvitation godoc
+// @Summary Invite a teammate
+// @Description Emails a single-use invite link to the address. The invitee registers through /auth/register with the invite token.
+// @Description Inviting an address again replaces its pending invitation. Only admins can invite admins.
+// @Router /invitations [post]
+// @Tags invitations
+// @Accept  json
+// @Produce  json
+// @Param   input body dto.CreateInvitationRequest true "Invitee email and role"
+// @Success 201 {object} dto.InvitationResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "Email already registered"
+// @Security BearerAuth
+func CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+
+	var req dto.CreateInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := validators.ValidateCreateInvitationInput(req); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	invitation, err := services.CreateInvitation(userID, role, req)
+	if err != nil {
+		writeInvitationError(w, err, "Failed to create invitation")
+		return
+	}
+
+	utils.JSON(w, http.StatusCreated, invitation)
+}
+
+// ListInvitations godoc
+// @Summary List pending invitations
+// @Description Lists invitations that have not been accepted, revoked or expired. Admins see every invitation, members the ones they sent.
+// @Router /invitations [get]
+// @Tags invitations
+// @Produce  json
+// @Success 200 {array} dto.InvitationResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Security BearerAuth
+func ListInvitations(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+
+	invitations, err := services.ListInvitations(userID, role)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "Failed to retrieve invitations")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, invitations)
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke an invitation
+// @Description Cancels a pending invitation so its link can no longer be used. Only its sender or an admin can revoke it.
+// @Router /invitations/{id} [delete]
+// @Tags invitations
+// @Param   id path string true "Invitation ID"
+// @Success 204 {object} nil
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Invitation not found"
+// @Security BearerAuth
+func RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+
+	if err := services.RevokeInvitation(userID, role, mux.Vars(r)["id"]); err != nil {
+		writeInvitationError(w, err, "Failed to revoke invitation")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetInvitation godoc
+// @Summary View an invitation
+// @Description Returns the email and role of the pending invitation for an invite token, e.g. to pre-fill a registration form.
+// @Router /auth/invitation [get]
+// @Tags invitations
+// @Produce  json
+// @Param   token query string true "Invite token from the invitation email"
+// @Success 200 {object} dto.InvitationResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid or expired invitation"
+func GetInvitation(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(r.URL.Query().Get("token"))
+	if token == "" {
+		utils.Error(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	invitation, err := services.GetInvitation(token)
+	if err != nil {
+		writeInvitationError(w, err, "Failed to retrieve invitation")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, invitation)
+}
+
+// writeInvitationError maps invitation service errors to HTTP responses.
+func writeInvitationError(w http.ResponseWriter, err error, fallback string) {
+	switch err.(type) {
+	case *errors.ValidationError:
+		utils.Error(w, http.StatusBadRequest, err.Error())
+	case *errors.ForbiddenError:
+		utils.Error(w, http.StatusForbidden, err.Error())
+	case *errors.ConflictError:
+		utils.Error(w, http.StatusConflict, err.Error())
+	default:
+		switch err.Error() {
+		case "invitation not found":
+			utils.Error(w, http.StatusNotFound, "Invitation not found")
+		case "invalid invitation ID":
+			utils.Error(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.Error(w, http.StatusInternalServerError, fallback)
+		}
+	}
+}
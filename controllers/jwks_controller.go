@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+)
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Publishes the public keys that verify access tokens issued by the API, identified by the "kid" token header.
+// @Description The set is empty when tokens are signed with a shared HS256 secret.
+// @Router /.well-known/jwks.json [get]
+// @Tags auth
+// @Produce  json
+// @Success 200 {object} dto.JWKSResponse
+func JWKS(w http.ResponseWriter, r *http.Request) {
+	// Verifiers may cache the set briefly; new keys are published before they are used for signing.
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utils.JSON(w, http.StatusOK, dto.JWKSResponse{Keys: utils.PublicJWKs()})
+}
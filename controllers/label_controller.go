@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/middleware"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"github.com/kfeuerschvenger/task-manager-api/validators"
+)
+
+// ListLabels godoc
+// @Summary List labels
+// @Description Lists the labels the authenticated user can use: their personal labels, then the team labels, each sorted by name.
+// @Router /labels [get]
+// @Tags labels
+// @Produce  json
+// @Success 200 {array} dto.LabelResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+func ListLabels(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	labels, err := services.ListLabels(userID)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "Failed to retrieve labels")
+		return
+	}
+
+	resp := make([]dto.LabelResponse, 0, len(labels))
+	for _, l := range labels {
+		resp = append(resp, toLabelResponse(l))
+	}
+	utils.JSON(w, http.StatusOK, resp)
+}
+
+// CreateLabel godoc
+// @Summary Create a label
+// @Description Creates a personal label, only visible to the user, or a team label shared by every user.
+// @Description Names are unique, ignoring case, among the user's personal labels and among team labels.
+// @Router /labels [post]
+// @Tags labels
+// @Accept  json
+// @Produce  json
+// @Param   input body dto.CreateLabelRequest true "Label name, color and scope"
+// @Success 201 {object} dto.LabelResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "A label with this name already exists"
+// @Security BearerAuth
+func CreateLabel(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var req dto.CreateLabelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := validators.ValidateCreateLabelInput(req); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	label, err := services.CreateLabel(userID, req)
+	if err != nil {
+		writeLabelError(w, err, "Failed to create label")
+		return
+	}
+
+	utils.JSON(w, http.StatusCreated, toLabelResponse(label))
+}
+
+// UpdateLabel godoc
+// @Summary Update a label
+// @Description Renames or recolors a label. Personal labels can only be changed by their owner, team labels by their creator or an admin.
+// @Router /labels/{id} [put]
+// @Tags labels
+// @Accept  json
+// @Produce  json
+// @Param   id path string true "Label ID"
+// @Param   input body dto.UpdateLabelRequest true "New name and/or color"
+// @Success 200 {object} dto.LabelResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Label not found"
+// @Failure 409 {object} dto.ErrorResponse "A label with this name already exists"
+// @Security BearerAuth
+func UpdateLabel(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+
+	var req dto.UpdateLabelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := validators.ValidateUpdateLabelInput(req); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	label, err := services.UpdateLabel(userID, role, mux.Vars(r)["id"], req)
+	if err != nil {
+		writeLabelError(w, err, "Failed to update label")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, toLabelResponse(label))
+}
+
+// DeleteLabel godoc
+// @Summary Delete a label
+// @Description Deletes a label and removes it from every task. The same permissions as for updates apply.
+// @Router /labels/{id} [delete]
+// @Tags labels
+// @Param   id path string true "Label ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Label not found"
+// @Security BearerAuth
+func DeleteLabel(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+
+	if err := services.DeleteLabel(userID, role, mux.Vars(r)["id"]); err != nil {
+		writeLabelError(w, err, "Failed to delete label")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AttachTaskLabel godoc
+// @Summary Label a task
+// @Description Adds a label to a task. Anyone who can see the task may label it with their personal labels or team labels.
+// @Description Labeling a task twice with the same label has no effect.
+// @Router /tasks/{id}/labels/{labelId} [put]
+// @Tags labels
+// @Param   id path string true "Task ID"
+// @Param   labelId path string true "Label ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Task or label not found"
+// @Security BearerAuth
+func AttachTaskLabel(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+	vars := mux.Vars(r)
+
+	if err := services.AttachLabel(vars["id"], vars["labelId"], userID, role); err != nil {
+		writeLabelError(w, err, "Failed to label task")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DetachTaskLabel godoc
+// @Summary Remove a label from a task
+// @Description Removes a label from a task. Removing a label the task does not carry has no effect.
+// @Router /tasks/{id}/labels/{labelId} [delete]
+// @Tags labels
+// @Param   id path string true "Task ID"
+// @Param   labelId path string true "Label ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Task or label not found"
+// @Security BearerAuth
+func DetachTaskLabel(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+	vars := mux.Vars(r)
+
+	if err := services.DetachLabel(vars["id"], vars["labelId"], userID, role); err != nil {
+		writeLabelError(w, err, "Failed to remove label from task")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeLabelError maps label service errors to HTTP responses.
+func writeLabelError(w http.ResponseWriter, err error, fallback string) {
+	switch err.(type) {
+	case *errors.ValidationError:
+		utils.Error(w, http.StatusBadRequest, err.Error())
+	case *errors.ForbiddenError:
+		utils.Error(w, http.StatusForbidden, err.Error())
+	case *errors.ConflictError:
+		utils.Error(w, http.StatusConflict, err.Error())
+	default:
+		switch err.Error() {
+		case "label not found":
+			utils.Error(w, http.StatusNotFound, "Label not found")
+		case "task not found":
+			utils.Error(w, http.StatusNotFound, "Task not found")
+		case "invalid label ID":
+			utils.Error(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.Error(w, http.StatusInternalServerError, fallback)
+		}
+	}
+}
+
+// toLabelResponse maps a label model to its response DTO.
+func toLabelResponse(l models.Label) dto.LabelResponse {
+	return dto.LabelResponse{
+		ID:        l.ID.String(),
+		Name:      l.Name,
+		Color:     l.Color,
+		Scope:     l.Scope(),
+		CreatedAt: l.CreatedAt,
+	}
+}
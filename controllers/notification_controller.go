@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/middleware"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+)
+
+// ListNotifications godoc
+// @Summary List my notifications
+// @Description Lists the authenticated user's notifications, newest first, e.g. mentions in task descriptions and comments.
+// @Description Pages are keyset-paginated: follow next_cursor, or the Link header, to fetch the next page.
+// @Router /me/notifications [get]
+// @Tags notifications
+// @Produce  json
+// @Param   unread query bool false "Only list unread notifications"
+// @Param   cursor query string false "Cursor of the next page, from a previous response"
+// @Param   limit query int false "Page size (1-100, default 20)"
+// @Success 200 {object} dto.NotificationListResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid filter, cursor or limit"
+// @Failure 401 {object} dto.ErrorResponse
+// @Security BearerAuth
+func ListNotifications(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	cursor, limit, err := utils.ParseCursorPagination(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	unread := false
+	if v := r.URL.Query().Get("unread"); v != "" {
+		if unread, err = strconv.ParseBool(v); err != nil {
+			utils.Error(w, http.StatusBadRequest, "unread must be true or false")
+			return
+		}
+	}
+
+	notifications, next, err := services.ListNotifications(userID, unread, cursor, limit)
+	if err != nil {
+		writeNotificationError(w, err, "Failed to retrieve notifications")
+		return
+	}
+	unreadCount, err := services.CountUnreadNotifications(userID)
+	if err != nil {
+		writeNotificationError(w, err, "Failed to retrieve notifications")
+		return
+	}
+
+	resp := dto.NotificationListResponse{
+		Notifications: make([]dto.NotificationResponse, 0, len(notifications)),
+		UnreadCount:   unreadCount,
+		Limit:         limit,
+	}
+	for _, n := range notifications {
+		resp.Notifications = append(resp.Notifications, toNotificationResponse(n))
+	}
+	if next != "" {
+		resp.NextCursor = &next
+	}
+
+	utils.SetPaginationLinks(w, r, next)
+	utils.JSON(w, http.StatusOK, resp)
+}
+
+// MarkNotificationRead godoc
+// @Summary Mark a notification as read
+// @Router /me/notifications/{id}/read [post]
+// @Tags notifications
+// @Param   id path string true "Notification ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Notification not found"
+// @Security BearerAuth
+func MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	if err := services.MarkNotificationRead(userID, mux.Vars(r)["id"]); err != nil {
+		writeNotificationError(w, err, "Failed to update notification")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MarkAllNotificationsRead godoc
+// @Summary Mark all notifications as read
+// @Router /me/notifications/read-all [post]
+// @Tags notifications
+// @Success 204 {object} nil
+// @Failure 401 {object} dto.ErrorResponse
+// @Security BearerAuth
+func MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	if err := services.MarkAllNotificationsRead(userID); err != nil {
+		writeNotificationError(w, err, "Failed to update notifications")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeNotificationError maps notification service errors to HTTP responses.
+func writeNotificationError(w http.ResponseWriter, err error, fallback string) {
+	switch err.(type) {
+	case *errors.ValidationError:
+		utils.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		switch err.Error() {
+		case "notification not found":
+			utils.Error(w, http.StatusNotFound, "Notification not found")
+		case "invalid notification ID":
+			utils.Error(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.Error(w, http.StatusInternalServerError, fallback)
+		}
+	}
+}
+
+// toNotificationResponse maps a notification model to its response DTO.
+func toNotificationResponse(n models.Notification) dto.NotificationResponse {
+	resp := dto.NotificationResponse{
+		ID:        n.ID.String(),
+		Kind:      n.Kind,
+		Task:      dto.TaskReference{ID: n.TaskID.String(), Title: n.Task.Title, Status: n.Task.Status},
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+	if n.CommentID != nil {
+		id := n.CommentID.String()
+		resp.CommentID = &id
+	}
+	if n.Actor != nil {
+		resp.Actor = &dto.PublicUserResponse{
+			ID:        n.Actor.ID.String(),
+			FirstName: n.Actor.FirstName,
+			LastName:  n.Actor.LastName,
+			Username:  n.Actor.Username,
+			Email:     n.Actor.Email,
+		}
+	}
+	return resp
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+)
+
+// OIDCLogin godoc
+// @Summary Start OpenID Connect Login
+// @Description Redirects to the identity provider's authorization endpoint (authorization code flow with PKCE).
+// @Description Providers are configured with OIDC_PROVIDERS and OIDC_<NAME>_* environment variables.
+// @Router /auth/oidc/{provider}/login [get]
+// @Tags auth
+// @Param   provider path string true "Provider name"
+// @Success 302 "Redirect to the identity provider"
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+func OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	redirectURL, err := services.StartOIDCLogin(mux.Vars(r)["provider"])
+	if err != nil {
+		writeOIDCError(w, err)
+		return
+	}
+
+	// The redirect must not be reused: it carries a single-use state
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, redirectURL, http.StatusFound)
+}
+
+// OIDCCallback godoc
+// @Summary Complete OpenID Connect Login
+// @Description Redeems the authorization code returned by the identity provider and signs the user in.
+// @Description The identity is linked to the account with the same verified email, or a new account is created.
+// @Description When two-factor authentication is enabled, a challenge token is returned instead (see /auth/2fa/verify).
+// @Router /auth/oidc/{provider}/callback [get]
+// @Tags auth
+// @Produce  json
+// @Param   provider path string true "Provider name"
+// @Param   code query string true "Authorization code"
+// @Param   state query string true "State issued by the login endpoint"
+// @Success 200 {object} dto.AuthResponse
+// @Success 200 {object} dto.MFAChallengeResponse "Two-factor authentication required"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse "Registration is invite-only"
+// @Failure 404 {object} dto.ErrorResponse
+func OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("error") != "" {
+		utils.Error(w, http.StatusUnauthorized, "login was denied by the identity provider")
+		return
+	}
+	if query.Get("code") == "" || query.Get("state") == "" {
+		utils.Error(w, http.StatusBadRequest, "code and state are required")
+		return
+	}
+
+	tokens, challenge, err := services.CompleteOIDCLogin(mux.Vars(r)["provider"], query.Get("code"), query.Get("state"), utils.Client(r))
+	if err != nil {
+		writeOIDCError(w, err)
+		return
+	}
+
+	if challenge != nil {
+		utils.JSON(w, http.StatusOK, challenge)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, tokens)
+}
+
+// writeOIDCError maps OIDC service errors to HTTP responses.
+func writeOIDCError(w http.ResponseWriter, err error) {
+	switch err.(type) {
+	case *errors.AuthError:
+		utils.Error(w, http.StatusUnauthorized, err.Error())
+	case *errors.ForbiddenError:
+		utils.Error(w, http.StatusForbidden, err.Error())
+	case *errors.InternalServerError:
+		utils.Error(w, http.StatusInternalServerError, err.Error())
+	default:
+		if err.Error() == "identity provider not found" {
+			utils.Error(w, http.StatusNotFound, "Identity provider not found")
+			return
+		}
+		utils.Error(w, http.StatusInternalServerError, "Failed to sign in with the identity provider")
+	}
+}
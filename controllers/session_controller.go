@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/kfeuerschvenger/task-manager-api/middleware"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+)
+
+// ListSessions godoc
+// @Summary List sessions
+// @Description Lists the devices and browsers the authenticated user is logged in on, most recently used first.
+// @Description The session of the access token used for the request is flagged as current.
+// @Router /me/sessions [get]
+// @Tags users
+// @Produce  json
+// @Success 200 {array} dto.SessionResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+func ListSessions(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.ClaimsKey).(*utils.Claims)
+
+	sessions, err := services.ListSessions(claims.UserID, claims.SessionID)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "Failed to retrieve sessions")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, sessions)
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Logs the authenticated user out of one of their sessions. Its refresh token stops working immediately
+// @Description and its access tokens are rejected within TOKEN_REVOCATION_SYNC_INTERVAL on every replica.
+// @Router /me/sessions/{id} [delete]
+// @Tags users
+// @Param   id path string true "Session ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} dto.ErrorResponse "Invalid session ID"
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Session not found"
+// @Security BearerAuth
+func RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	if err := services.RevokeSession(userID, mux.Vars(r)["id"]); err != nil {
+		switch err.Error() {
+		case "session not found":
+			utils.Error(w, http.StatusNotFound, "Session not found")
+		case "invalid session ID":
+			utils.Error(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.Error(w, http.StatusInternalServerError, "Failed to revoke session")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -3,17 +3,24 @@ package controllers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
 	"github.com/kfeuerschvenger/task-manager-api/middleware"
+	"github.com/kfeuerschvenger/task-manager-api/models"
 	"github.com/kfeuerschvenger/task-manager-api/services"
 	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"github.com/kfeuerschvenger/task-manager-api/validators"
 )
 
 // CreateTask godoc
 // @Summary Create a new task
-// @Description Creates a new task with the provided details.
+// @Description Creates a new task with the provided details. Set parent_id to create a subtask of a task visible to the user,
+// @Description at most TASK_MAX_DEPTH levels deep.
 // @Router /tasks [post]
 // @Tags tasks
 // @Accept  json
@@ -21,6 +28,7 @@ import (
 // @Param   input body dto.CreateTaskInput true "Task details"
 // @Success 201 {object} dto.TaskResponse
 // @Failure 400 {object} dto.ErrorResponse "Invalid input or missing required fields"
+// @Failure 403 {object} dto.ErrorResponse "Blocked by the email verification policy"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
 // @Security BearerAuth
 func CreateTask(w http.ResponseWriter, r *http.Request) {
@@ -44,64 +52,220 @@ func CreateTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	creatorID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
 	if input.AssigneeID == "" {
 		input.AssigneeID = creatorID
 	}
 
-	task, err := services.CreateTask(input, creatorID)
+	task, err := services.CreateTask(input, creatorID, role)
 	if err != nil {
-		utils.Error(w, http.StatusInternalServerError, "Failed to create task")
+		switch err.(type) {
+		case *errors.ForbiddenError:
+			utils.Error(w, http.StatusForbidden, err.Error())
+		case *errors.ValidationError:
+			utils.Error(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.Error(w, http.StatusInternalServerError, "Failed to create task")
+		}
 		return
 	}
 
-	resp := dto.TaskResponse{
-    ID:          task.ID.String(),
-    Title:       task.Title,
-    Description: task.Description,
-    DueDate:     task.DueDate,
-    Status:      task.Status,
-    Priority:    task.Priority,
-	}
+	resp := toTaskResponse(task)
 	utils.JSON(w, http.StatusCreated, resp)
 }
 
 // GetTasks godoc
 // @Summary Get all tasks
-// @Description Retrieves all tasks for the authenticated user, with optional filtering by status and priority.
+// @Description Retrieves the tasks the authenticated user created or is assigned to, with optional filters and sort order.
+// @Description List filters accept comma-separated values. Results are paginated: pass the returned next_cursor as cursor,
+// @Description with the same filters and sort, to get the next page. The Link header holds the first and next page URLs.
 // @Router /tasks [get]
 // @Tags tasks
 // @Accept  json
 // @Produce  json
-// @Param   status query string false "Filter by task status (pending, in_progress, complete)"
-// @Param   priority query string false "Filter by task priority (low, medium, high)"
-// @Success 200 {array} dto.TaskResponse
+// @Param   status query string false "Filter by task status (pending, in_progress, complete), e.g. pending,in_progress"
+// @Param   priority query string false "Filter by task priority (low, medium, high), e.g. medium,high"
+// @Param   role query string false "Only tasks the user created (creator) or is assigned to (assignee)"
+// @Param   assignee_id query string false "Only tasks assigned to this user"
+// @Param   due_after query string false "Only tasks due at or after this RFC 3339 date"
+// @Param   due_before query string false "Only tasks due before this RFC 3339 date"
+// @Param   created_after query string false "Only tasks created at or after this RFC 3339 date"
+// @Param   overdue query bool false "Only tasks past their due date and not complete (or, when false, the others)"
+// @Param   label query string false "Only tasks carrying these labels, by comma-separated IDs"
+// @Param   label_match query string false "Whether tasks must carry any (default) or all of the labels"
+// @Param   q query string false "Only tasks matching these full-text search terms (see GET /tasks/search)"
+// @Param   sort query string false "Comma-separated sort fields (due_date, priority, status, title, created_at, updated_at, and rank when searching); prefix with - for descending. Default: due_date"
+// @Param   cursor query string false "Opaque cursor from the previous page"
+// @Param   limit query int false "Page size (1-100, default 20)"
+// @Success 200 {object} dto.TaskListResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid filter, sort, cursor or limit"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
 // @Security BearerAuth
 func GetTasks(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value(middleware.UserIDKey).(string)
 
-	// Optional query parameters for filtering
-	status := r.URL.Query().Get("status")
-	priority := r.URL.Query().Get("priority")
+	query, err := taskListQuery(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tasks, next, err := services.GetTasks(userID, query)
+	if err != nil {
+		if _, ok := err.(*errors.ValidationError); ok {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+		} else {
+			utils.Error(w, http.StatusInternalServerError, "Failed to retrieve tasks")
+		}
+		return
+	}
+
+	writeTaskList(w, r, tasks, next, query.Limit)
+}
+
+// SearchTasks godoc
+// @Summary Search tasks
+// @Description Full-text search over the titles and descriptions of the tasks the authenticated user created or is assigned to.
+// @Description Words are matched after stemming ("documents" finds "documentation") and must all match. Quote words to match
+// @Description them as a phrase and end a word with * to match it as a prefix, e.g. "release notes" deploy*.
+// @Description Results are ranked by relevance, title matches first, and carry HTML-escaped snippets with matches wrapped in <mark> tags.
+// @Description Accepts the same filters, sort order and pagination parameters as GET /tasks.
+// @Router /tasks/search [get]
+// @Tags tasks
+// @Produce  json
+// @Param   q query string true "Search terms (at most 200 characters)"
+// @Param   status query string false "Filter by task status (pending, in_progress, complete), comma-separated"
+// @Param   priority query string false "Filter by task priority (low, medium, high), comma-separated"
+// @Param   role query string false "Only tasks the user created (creator) or is assigned to (assignee)"
+// @Param   label query string false "Only tasks carrying these labels, by comma-separated IDs"
+// @Param   label_match query string false "Whether tasks must carry any (default) or all of the labels"
+// @Param   sort query string false "Comma-separated sort fields (rank, due_date, priority, status, title, created_at, updated_at); prefix with - for descending. Default: -rank"
+// @Param   cursor query string false "Opaque cursor from the previous page"
+// @Param   limit query int false "Page size (1-100, default 20)"
+// @Success 200 {object} dto.TaskSearchResponse
+// @Failure 400 {object} dto.ErrorResponse "Missing or invalid search terms, filter, sort, cursor or limit"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Security BearerAuth
+func SearchTasks(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	query, err := taskListQuery(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	tasks, err := services.GetTasks(userID, status, priority)
+	matches, next, err := services.SearchTasks(userID, query)
 	if err != nil {
-		utils.Error(w, http.StatusInternalServerError, "Failed to retrieve tasks")
+		if _, ok := err.(*errors.ValidationError); ok {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+		} else {
+			utils.Error(w, http.StatusInternalServerError, "Failed to search tasks")
+		}
 		return
 	}
 
-	// Map models to response DTOs
-	var resp []dto.TaskResponse
+	resp := dto.TaskSearchResponse{
+		Tasks: make([]dto.TaskSearchResult, 0, len(matches)),
+		Limit: query.Limit,
+	}
+	for _, m := range matches {
+		resp.Tasks = append(resp.Tasks, dto.TaskSearchResult{
+			TaskResponse: toTaskResponse(m.Task),
+			Rank:         m.Rank,
+			Highlights: dto.TaskHighlights{
+				Title:       m.TitleHighlight,
+				Description: m.DescriptionHighlight,
+			},
+		})
+	}
+	if next != "" {
+		resp.NextCursor = &next
+	}
+
+	utils.SetPaginationLinks(w, r, next)
+	utils.JSON(w, http.StatusOK, resp)
+}
+
+// taskListQuery reads and validates the filters, sort order and pagination parameters of a task listing.
+func taskListQuery(r *http.Request) (dto.TaskListQuery, error) {
+	params := r.URL.Query()
+
+	cursor, limit, err := utils.ParseCursorPagination(r)
+	if err != nil {
+		return dto.TaskListQuery{}, err
+	}
+
+	query := dto.TaskListQuery{
+		Statuses:   splitList(params.Get("status")),
+		Priorities: splitList(params.Get("priority")),
+		Role:       params.Get("role"),
+		AssigneeID: params.Get("assignee_id"),
+		Labels:     splitList(params.Get("label")),
+		LabelMatch: params.Get("label_match"),
+		Search:     strings.TrimSpace(params.Get("q")),
+		Sort:       params.Get("sort"),
+		Cursor:     cursor,
+		Limit:      limit,
+	}
+
+	dates := []struct {
+		name   string
+		target **time.Time
+	}{
+		{"due_after", &query.DueAfter},
+		{"due_before", &query.DueBefore},
+		{"created_after", &query.CreatedAfter},
+	}
+	for _, date := range dates {
+		if v := params.Get(date.name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return dto.TaskListQuery{}, errors.ErrInvalidField(date.name)
+			}
+			*date.target = &parsed
+		}
+	}
+
+	if v := params.Get("overdue"); v != "" {
+		overdue, err := strconv.ParseBool(v)
+		if err != nil {
+			return dto.TaskListQuery{}, errors.ErrInvalidField("overdue")
+		}
+		query.Overdue = &overdue
+	}
+
+	if err := validators.ValidateTaskListQuery(query); err != nil {
+		return dto.TaskListQuery{}, err
+	}
+	return query, nil
+}
+
+// splitList splits a comma-separated query parameter, ignoring empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// writeTaskList writes one page of tasks with its pagination links.
+func writeTaskList(w http.ResponseWriter, r *http.Request, tasks []models.Task, next string, limit int) {
+	resp := dto.TaskListResponse{
+		Tasks: make([]dto.TaskResponse, 0, len(tasks)),
+		Limit: limit,
+	}
 	for _, t := range tasks {
-			resp = append(resp, dto.TaskResponse{
-					ID:          t.ID.String(),
-					Title:       t.Title,
-					Description: t.Description,
-					DueDate:     t.DueDate,
-					Status:      t.Status,
-					Priority:    t.Priority,
-			})
+		resp.Tasks = append(resp.Tasks, toTaskResponse(t))
+	}
+	if next != "" {
+		resp.NextCursor = &next
 	}
+
+	utils.SetPaginationLinks(w, r, next)
 	utils.JSON(w, http.StatusOK, resp)
 }
 
@@ -116,9 +280,10 @@ func GetTasks(w http.ResponseWriter, r *http.Request) {
 // @Security BearerAuth
 func GetTaskByID(w http.ResponseWriter, r *http.Request) {
     userID := r.Context().Value(middleware.UserIDKey).(string)
+    role := r.Context().Value(middleware.RoleKey).(string)
     taskID := mux.Vars(r)["id"]
 
-    task, err := services.GetTaskByID(taskID, userID)
+    task, err := services.GetTaskByID(taskID, userID, role)
     if err != nil {
         if err.Error() == "task not found" {
             utils.Error(w, http.StatusNotFound, "Task not found")
@@ -128,20 +293,46 @@ func GetTaskByID(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    resp := dto.TaskResponse{
-        ID:          task.ID.String(),
-        Title:       task.Title,
-        Description: task.Description,
-        DueDate:     task.DueDate,
-        Status:      task.Status,
-        Priority:    task.Priority,
-    }
+    resp := toTaskResponse(*task)
     utils.JSON(w, http.StatusOK, resp)
 }
 
+// ListSubtasks godoc
+// @Summary List subtasks
+// @Description Lists the direct subtasks of a task that the authenticated user can see, by due date.
+// @Router /tasks/{id}/subtasks [get]
+// @Tags tasks
+// @Produce  json
+// @Param   id path string true "Task ID"
+// @Success 200 {array} dto.TaskResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Task not found"
+// @Security BearerAuth
+func ListSubtasks(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+
+	subtasks, err := services.ListSubtasks(mux.Vars(r)["id"], userID, role)
+	if err != nil {
+		if err.Error() == "task not found" {
+			utils.Error(w, http.StatusNotFound, "Task not found")
+		} else {
+			utils.Error(w, http.StatusInternalServerError, "Failed to retrieve subtasks")
+		}
+		return
+	}
+
+	resp := make([]dto.TaskResponse, 0, len(subtasks))
+	for _, t := range subtasks {
+		resp = append(resp, toTaskResponse(t))
+	}
+	utils.JSON(w, http.StatusOK, resp)
+}
+
 // UpdateTask godoc
 // @Summary Update an existing task
-// @Description Updates the details of an existing task.
+// @Description Updates the details of an existing task. Setting parent_id makes it a subtask of another task ("" makes it a top-level task again).
+// @Description A task with open subtasks can only be completed, and a task blocked by open tasks can only be started or completed, with force set.
 // @Router /tasks/{id} [put]
 // @Tags tasks
 // @Accept  json
@@ -152,9 +343,11 @@ func GetTaskByID(w http.ResponseWriter, r *http.Request) {
 // @Failure 400 {object} dto.ErrorResponse "Invalid input or missing required fields"
 // @Failure 404 {object} dto.ErrorResponse "Task not found"
 // @Failure 403 {object} dto.ErrorResponse "Unauthorized to update this task"
+// @Failure 409 {object} dto.ErrorResponse "The task has open subtasks or blocking tasks; set force to change its status anyway"
 // @Security BearerAuth
 func UpdateTask(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
 	taskID := mux.Vars(r)["id"]
 
 	var updateData dto.UpdateTaskDTO
@@ -163,10 +356,14 @@ func UpdateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := services.UpdateTask(taskID, userID, updateData)
+	task, err := services.UpdateTask(taskID, userID, role, updateData)
 	if err != nil {
-		if err.Error() == "unauthorized" {
-			utils.Error(w, http.StatusForbidden, "You are not the creator of this task")
+		if _, ok := err.(*errors.ForbiddenError); ok {
+			utils.Error(w, http.StatusForbidden, err.Error())
+			return
+		}
+		if _, ok := err.(*errors.ConflictError); ok {
+			utils.Error(w, http.StatusConflict, err.Error())
 			return
 		}
 		if err.Error() == "task not found" {
@@ -182,14 +379,7 @@ func UpdateTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Map to response DTO
-	resp := dto.TaskResponse{
-		ID:          task.ID.String(),
-		Title:       task.Title,
-		Description: task.Description,
-		DueDate:     task.DueDate,
-		Status:      task.Status,
-		Priority:    task.Priority,
-	}
+	resp := toTaskResponse(*task)
 	utils.JSON(w, http.StatusOK, resp)
 }
 
@@ -205,15 +395,18 @@ func UpdateTask(w http.ResponseWriter, r *http.Request) {
 // @Security BearerAuth
 func DeleteTask(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
 	taskID := mux.Vars(r)["id"]
 
-	err := services.DeleteTask(taskID, userID)
+	err := services.DeleteTask(taskID, userID, role)
 	if err != nil {
+		if _, ok := err.(*errors.ForbiddenError); ok {
+			utils.Error(w, http.StatusForbidden, "You are not authorized to delete this task")
+			return
+		}
 		switch err.Error() {
 		case "task not found":
 			utils.Error(w, http.StatusNotFound, "Task not found")
-		case "unauthorized":
-			utils.Error(w, http.StatusForbidden, "You are not authorized to delete this task")
 		default:
 			utils.Error(w, http.StatusInternalServerError, "Error deleting task")
 		}
@@ -222,3 +415,41 @@ func DeleteTask(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// toTaskResponse maps a task model to its response DTO.
+func toTaskResponse(t models.Task) dto.TaskResponse {
+	labels := make([]dto.LabelResponse, 0, len(t.Labels))
+	for _, l := range t.Labels {
+		labels = append(labels, toLabelResponse(l))
+	}
+
+	var parentID *string
+	if t.ParentID != nil {
+		id := t.ParentID.String()
+		parentID = &id
+	}
+
+	return dto.TaskResponse{
+		ID:          t.ID.String(),
+		Title:       t.Title,
+		Description: t.Description,
+		DueDate:     t.DueDate,
+		Status:      t.Status,
+		Priority:    t.Priority,
+		Labels:      labels,
+		ParentID:    parentID,
+		Subtasks:    dto.TaskProgress{Done: t.SubtaskProgress.Done, Total: t.SubtaskProgress.Total},
+		Checklist:   dto.TaskProgress{Done: t.ChecklistProgress.Done, Total: t.ChecklistProgress.Total},
+		BlockedBy:   toTaskReferences(t.BlockedBy),
+		Blocking:    toTaskReferences(t.Blocking),
+	}
+}
+
+// toTaskReferences maps related tasks to their reference DTOs.
+func toTaskReferences(tasks []models.Task) []dto.TaskReference {
+	refs := make([]dto.TaskReference, 0, len(tasks))
+	for _, t := range tasks {
+		refs = append(refs, dto.TaskReference{ID: t.ID.String(), Title: t.Title, Status: t.Status})
+	}
+	return refs
+}
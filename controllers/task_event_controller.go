@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/middleware"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+)
+
+// GetTaskHistory godoc
+// @Summary Get a task's history
+// @Description Lists the changes made to a task, newest first: who created, updated or deleted it, when, and the
+// @Description values of each changed field before and after. Admins can also read the history of deleted tasks.
+// @Description Pages are keyset-paginated: follow next_cursor, or the Link header, to fetch the next page.
+// @Router /tasks/{id}/history [get]
+// @Tags tasks
+// @Produce  json
+// @Param   id path string true "Task ID"
+// @Param   cursor query string false "Cursor of the next page, from a previous response"
+// @Param   limit query int false "Page size (1-100, default 20)"
+// @Success 200 {object} dto.TaskHistoryResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid cursor or limit"
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Task not found"
+// @Security BearerAuth
+func GetTaskHistory(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	role := r.Context().Value(middleware.RoleKey).(string)
+
+	cursor, limit, err := utils.ParseCursorPagination(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	events, next, err := services.ListTaskHistory(mux.Vars(r)["id"], userID, role, cursor, limit)
+	if err != nil {
+		switch err.(type) {
+		case *errors.ValidationError:
+			utils.Error(w, http.StatusBadRequest, err.Error())
+		default:
+			if err.Error() == "task not found" {
+				utils.Error(w, http.StatusNotFound, "Task not found")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Failed to retrieve task history")
+		}
+		return
+	}
+
+	resp := dto.TaskHistoryResponse{
+		Events: make([]dto.TaskEventResponse, 0, len(events)),
+		Limit:  limit,
+	}
+	for _, e := range events {
+		resp.Events = append(resp.Events, toTaskEventResponse(e))
+	}
+	if next != "" {
+		resp.NextCursor = &next
+	}
+
+	utils.SetPaginationLinks(w, r, next)
+	utils.JSON(w, http.StatusOK, resp)
+}
+
+// toTaskEventResponse maps a task event model to its response DTO.
+func toTaskEventResponse(e models.TaskEvent) dto.TaskEventResponse {
+	resp := dto.TaskEventResponse{
+		ID:        e.ID.String(),
+		TaskID:    e.TaskID.String(),
+		Action:    e.Action,
+		Changes:   make(map[string]dto.FieldChange, len(e.Changes)),
+		CreatedAt: e.CreatedAt,
+	}
+	for field, change := range e.Changes {
+		resp.Changes[field] = dto.FieldChange{Before: change.Before, After: change.After}
+	}
+	if e.Actor != nil {
+		resp.Actor = &dto.PublicUserResponse{
+			ID:        e.Actor.ID.String(),
+			FirstName: e.Actor.FirstName,
+			LastName:  e.Actor.LastName,
+			Username:  e.Actor.Username,
+			Email:     e.Actor.Email,
+		}
+	}
+	return resp
+}
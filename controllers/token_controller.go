@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/middleware"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"github.com/kfeuerschvenger/task-manager-api/validators"
+)
+
+// CreatePersonalAccessToken godoc
+// @Summary Create a personal access token
+// @Description Creates a scoped token for scripts and CI. The token is only returned in this response.
+// @Router /me/tokens [post]
+// @Tags tokens
+// @Accept  json
+// @Produce  json
+// @Param   input body dto.CreatePersonalAccessTokenRequest true "Token details"
+// @Success 201 {object} dto.CreatedPersonalAccessTokenResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse "Personal access tokens cannot manage tokens"
+// @Security BearerAuth
+func CreatePersonalAccessToken(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var req dto.CreatePersonalAccessTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := validators.ValidateCreatePersonalAccessTokenInput(req); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, err := services.CreatePersonalAccessToken(userID, req)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "Failed to create token")
+		return
+	}
+
+	utils.JSON(w, http.StatusCreated, token)
+}
+
+// ListPersonalAccessTokens godoc
+// @Summary List personal access tokens
+// @Description Lists the authenticated user's personal access tokens. Secrets are never returned.
+// @Router /me/tokens [get]
+// @Tags tokens
+// @Produce  json
+// @Success 200 {array} dto.PersonalAccessTokenResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+func ListPersonalAccessTokens(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	tokens, err := services.ListPersonalAccessTokens(userID)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "Failed to retrieve tokens")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, tokens)
+}
+
+// DeletePersonalAccessToken godoc
+// @Summary Revoke a personal access token
+// @Description Deletes one of the authenticated user's personal access tokens.
+// @Router /me/tokens/{id} [delete]
+// @Tags tokens
+// @Param   id path string true "Token ID"
+// @Success 204 {object} nil
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse "Token not found"
+// @Security BearerAuth
+func DeletePersonalAccessToken(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	tokenID := mux.Vars(r)["id"]
+
+	if err := services.DeletePersonalAccessToken(userID, tokenID); err != nil {
+		if err.Error() == "token not found" {
+			utils.Error(w, http.StatusNotFound, "Token not found")
+		} else {
+			utils.Error(w, http.StatusInternalServerError, "Failed to revoke token")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/middleware"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+)
+
+// SetupTwoFactor godoc
+// @Summary Start Two-Factor Setup
+// @Description Generates a new TOTP secret and returns it with an otpauth URI for authenticator apps.
+// @Description Two-factor authentication is not enforced until the secret is confirmed.
+// @Router /auth/2fa/setup [post]
+// @Tags 2fa
+// @Produce  json
+// @Success 200 {object} dto.TwoFactorSetupResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "Two-factor authentication already enabled"
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+func SetupTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	setup, err := services.SetupTwoFactor(userID)
+	if err != nil {
+		writeTwoFactorError(w, err, "Failed to set up two-factor authentication")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, setup)
+}
+
+// ConfirmTwoFactor godoc
+// @Summary Confirm Two-Factor Setup
+// @Description Enables two-factor authentication after validating a code from the authenticator app.
+// @Description Returns one-time recovery codes that are only shown once.
+// @Router /auth/2fa/confirm [post]
+// @Tags 2fa
+// @Accept  json
+// @Produce  json
+// @Param   input body dto.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} dto.RecoveryCodesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "Two-factor authentication already enabled"
+// @Security BearerAuth
+func ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var req dto.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	codes, err := services.ConfirmTwoFactor(userID, req.Code)
+	if err != nil {
+		writeTwoFactorError(w, err, "Failed to enable two-factor authentication")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, codes)
+}
+
+// DisableTwoFactor godoc
+// @Summary Disable Two-Factor Authentication
+// @Description Turns off two-factor authentication. Requires the current password and a TOTP or recovery code.
+// @Router /auth/2fa [delete]
+// @Tags 2fa
+// @Accept  json
+// @Param   input body dto.DisableTwoFactorRequest true "Re-authentication details"
+// @Success 204 {object} nil
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Security BearerAuth
+func DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var req dto.DisableTwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := services.DisableTwoFactor(userID, req.Password, req.Code); err != nil {
+		writeTwoFactorError(w, err, "Failed to disable two-factor authentication")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifyTwoFactorLogin godoc
+// @Summary Complete Two-Factor Login
+// @Description Exchanges the challenge token returned by /auth/login and a TOTP or recovery code for a token pair.
+// @Router /auth/2fa/verify [post]
+// @Tags 2fa
+// @Accept  json
+// @Produce  json
+// @Param   input body dto.TwoFactorLoginRequest true "Challenge token and code"
+// @Success 200 {object} dto.AuthResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse "Too many failed attempts; see the Retry-After header"
+func VerifyTwoFactorLogin(w http.ResponseWriter, r *http.Request) {
+	var req dto.TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if strings.TrimSpace(req.ChallengeToken) == "" || strings.TrimSpace(req.Code) == "" {
+		utils.Error(w, http.StatusBadRequest, "challenge token and code are required")
+		return
+	}
+
+	tokens, err := services.CompleteTwoFactorLogin(req.ChallengeToken, req.Code, utils.Client(r))
+	if err != nil {
+		writeTwoFactorError(w, err, "Failed to complete login")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, tokens)
+}
+
+// writeTwoFactorError maps two-factor service errors to HTTP responses.
+func writeTwoFactorError(w http.ResponseWriter, err error, fallback string) {
+	switch e := err.(type) {
+	case *errors.ValidationError:
+		utils.Error(w, http.StatusBadRequest, err.Error())
+	case *errors.AuthError:
+		utils.Error(w, http.StatusUnauthorized, err.Error())
+	case *errors.ConflictError:
+		utils.Error(w, http.StatusConflict, err.Error())
+	case *errors.TooManyRequestsError:
+		utils.TooManyRequests(w, e.RetryAfter, err.Error())
+	default:
+		utils.Error(w, http.StatusInternalServerError, fallback)
+	}
+}
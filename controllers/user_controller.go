@@ -0,0 +1,252 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/middleware"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"github.com/kfeuerschvenger/task-manager-api/validators"
+)
+
+// GetMe godoc
+// @Summary Get current user
+// @Description Returns the profile of the authenticated user.
+// @Router /me [get]
+// @Tags users
+// @Produce  json
+// @Success 200 {object} dto.UserResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+func GetMe(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	user, err := services.GetProfile(userID)
+	if err != nil {
+		utils.Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, user)
+}
+
+// UpdateMe godoc
+// @Summary Update current user
+// @Description Updates the name, username, timezone or locale of the authenticated user. Omitted fields are left unchanged.
+// @Description Other users can @mention the user by username in task descriptions and comments.
+// @Router /me [patch]
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param   input body dto.UpdateProfileRequest true "Profile fields to update"
+// @Success 200 {object} dto.UserResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "Username already taken"
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+func UpdateMe(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var req dto.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := validators.ValidateUpdateProfileInput(req); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := services.UpdateProfile(userID, req)
+	if err != nil {
+		switch err.(type) {
+		case *errors.ValidationError:
+			utils.Error(w, http.StatusBadRequest, err.Error())
+		case *errors.ConflictError:
+			utils.Error(w, http.StatusConflict, err.Error())
+		default:
+			utils.Error(w, http.StatusInternalServerError, "Failed to update profile")
+		}
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, user)
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Changes the password of the authenticated user. Every other session is revoked
+// @Description and a new token pair is returned for the current client.
+// @Router /me/password [post]
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param   input body dto.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} dto.AuthResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse "Wrong current password"
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+func ChangePassword(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.ClaimsKey).(*utils.Claims)
+
+	var req dto.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := validators.ValidateChangePasswordInput(req); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tokens, err := services.ChangePassword(claims.UserID, req, utils.Client(r))
+	if err != nil {
+		switch err.(type) {
+		case *errors.AuthError:
+			utils.Error(w, http.StatusUnauthorized, err.Error())
+		default:
+			utils.Error(w, http.StatusInternalServerError, "Failed to change password")
+		}
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, tokens)
+}
+
+// ChangeEmail godoc
+// @Summary Change email address
+// @Description Starts moving the account to a new email address. A confirmation link is sent to the
+// @Description new address and the change is only applied once it is opened (see /auth/email/confirm).
+// @Router /me/email [post]
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param   input body dto.ChangeEmailRequest true "New email and current password"
+// @Success 202 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse "Wrong password"
+// @Failure 409 {object} dto.ErrorResponse "Email already registered"
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+func ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var req dto.ChangeEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := validators.ValidateChangeEmailInput(req); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := services.RequestEmailChange(userID, req); err != nil {
+		writeEmailChangeError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusAccepted, dto.MessageResponse{Message: "A confirmation link has been sent to the new address"})
+}
+
+// ConfirmEmailChange godoc
+// @Summary Confirm email change
+// @Description Applies a pending email change using the token sent to the new address.
+// @Router /auth/email/confirm [get]
+// @Tags users
+// @Produce  json
+// @Param   token query string true "Email change token"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "Email already registered"
+// @Failure 500 {object} dto.ErrorResponse
+func ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utils.Error(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	if err := services.ConfirmEmailChange(token); err != nil {
+		writeEmailChangeError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, dto.MessageResponse{Message: "Email address updated"})
+}
+
+// writeEmailChangeError maps email change service errors to HTTP responses.
+func writeEmailChangeError(w http.ResponseWriter, err error) {
+	switch err.(type) {
+	case *errors.ValidationError:
+		utils.Error(w, http.StatusBadRequest, err.Error())
+	case *errors.AuthError:
+		utils.Error(w, http.StatusUnauthorized, err.Error())
+	case *errors.ConflictError:
+		utils.Error(w, http.StatusConflict, err.Error())
+	default:
+		utils.Error(w, http.StatusInternalServerError, "Failed to change email")
+	}
+}
+
+// ListUsers godoc
+// @Summary Search users
+// @Description Lists users for picking assignees. The optional query matches the start of the first name,
+// @Description last name, full name or email, case-insensitively.
+// @Router /users [get]
+// @Tags users
+// @Produce  json
+// @Param   query query string false "Name, username or email prefix"
+// @Param   page  query int    false "Page number (default 1)"
+// @Param   limit query int    false "Page size (default 20, max 100)"
+// @Success 200 {object} dto.UserListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+func ListUsers(w http.ResponseWriter, r *http.Request) {
+	page, limit, err := utils.ParsePagination(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	users, err := services.SearchUsers(r.URL.Query().Get("query"), page, limit)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "Failed to retrieve users")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, users)
+}
+
+// GetUser godoc
+// @Summary Get a user
+// @Description Returns the public profile of a user.
+// @Router /users/{id} [get]
+// @Tags users
+// @Produce  json
+// @Param   id path string true "User ID"
+// @Success 200 {object} dto.PublicUserResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+func GetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := services.GetPublicProfile(mux.Vars(r)["id"])
+	if err != nil {
+		utils.Error(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, user)
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -31,4 +32,11 @@ func Connect() error {
 
 	DB = db
 	return nil
-}
\ No newline at end of file
+}
+
+// IsUniqueViolation reports whether err is a Postgres unique constraint violation (SQLSTATE 23505),
+// e.g. when a concurrent request inserted the same value between a check and the write.
+func IsUniqueViolation(err error) bool {
+	var pgErr interface{ SQLState() string }
+	return errors.As(err, &pgErr) && pgErr.SQLState() == "23505"
+}
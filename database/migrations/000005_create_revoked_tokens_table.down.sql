@@ -0,0 +1,4 @@
+ALTER TABLE users DROP COLUMN IF EXISTS tokens_invalid_before;
+
+DROP INDEX IF EXISTS idx_revoked_tokens_expires_at;
+DROP TABLE IF EXISTS revoked_tokens;
\ No newline at end of file
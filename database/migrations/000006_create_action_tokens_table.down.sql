@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_action_tokens_user_purpose;
+DROP TABLE IF EXISTS action_tokens;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_lockout_events_created_at;
+DROP TABLE IF EXISTS lockout_events;
+DROP TABLE IF EXISTS login_attempts;
\ No newline at end of file
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS login_attempts (
+    key TEXT PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL DEFAULT '0001-01-01 00:00:00',
+    locked_until TIMESTAMP NOT NULL DEFAULT '0001-01-01 00:00:00'
+);
+
+CREATE TABLE IF NOT EXISTS lockout_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    key TEXT NOT NULL,
+    failures INTEGER NOT NULL,
+    locked_until TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_lockout_events_created_at ON lockout_events(created_at);
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC',
+    ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT 'en',
+    ADD COLUMN IF NOT EXISTS pending_email TEXT;
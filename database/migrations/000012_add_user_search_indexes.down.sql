@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_email_prefix;
+DROP INDEX IF EXISTS idx_users_last_name_prefix;
+DROP INDEX IF EXISTS idx_users_first_name_prefix;
@@ -0,0 +1,4 @@
+-- Prefix search over names and emails (lower(column) LIKE 'prefix%')
+CREATE INDEX IF NOT EXISTS idx_users_first_name_prefix ON users (lower(first_name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_users_last_name_prefix ON users (lower(last_name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_users_email_prefix ON users (lower(email) text_pattern_ops);
@@ -0,0 +1,10 @@
+ALTER TABLE tasks
+    DROP CONSTRAINT IF EXISTS fk_creator,
+    DROP CONSTRAINT IF EXISTS fk_assignee;
+
+ALTER TABLE tasks
+    ADD CONSTRAINT fk_creator FOREIGN KEY (creator_id) REFERENCES users(id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_assignee FOREIGN KEY (assignee_id) REFERENCES users(id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deletion_scheduled_at;
@@ -0,0 +1,13 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at ON users(deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
+
+-- Deleting a user must never take other people's tasks with it: the account deletion
+-- job reassigns tasks first, and any other delete of a user with tasks now fails.
+ALTER TABLE tasks
+    DROP CONSTRAINT IF EXISTS fk_creator,
+    DROP CONSTRAINT IF EXISTS fk_assignee;
+
+ALTER TABLE tasks
+    ADD CONSTRAINT fk_creator FOREIGN KEY (creator_id) REFERENCES users(id) ON DELETE RESTRICT,
+    ADD CONSTRAINT fk_assignee FOREIGN KEY (assignee_id) REFERENCES users(id) ON DELETE RESTRICT;
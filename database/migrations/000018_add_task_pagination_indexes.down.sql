@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tasks_assignee_due_date_id;
+DROP INDEX IF EXISTS idx_tasks_creator_due_date_id;
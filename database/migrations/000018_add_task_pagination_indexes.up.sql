@@ -0,0 +1,3 @@
+-- Task listings filter by creator or assignee and page over (due_date, id)
+CREATE INDEX IF NOT EXISTS idx_tasks_creator_due_date_id ON tasks(creator_id, due_date, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee_due_date_id ON tasks(assignee_id, due_date, id);
@@ -0,0 +1,27 @@
+-- Personal labels belong to owner_id; team labels (owner_id NULL) are shared by every user
+CREATE TABLE IF NOT EXISTS labels (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID,
+    created_by UUID,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(7) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_labels_owner FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_labels_created_by FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Label names are unique, ignoring case, among a user's personal labels and among team labels
+CREATE UNIQUE INDEX IF NOT EXISTS idx_labels_owner_name ON labels(owner_id, LOWER(name)) WHERE owner_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_labels_team_name ON labels(LOWER(name)) WHERE owner_id IS NULL;
+
+CREATE TABLE IF NOT EXISTS task_labels (
+    task_id UUID NOT NULL,
+    label_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, label_id),
+    CONSTRAINT fk_task_labels_task FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_labels_label FOREIGN KEY (label_id) REFERENCES labels(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_labels_label_id ON task_labels(label_id);
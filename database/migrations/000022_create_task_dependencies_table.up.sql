@@ -0,0 +1,12 @@
+-- task_id is blocked by blocked_by_id: it cannot start or be completed until blocked_by_id is complete
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    task_id UUID NOT NULL,
+    blocked_by_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, blocked_by_id),
+    CONSTRAINT fk_task_dependencies_task FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_dependencies_blocked_by FOREIGN KEY (blocked_by_id) REFERENCES tasks(id) ON DELETE CASCADE,
+    CONSTRAINT chk_task_dependencies_self CHECK (task_id <> blocked_by_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_blocked_by_id ON task_dependencies(blocked_by_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS task_events;
+DROP FUNCTION IF EXISTS prevent_task_event_changes();
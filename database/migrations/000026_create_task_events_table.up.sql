@@ -0,0 +1,28 @@
+-- Audit trail of task changes. Events reference neither tasks nor users, so that they outlive them.
+CREATE TABLE IF NOT EXISTS task_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    task_id UUID NOT NULL,
+    actor_id UUID,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('created', 'updated', 'deleted')),
+    changes JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_events_task_id_created_at ON task_events(task_id, created_at DESC, id DESC);
+
+-- Events can be appended but never changed or removed
+CREATE OR REPLACE FUNCTION prevent_task_event_changes() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'task events are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS task_events_immutable ON task_events;
+CREATE TRIGGER task_events_immutable
+    BEFORE UPDATE OR DELETE ON task_events
+    FOR EACH ROW EXECUTE FUNCTION prevent_task_event_changes();
+
+DROP TRIGGER IF EXISTS task_events_no_truncate ON task_events;
+CREATE TRIGGER task_events_no_truncate
+    BEFORE TRUNCATE ON task_events
+    FOR EACH STATEMENT EXECUTE FUNCTION prevent_task_event_changes();
@@ -0,0 +1,9 @@
+-- Second-factor attempts made with each two-factor login challenge, by the challenge's jti claim.
+-- Rows can be pruned once the challenge has expired.
+CREATE TABLE IF NOT EXISTS mfa_challenge_attempts (
+    jti TEXT PRIMARY KEY,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_challenge_attempts_expires_at ON mfa_challenge_attempts(expires_at);
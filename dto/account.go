@@ -0,0 +1,74 @@
+package dto
+
+import "time"
+
+// DeleteAccountRequest re-authenticates the user before their account is scheduled for deletion.
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required" example:"SecurePassword123"`
+}
+
+// AccountDeletionResponse tells the user when their account will be deleted.
+type AccountDeletionResponse struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at" example:"2025-07-01T15:04:05Z"`
+}
+
+// AccountExport is the archive of a user's personal data returned by GET /me/export.
+type AccountExport struct {
+	ExportedAt           time.Time                     `json:"exported_at" example:"2025-06-01T15:04:05Z"`
+	Profile              UserResponse                  `json:"profile"`
+	Tasks                []ExportedTask                `json:"tasks"`
+	Labels               []LabelResponse               `json:"labels"`   // personal labels and team labels the user created
+	Comments             []CommentResponse             `json:"comments"` // comments the user wrote
+	Activity             []ExportedTaskEvent           `json:"activity"` // changes the user made to tasks
+	Notifications        []ExportedNotification        `json:"notifications"`
+	Sessions             []ExportedSession             `json:"sessions"`
+	PersonalAccessTokens []PersonalAccessTokenResponse `json:"personal_access_tokens"`
+	Identities           []ExportedIdentity            `json:"identities"`
+	InvitationsSent      []InvitationResponse          `json:"invitations_sent"`
+}
+
+// ExportedTask is a task the user created or is assigned to.
+type ExportedTask struct {
+	TaskResponse
+	CreatorID  string    `json:"creator_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	AssigneeID string    `json:"assignee_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CreatedAt  time.Time `json:"created_at" example:"2025-06-01T15:04:05Z"`
+	UpdatedAt  time.Time `json:"updated_at" example:"2025-06-01T15:04:05Z"`
+}
+
+// ExportedTaskEvent is a change the user made to a task, as recorded in the task's history.
+type ExportedTaskEvent struct {
+	TaskID    string                 `json:"task_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Action    string                 `json:"action" example:"updated"`
+	Changes   map[string]FieldChange `json:"changes"`
+	CreatedAt time.Time              `json:"created_at" example:"2025-06-01T15:04:05Z"`
+}
+
+// ExportedNotification is a notification the user received. ActorID is null once the acting user's
+// account is deleted.
+type ExportedNotification struct {
+	Kind      string     `json:"kind" example:"comment_mention"`
+	TaskID    string     `json:"task_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	CommentID *string    `json:"comment_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ActorID   *string    `json:"actor_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ReadAt    *time.Time `json:"read_at" example:"2025-06-01T16:20:00Z"`
+	CreatedAt time.Time  `json:"created_at" example:"2025-06-01T15:04:05Z"`
+}
+
+// ExportedSession is a login of the user on one device.
+type ExportedSession struct {
+	UserAgent  string     `json:"user_agent" example:"Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0"`
+	IPAddress  string     `json:"ip_address" example:"203.0.113.7"`
+	StartedAt  time.Time  `json:"started_at" example:"2025-06-01T15:04:05Z"`
+	LastSeenAt time.Time  `json:"last_seen_at" example:"2025-06-02T09:30:00Z"`
+	ExpiresAt  time.Time  `json:"expires_at" example:"2025-07-01T15:04:05Z"`
+	RevokedAt  *time.Time `json:"revoked_at" example:"2025-06-02T15:04:05Z"`
+}
+
+// ExportedIdentity is an external identity provider account linked to the user.
+type ExportedIdentity struct {
+	Provider  string    `json:"provider" example:"sso"`
+	Subject   string    `json:"subject" example:"248289761001"`
+	Email     string    `json:"email" example:"user@example.com"`
+	CreatedAt time.Time `json:"created_at" example:"2025-06-01T15:04:05Z"`
+}
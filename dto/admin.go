@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// AdminUserResponse describes a user account as seen by administrators.
+type AdminUserResponse struct {
+	ID               string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	FirstName        string     `json:"first_name" example:"John"`
+	LastName         string     `json:"last_name" example:"Doe"`
+	Email            string     `json:"email" example:"user@example.com"`
+	Role             string     `json:"role" example:"member"`
+	EmailVerified    bool       `json:"email_verified" example:"true"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled" example:"false"`
+	DisabledAt       *time.Time `json:"disabled_at" example:"2025-06-01T15:04:05Z"`
+	CreatedAt        time.Time  `json:"created_at" example:"2025-06-01T15:04:05Z"`
+}
+
+// AdminUserListResponse is a page of user accounts for administrators.
+type AdminUserListResponse struct {
+	Users []AdminUserResponse `json:"users"`
+	Page  int                 `json:"page" example:"1"`
+	Limit int                 `json:"limit" example:"20"`
+	Total int64               `json:"total" example:"42"`
+}
+
+// UpdateRoleRequest represents the data required to change a user's role.
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin member viewer" example:"viewer"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// AttachmentResponse describes a file attached to a task.
+type AttachmentResponse struct {
+	ID          string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TaskID      string    `json:"task_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	UploaderID  *string   `json:"uploader_id" example:"123e4567-e89b-12d3-a456-426614174000"` // null once the uploader's account is deleted
+	FileName    string    `json:"file_name" example:"wireframes.png"`
+	ContentType string    `json:"content_type" example:"image/png"` // detected from the contents
+	Size        int64     `json:"size" example:"48213"`             // in bytes
+	SHA256      string    `json:"sha256" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	DownloadURL string    `json:"download_url" example:"http://localhost:8080/tasks/123e4567-e89b-12d3-a456-426614174000/attachments/550e8400-e29b-41d4-a716-446655440000"`
+	CreatedAt   time.Time `json:"created_at" example:"2025-06-01T15:04:05Z"`
+}
@@ -6,10 +6,88 @@ type RegisterRequest struct {
 	LastName  string `json:"last_name" binding:"required" example:"Doe"`
 	Email     string `json:"email" binding:"required,email" example:"user@example.com"`
 	Password  string `json:"password" binding:"required,min=6" example:"SecurePassword123"`
+
+	// InviteToken is the token from an invitation email. The email must match the invitation,
+	// which counts as verified. Required when registration is invite-only.
+	InviteToken string `json:"invite_token,omitempty" example:"3q2-7wQKx1c0bS3h1yJ6m0Zb2kq9oYt4Yw8n1Vd5c2E"`
 }
 
 // LoginRequest represents the data required for user login.
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email" example:"user@example.com"`
 	Password string `json:"password" binding:"required" example:"SecurePassword123"`
-}
\ No newline at end of file
+}
+
+// RefreshRequest represents the data required to exchange a refresh token for a new token pair.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required" example:"3q2-7wQKx1c0bS3h1yJ6m0Zb2kq9oYt4Yw8n1Vd5c2E"`
+}
+
+// AuthResponse represents the token pair returned after a successful login, registration or refresh.
+// The access token is short-lived; the refresh token is single-use and rotated on every refresh.
+type AuthResponse struct {
+	Token        string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string `json:"refresh_token" example:"3q2-7wQKx1c0bS3h1yJ6m0Zb2kq9oYt4Yw8n1Vd5c2E"`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresAt    string `json:"expires_at" example:"2025-06-01T15:04:05Z"` // access token expiration, ISO string
+}
+
+// LogoutRequest optionally carries the refresh token of the session being closed,
+// so it is revoked together with the current access token.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty" example:"3q2-7wQKx1c0bS3h1yJ6m0Zb2kq9oYt4Yw8n1Vd5c2E"`
+}
+
+// ForgotPasswordRequest represents the data required to request a password reset email.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email" example:"user@example.com"`
+}
+
+// ResetPasswordRequest represents the data required to set a new password with a reset token.
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required" example:"Zb2kq9oYt4Yw8n1Vd5c2E3q2-7wQKx1c0bS3h1yJ6m0"`
+	Password string `json:"password" binding:"required,min=6" example:"NewSecurePassword123"`
+}
+
+// MessageResponse represents a response that only carries a human-readable message.
+type MessageResponse struct {
+	Message string `json:"message" example:"If the email is registered, a reset link has been sent"`
+}
+
+// MFAChallengeResponse is returned by login instead of a token pair when the account has
+// two-factor authentication enabled. The challenge token is exchanged at /auth/2fa/verify.
+type MFAChallengeResponse struct {
+	MFARequired    bool   `json:"mfa_required" example:"true"`
+	ChallengeToken string `json:"challenge_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ExpiresAt      string `json:"expires_at" example:"2025-06-01T15:04:05Z"` // ISO string
+}
+
+// TwoFactorLoginRequest represents the second step of a two-factor login.
+// Code accepts either a TOTP code or an unused recovery code.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Code           string `json:"code" binding:"required" example:"123456"`
+}
+
+// TwoFactorSetupResponse carries a new TOTP secret and the otpauth URI to enroll it in an authenticator app.
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	OTPAuthURI string `json:"otpauth_uri" example:"otpauth://totp/Task%20Manager:user@example.com?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&issuer=Task+Manager"`
+}
+
+// TwoFactorCodeRequest represents a request carrying a single TOTP code.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+// RecoveryCodesResponse lists one-time recovery codes. They are only shown once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k3jd9-a8f2m,p0qz7-x1c4v"`
+}
+
+// DisableTwoFactorRequest re-authenticates the user before two-factor authentication is turned off.
+// Code accepts either a TOTP code or an unused recovery code.
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required" example:"SecurePassword123"`
+	Code     string `json:"code" binding:"required" example:"123456"`
+}
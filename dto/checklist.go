@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+// CreateChecklistItemRequest represents the data required to add an item to a task's checklist.
+type CreateChecklistItemRequest struct {
+	Text     string `json:"text" binding:"required" example:"Update the changelog"`
+	Position *int   `json:"position,omitempty" example:"0"` // 0-based; defaults to the end of the checklist
+}
+
+// UpdateChecklistItemRequest edits, checks or moves a checklist item. Omitted fields are left unchanged.
+type UpdateChecklistItemRequest struct {
+	Text     string `json:"text,omitempty" example:"Update the changelog and the docs"`
+	Done     *bool  `json:"done,omitempty" example:"true"`
+	Position *int   `json:"position,omitempty" example:"2"` // 0-based; the other items shift to make room
+}
+
+// ChecklistItemResponse describes an item of a task's checklist.
+type ChecklistItemResponse struct {
+	ID        string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Text      string    `json:"text" example:"Update the changelog"`
+	Done      bool      `json:"done" example:"false"`
+	Position  int       `json:"position" example:"0"`
+	CreatedAt time.Time `json:"created_at" example:"2025-06-01T15:04:05Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2025-06-01T15:04:05Z"`
+}
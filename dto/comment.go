@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+// CommentRequest represents the body of a new or edited comment, in Markdown.
+type CommentRequest struct {
+	Body string `json:"body" binding:"required" example:"Blocked on the **API review**, see the notes from Monday."`
+}
+
+// CommentAuthor identifies the author of a comment. Once their account is deleted, ID is null
+// and the name reads "Deleted user".
+type CommentAuthor struct {
+	ID        *string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	FirstName string  `json:"first_name" example:"John"`
+	LastName  string  `json:"last_name" example:"Doe"`
+}
+
+// CommentResponse describes a comment on a task. The body is the raw Markdown written by its author.
+type CommentResponse struct {
+	ID        string        `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TaskID    string        `json:"task_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Author    CommentAuthor `json:"author"`
+	Body      string        `json:"body" example:"Blocked on the **API review**, see the notes from Monday."`
+	CreatedAt time.Time     `json:"created_at" example:"2025-06-01T15:04:05Z"`
+	EditedAt  *time.Time    `json:"edited_at" example:"2025-06-01T16:20:00Z"` // null unless the body was edited
+}
+
+// CommentListResponse is one page of a task's comments, oldest first.
+// NextCursor is null on the last page.
+type CommentListResponse struct {
+	Comments   []CommentResponse `json:"comments"`
+	NextCursor *string           `json:"next_cursor" example:"eyJjcmVhdGVkX2F0IjoiMjAyNS0wNi0wMVQxNTowNDowNVoiLCJpZCI6IjU1MGU4NDAwLWUyOWItNDFkNC1hNzE2LTQ0NjY1NTQ0MDAwMCJ9"`
+	Limit      int               `json:"limit" example:"20"`
+}
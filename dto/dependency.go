@@ -0,0 +1,6 @@
+package dto
+
+// AddDependencyRequest marks a task as blocked by another task.
+type AddDependencyRequest struct {
+	BlockedByID string `json:"blocked_by_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
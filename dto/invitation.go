@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+// CreateInvitationRequest represents the data required to invite a new teammate.
+type CreateInvitationRequest struct {
+	Email string `json:"email" binding:"required,email" example:"new.teammate@example.com"`
+	Role  string `json:"role,omitempty" binding:"omitempty,oneof=admin member viewer" example:"member"` // defaults to member
+}
+
+// InvitationResponse describes a pending invitation. The invite token is only ever sent by email.
+type InvitationResponse struct {
+	ID        string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Email     string    `json:"email" example:"new.teammate@example.com"`
+	Role      string    `json:"role" example:"member"`
+	InvitedBy *string   `json:"invited_by" example:"550e8400-e29b-41d4-a716-446655440000"` // null once the inviter is deleted
+	ExpiresAt time.Time `json:"expires_at" example:"2025-06-08T15:04:05Z"`
+	CreatedAt time.Time `json:"created_at" example:"2025-06-01T15:04:05Z"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+// JWK is a public signing key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty" example:"OKP"`
+	Kid string `json:"kid" example:"20250601T000000Z"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"EdDSA"`
+	N   string `json:"n,omitempty"`                                                       // RSA modulus
+	E   string `json:"e,omitempty" example:"AQAB"`                                        // RSA exponent
+	Crv string `json:"crv,omitempty" example:"Ed25519"`                                   // OKP curve
+	X   string `json:"x,omitempty" example:"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"` // OKP public key
+}
+
+// JWKSResponse is the JSON Web Key Set used to verify tokens issued by the API.
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+// CreateLabelRequest represents the data required to create a label.
+type CreateLabelRequest struct {
+	Name  string `json:"name" binding:"required" example:"backend"`
+	Color string `json:"color,omitempty" example:"#2563eb"`                                      // hex color; defaults to grey
+	Scope string `json:"scope,omitempty" binding:"omitempty,oneof=personal team" example:"team"` // defaults to personal
+}
+
+// UpdateLabelRequest renames or recolors a label. Empty fields are left unchanged.
+type UpdateLabelRequest struct {
+	Name  string `json:"name,omitempty" example:"frontend"`
+	Color string `json:"color,omitempty" example:"#16a34a"`
+}
+
+// LabelResponse describes a label. Personal labels are only visible to their owner, team labels to everyone.
+type LabelResponse struct {
+	ID        string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name      string    `json:"name" example:"backend"`
+	Color     string    `json:"color" example:"#2563eb"`
+	Scope     string    `json:"scope" example:"team"`
+	CreatedAt time.Time `json:"created_at" example:"2025-06-01T15:04:05Z"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// NotificationResponse describes a notification, e.g. that another user mentioned the user in a task
+// ("task_mention") or a comment ("comment_mention"). Actor is null once their account is deleted.
+type NotificationResponse struct {
+	ID        string              `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Kind      string              `json:"kind" example:"comment_mention"`
+	Task      TaskReference       `json:"task"`
+	CommentID *string             `json:"comment_id" example:"123e4567-e89b-12d3-a456-426614174000"` // set for comment mentions
+	Actor     *PublicUserResponse `json:"actor"`
+	ReadAt    *time.Time          `json:"read_at" example:"2025-06-01T16:20:00Z"` // null while unread
+	CreatedAt time.Time           `json:"created_at" example:"2025-06-01T15:04:05Z"`
+}
+
+// NotificationListResponse is one page of the user's notifications, newest first.
+// NextCursor is null on the last page.
+type NotificationListResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	UnreadCount   int64                  `json:"unread_count" example:"3"` // across all pages
+	NextCursor    *string                `json:"next_cursor" example:"eyJjcmVhdGVkX2F0IjoiMjAyNS0wNi0wMVQxNTowNDowNVoiLCJpZCI6IjU1MGU4NDAwLWUyOWItNDFkNC1hNzE2LTQ0NjY1NTQ0MDAwMCJ9"`
+	Limit         int                    `json:"limit" example:"20"`
+}
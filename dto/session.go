@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+// SessionResponse describes a device or browser the user is logged in on.
+type SessionResponse struct {
+	ID         string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserAgent  string    `json:"user_agent" example:"Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0"`
+	IPAddress  string    `json:"ip_address" example:"203.0.113.7"`
+	Current    bool      `json:"current" example:"true"`
+	CreatedAt  time.Time `json:"created_at" example:"2025-06-01T15:04:05Z"`
+	LastSeenAt time.Time `json:"last_seen_at" example:"2025-06-02T09:30:00Z"`
+	ExpiresAt  time.Time `json:"expires_at" example:"2025-07-02T09:30:00Z"`
+}
@@ -10,25 +10,107 @@ type CreateTaskInput struct {
 	Priority    string    `json:"priority" binding:"omitempty,oneof=low medium high" example:"high"` // default: medium
 	Status      string    `json:"status" binding:"omitempty,oneof=pending in_progress complete" example:"in_progress"` // default: pending
 	AssigneeID  string    `json:"assignee_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"` // UUID of the user assigned to the task
+	ParentID    string    `json:"parent_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"` // UUID of the parent task, to create a subtask
 }
 
 // UpdateTaskDTO represents the data transfer object for updating a task.
 // It allows partial updates to a task's fields, with each field being optional.
 type UpdateTaskDTO struct {
-	Status      string `json:"status,omitempty" binding:"omitempty,oneof=pending in_progress complete" example:"in_progress"`
-	Priority    string `json:"priority,omitempty" binding:"omitempty,oneof=low medium high" example:"high"`
-	DueDate     string `json:"due_date,omitempty" example:"2023-12-31T23:59:59Z"` // ISO string
-	Description string `json:"description,omitempty" example:"Write detailed documentation for the project including setup, usage, and API endpoints."`
-	AssigneeID  string `json:"assignee_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Status      string  `json:"status,omitempty" binding:"omitempty,oneof=pending in_progress complete" example:"in_progress"`
+	Priority    string  `json:"priority,omitempty" binding:"omitempty,oneof=low medium high" example:"high"`
+	DueDate     string  `json:"due_date,omitempty" example:"2023-12-31T23:59:59Z"` // ISO string
+	Description string  `json:"description,omitempty" example:"Write detailed documentation for the project including setup, usage, and API endpoints."`
+	AssigneeID  string  `json:"assignee_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ParentID    *string `json:"parent_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"` // "" makes the task a top-level task
+	Force       bool    `json:"force,omitempty" example:"false"` // change the status even though subtasks or blocking tasks are open
 }
 
 // TaskResponse represents the response structure for a task.
 // It includes all fields of a task, formatted for API responses.
 type TaskResponse struct {
-    ID          string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-    Title       string    `json:"title" example:"Complete project documentation"`
-    Description string    `json:"description" example:"Write detailed documentation for the project including setup, usage, and API endpoints."`
-    DueDate     time.Time `json:"due_date" example:"2025-06-01T15:04:05Z"`
-    Status      string    `json:"status" example:"pending"`
-    Priority    string    `json:"priority" example:"high"`
-}
\ No newline at end of file
+    ID          string          `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+    Title       string          `json:"title" example:"Complete project documentation"`
+    Description string          `json:"description" example:"Write detailed documentation for the project including setup, usage, and API endpoints."`
+    DueDate     time.Time       `json:"due_date" example:"2025-06-01T15:04:05Z"`
+    Status      string          `json:"status" example:"pending"`
+    Priority    string          `json:"priority" example:"high"`
+    Labels      []LabelResponse `json:"labels"` // labels visible to the requesting user
+    ParentID    *string         `json:"parent_id" example:"123e4567-e89b-12d3-a456-426614174000"` // null for top-level tasks
+    Subtasks    TaskProgress    `json:"subtasks"` // progress of the direct subtasks
+    Checklist   TaskProgress    `json:"checklist"` // progress of the checklist
+    BlockedBy   []TaskReference `json:"blocked_by"` // tasks that must be complete before this one can start
+    Blocking    []TaskReference `json:"blocking"` // tasks waiting for this one
+}
+
+// TaskReference identifies a related task.
+type TaskReference struct {
+	ID     string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Title  string `json:"title" example:"Design the database schema"`
+	Status string `json:"status" example:"in_progress"`
+}
+
+// TaskProgress counts the finished parts of a task, e.g. 3 of 5 checklist items done.
+type TaskProgress struct {
+	Done  int `json:"done" example:"3"`
+	Total int `json:"total" example:"5"`
+}
+// Values of the role filter of task listings.
+const (
+	TaskRoleCreator  = "creator"
+	TaskRoleAssignee = "assignee"
+)
+
+// Values of the label_match parameter of task listings.
+const (
+	TaskLabelMatchAny = "any"
+	TaskLabelMatchAll = "all"
+)
+
+// TaskListQuery holds the filters, sort order and page position of a task listing.
+// Empty fields do not filter.
+type TaskListQuery struct {
+	Statuses     []string   // any of these statuses
+	Priorities   []string   // any of these priorities
+	Role         string     // TaskRoleCreator or TaskRoleAssignee; empty matches both
+	AssigneeID   string     // tasks assigned to this user
+	DueAfter     *time.Time // due at or after this instant
+	DueBefore    *time.Time // due before this instant
+	CreatedAfter *time.Time // created at or after this instant
+	Overdue      *bool      // past due and not complete (or the opposite when false)
+	Labels       []string   // label IDs; tasks carrying any (or all, see LabelMatch) of them
+	LabelMatch   string     // TaskLabelMatchAny (default) or TaskLabelMatchAll
+	Search       string     // full-text search terms matched against titles and descriptions
+	Sort         string     // comma-separated fields, "-" prefix for descending; defaults to due_date
+	Cursor       string     // opaque position returned as next_cursor by the previous page; empty for the first page
+	Limit        int
+}
+
+// TaskListResponse is one page of tasks, in the requested order.
+// NextCursor is null on the last page.
+type TaskListResponse struct {
+	Tasks      []TaskResponse `json:"tasks"`
+	NextCursor *string        `json:"next_cursor" example:"eyJkdWVfZGF0ZSI6IjIwMjUtMDYtMDFUMTU6MDQ6MDVaIiwiaWQiOiI1NTBlODQwMC1lMjliLTQxZDQtYTcxNi00NDY2NTU0NDAwMDAifQ"`
+	Limit      int            `json:"limit" example:"20"`
+}
+
+// TaskSearchResult is a task matching a full-text search, with its relevance and the matching
+// parts of its title and description. Highlights are HTML-escaped, with matches wrapped in <mark> tags.
+type TaskSearchResult struct {
+	TaskResponse
+	Rank       float64        `json:"rank" example:"0.6"`
+	Highlights TaskHighlights `json:"highlights"`
+}
+
+// TaskHighlights holds the highlighted snippets of a search result.
+type TaskHighlights struct {
+	Title       string `json:"title" example:"Complete project <mark>documentation</mark>"`
+	Description string `json:"description" example:"Write detailed <mark>documentation</mark> for the project"`
+}
+
+// TaskSearchResponse is one page of search results, most relevant first unless another sort is requested.
+// NextCursor is null on the last page.
+type TaskSearchResponse struct {
+	Tasks      []TaskSearchResult `json:"tasks"`
+	NextCursor *string            `json:"next_cursor" example:"eyJzb3J0IjoiLXJhbmsiLCJ2YWx1ZXMiOlswLjZdLCJpZCI6IjU1MGU4NDAwLWUyOWItNDFkNC1hNzE2LTQ0NjY1NTQ0MDAwMCJ9"`
+	Limit      int                `json:"limit" example:"20"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// FieldChange holds the values of a task field before and after a change.
+type FieldChange struct {
+	Before interface{} `json:"before" example:"complete"` // null for created tasks
+	After  interface{} `json:"after" example:"pending"`   // null for deleted tasks
+}
+
+// TaskEventResponse describes a change to a task ("created", "updated" or "deleted") and the fields
+// it changed. Actor is null for changes made by the system and once the actor's account is deleted.
+type TaskEventResponse struct {
+	ID        string                 `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TaskID    string                 `json:"task_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Action    string                 `json:"action" example:"updated"`
+	Actor     *PublicUserResponse    `json:"actor"`
+	Changes   map[string]FieldChange `json:"changes"`
+	CreatedAt time.Time              `json:"created_at" example:"2025-06-01T15:04:05Z"`
+}
+
+// TaskHistoryResponse is one page of a task's history, newest first. NextCursor is null on the last page.
+type TaskHistoryResponse struct {
+	Events     []TaskEventResponse `json:"events"`
+	NextCursor *string             `json:"next_cursor" example:"eyJjcmVhdGVkX2F0IjoiMjAyNS0wNi0wMVQxNTowNDowNVoiLCJpZCI6IjU1MGU4NDAwLWUyOWItNDFkNC1hNzE2LTQ0NjY1NTQ0MDAwMCJ9"`
+	Limit      int                 `json:"limit" example:"20"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// CreatePersonalAccessTokenRequest represents the data required to create a personal access token.
+type CreatePersonalAccessTokenRequest struct {
+	Name      string   `json:"name" binding:"required" example:"CI pipeline"`
+	Scopes    []string `json:"scopes" binding:"required" example:"tasks:read,tasks:write"`
+	ExpiresAt string   `json:"expires_at,omitempty" example:"2026-01-01T00:00:00Z"` // ISO string, optional
+}
+
+// PersonalAccessTokenResponse describes a personal access token without its secret.
+type PersonalAccessTokenResponse struct {
+	ID         string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name       string     `json:"name" example:"CI pipeline"`
+	Prefix     string     `json:"prefix" example:"tmpat_1a2b3c4d5e6f"`
+	Scopes     []string   `json:"scopes" example:"tasks:read,tasks:write"`
+	ExpiresAt  *time.Time `json:"expires_at" example:"2026-01-01T00:00:00Z"`
+	LastUsedAt *time.Time `json:"last_used_at" example:"2025-06-01T15:04:05Z"`
+	CreatedAt  time.Time  `json:"created_at" example:"2025-06-01T15:04:05Z"`
+}
+
+// CreatedPersonalAccessTokenResponse is returned once, right after creation, and includes the token itself.
+type CreatedPersonalAccessTokenResponse struct {
+	PersonalAccessTokenResponse
+	Token string `json:"token" example:"tmpat_1a2b3c4d5e6f_3q2-7wQKx1c0bS3h1yJ6m0Zb2kq9oYt4Yw8n1Vd5c2E"`
+}
@@ -0,0 +1,60 @@
+package dto
+
+import "time"
+
+// UserResponse represents the authenticated user's profile.
+type UserResponse struct {
+	ID                  string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	FirstName           string     `json:"first_name" example:"John"`
+	LastName            string     `json:"last_name" example:"Doe"`
+	Username            *string    `json:"username" example:"jdoe"` // null until the user picks one
+	Email               string     `json:"email" example:"user@example.com"`
+	EmailVerified       bool       `json:"email_verified" example:"true"`
+	PendingEmail        *string    `json:"pending_email,omitempty" example:"new@example.com"` // awaiting confirmation
+	Role                string     `json:"role" example:"member"`
+	Timezone            string     `json:"timezone" example:"Europe/Madrid"`
+	Locale              string     `json:"locale" example:"es-AR"`
+	TwoFactorEnabled    bool       `json:"two_factor_enabled" example:"false"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" example:"2025-07-01T15:04:05Z"` // the account is deleted after this instant unless restored
+	CreatedAt           time.Time  `json:"created_at" example:"2025-06-01T15:04:05Z"`
+}
+
+// UpdateProfileRequest represents a partial update of the user's profile.
+// Omitted fields are left unchanged.
+type UpdateProfileRequest struct {
+	FirstName string `json:"first_name,omitempty" example:"John"`
+	LastName  string `json:"last_name,omitempty" example:"Doe"`
+	Username  string `json:"username,omitempty" example:"jdoe"`          // 3-30 letters, digits, dots, hyphens or underscores
+	Timezone  string `json:"timezone,omitempty" example:"Europe/Madrid"` // IANA time zone name
+	Locale    string `json:"locale,omitempty" example:"es-AR"`           // BCP 47 language tag
+}
+
+// ChangePasswordRequest represents the data required to change the password of a signed-in user.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required" example:"SecurePassword123"`
+	NewPassword     string `json:"new_password" binding:"required,min=6" example:"NewSecurePassword123"`
+}
+
+// ChangeEmailRequest represents a request to move the account to a new email address.
+// The change is applied once confirmed from the new inbox.
+type ChangeEmailRequest struct {
+	Email    string `json:"email" binding:"required,email" example:"new@example.com"`
+	Password string `json:"password" binding:"required" example:"SecurePassword123"`
+}
+
+// PublicUserResponse is the profile of a user as seen by other users, e.g. when picking an assignee.
+type PublicUserResponse struct {
+	ID        string  `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	FirstName string  `json:"first_name" example:"John"`
+	LastName  string  `json:"last_name" example:"Doe"`
+	Username  *string `json:"username" example:"jdoe"`
+	Email     string  `json:"email" example:"user@example.com"`
+}
+
+// UserListResponse is a page of the user directory.
+type UserListResponse struct {
+	Users []PublicUserResponse `json:"users"`
+	Page  int                  `json:"page" example:"1"`
+	Limit int                  `json:"limit" example:"20"`
+	Total int64                `json:"total" example:"42"`
+}
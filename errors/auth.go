@@ -1,5 +1,7 @@
 package errors
 
+import "time"
+
 // AuthError represents an authentication error with a message.
 // It implements the error interface.
 
@@ -26,3 +28,31 @@ func (e *ConflictError) Error() string {
 func NewConflictError(msg string) error {
 	return &ConflictError{Message: msg}
 }
+
+// ForbiddenError represents an authenticated request that is not allowed by policy.
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
+}
+
+func NewForbiddenError(msg string) error {
+	return &ForbiddenError{Message: msg}
+}
+
+// TooManyRequestsError represents a request rejected because of too many failed attempts.
+// RetryAfter tells the client how long to wait before trying again.
+type TooManyRequestsError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *TooManyRequestsError) Error() string {
+	return e.Message
+}
+
+func NewTooManyRequestsError(msg string, retryAfter time.Duration) error {
+	return &TooManyRequestsError{Message: msg, RetryAfter: retryAfter}
+}
@@ -14,8 +14,9 @@ func ErrNotFound(entity string) error {
 	return fmt.Errorf("%s not found", entity)
 }
 
+// ErrUnauthorizedAction is a ForbiddenError so handlers can map it to 403 by type.
 func ErrUnauthorizedAction(action string, entity string) error {
-	return fmt.Errorf("unauthorized to %s %s", action, entity)
+	return NewForbiddenError(fmt.Sprintf("unauthorized to %s %s", action, entity))
 }
 
 func ErrInvalidField(field string) error {
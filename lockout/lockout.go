@@ -0,0 +1,71 @@
+package lockout
+
+import (
+	"math"
+	"time"
+)
+
+// State is the failed-attempt record kept for a single key (an email or a client IP).
+type State struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// Store persists failed-attempt state. Implementations must be safe for concurrent use,
+// and RegisterFailure must update the state atomically.
+type Store interface {
+	// Get returns the current state for key; unknown keys return a zero State.
+	Get(key string) (State, error)
+	// RegisterFailure records a failed attempt for key using the policy and returns the new state.
+	RegisterFailure(key string, policy Policy, now time.Time) (State, error)
+	// Reset clears the state for key.
+	Reset(key string) error
+}
+
+// Policy describes how failures translate into delays.
+// The first FreeAttempts failures within Window are not delayed; each further failure doubles
+// the delay starting at BaseDelay, and reaching MaxFailures locks the key for LockoutDuration.
+type Policy struct {
+	FreeAttempts    int
+	MaxFailures     int
+	BaseDelay       time.Duration
+	LockoutDuration time.Duration
+	Window          time.Duration
+}
+
+// next applies a new failure at now to the state, following the policy.
+func (p Policy) next(s State, now time.Time) State {
+	if !s.LastFailureAt.IsZero() && now.Sub(s.LastFailureAt) > p.Window && now.After(s.LockedUntil) {
+		s.Failures = 0
+	}
+
+	s.Failures++
+	s.LastFailureAt = now
+
+	switch {
+	case s.Failures >= p.MaxFailures:
+		s.LockedUntil = now.Add(p.LockoutDuration)
+	case s.Failures > p.FreeAttempts:
+		exp := s.Failures - p.FreeAttempts - 1
+		delay := time.Duration(float64(p.BaseDelay) * math.Pow(2, float64(exp)))
+		if delay > p.LockoutDuration {
+			delay = p.LockoutDuration
+		}
+		s.LockedUntil = now.Add(delay)
+	}
+	return s
+}
+
+// LockedOut reports whether the state has just reached a full lockout under the policy.
+func (p Policy) LockedOut(s State) bool {
+	return s.Failures >= p.MaxFailures
+}
+
+// RetryAfter returns how long the caller must wait before the next attempt, or zero.
+func (s State) RetryAfter(now time.Time) time.Duration {
+	if now.Before(s.LockedUntil) {
+		return s.LockedUntil.Sub(now)
+	}
+	return 0
+}
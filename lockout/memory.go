@@ -0,0 +1,43 @@
+package lockout
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps failed-attempt state in process memory.
+// It is suitable for a single replica and for tests.
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]State
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: make(map[string]State)}
+}
+
+// Get returns the current state for key.
+func (m *MemoryStore) Get(key string) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.states[key], nil
+}
+
+// RegisterFailure records a failed attempt for key.
+func (m *MemoryStore) RegisterFailure(key string, policy Policy, now time.Time) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := policy.next(m.states[key], now)
+	m.states[key] = state
+	return state, nil
+}
+
+// Reset clears the state for key.
+func (m *MemoryStore) Reset(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.states, key)
+	return nil
+}
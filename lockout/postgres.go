@@ -0,0 +1,71 @@
+package lockout
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// loginAttempt is the row stored by PostgresStore in the login_attempts table.
+type loginAttempt struct {
+	Key           string `gorm:"primaryKey"`
+	Failures      int    `gorm:"not null"`
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+func (loginAttempt) TableName() string {
+	return "login_attempts"
+}
+
+// PostgresStore keeps failed-attempt state in Postgres so every API replica shares it.
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresStore creates a store backed by the login_attempts table.
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Get returns the current state for key.
+func (p *PostgresStore) Get(key string) (State, error) {
+	var row loginAttempt
+	err := p.db.Where("key = ?", key).Limit(1).Find(&row).Error
+	if err != nil {
+		return State{}, err
+	}
+	return State{Failures: row.Failures, LastFailureAt: row.LastFailureAt, LockedUntil: row.LockedUntil}, nil
+}
+
+// RegisterFailure records a failed attempt for key, locking the row so concurrent
+// failures from different replicas are all counted.
+func (p *PostgresStore) RegisterFailure(key string, policy Policy, now time.Time) (State, error) {
+	var state State
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&loginAttempt{Key: key}).Error; err != nil {
+			return err
+		}
+
+		var row loginAttempt
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("key = ?", key).First(&row).Error; err != nil {
+			return err
+		}
+
+		state = policy.next(State{Failures: row.Failures, LastFailureAt: row.LastFailureAt, LockedUntil: row.LockedUntil}, now)
+		return tx.Model(&loginAttempt{}).Where("key = ?", key).Updates(map[string]interface{}{
+			"failures":        state.Failures,
+			"last_failure_at": state.LastFailureAt,
+			"locked_until":    state.LockedUntil,
+		}).Error
+	})
+	return state, err
+}
+
+// Reset clears the state for key.
+func (p *PostgresStore) Reset(key string) error {
+	return p.db.Where("key = ?", key).Delete(&loginAttempt{}).Error
+}
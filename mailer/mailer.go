@@ -0,0 +1,72 @@
+package mailer
+
+import (
+	"os"
+	"sync"
+)
+
+// Message represents a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email messages.
+// Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+var (
+	mu      sync.RWMutex
+	current Mailer
+)
+
+// Default returns the process-wide mailer, building it from the environment on first use.
+// MAIL_DRIVER selects the implementation: "smtp", "file" (writes to MAIL_OUTBOX_DIR) or
+// "memory" (the default, keeps messages in memory for local development and tests).
+func Default() Mailer {
+	mu.RLock()
+	m := current
+	mu.RUnlock()
+	if m != nil {
+		return m
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if current == nil {
+		current = fromEnv()
+	}
+	return current
+}
+
+// SetDefault replaces the process-wide mailer, e.g. with an Outbox in tests.
+func SetDefault(m Mailer) {
+	mu.Lock()
+	current = m
+	mu.Unlock()
+}
+
+// fromEnv builds a mailer from the MAIL_* environment variables.
+func fromEnv() Mailer {
+	switch os.Getenv("MAIL_DRIVER") {
+	case "smtp":
+		return NewSMTPMailer(
+			os.Getenv("SMTP_HOST"),
+			os.Getenv("SMTP_PORT"),
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+			os.Getenv("MAIL_FROM"),
+		)
+	case "file":
+		dir := os.Getenv("MAIL_OUTBOX_DIR")
+		if dir == "" {
+			dir = "outbox"
+		}
+		return NewOutbox(dir)
+	default:
+		return NewOutbox("")
+	}
+}
@@ -0,0 +1,64 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outbox is a Mailer that does not deliver anything. Messages are kept in memory and,
+// when a directory is configured, also written there as .eml files for local development.
+type Outbox struct {
+	dir      string
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewOutbox creates an outbox. An empty dir keeps messages in memory only.
+func NewOutbox(dir string) *Outbox {
+	return &Outbox{dir: dir}
+}
+
+// Send records the message and writes it to the outbox directory if one is configured.
+func (o *Outbox) Send(msg Message) error {
+	o.mu.Lock()
+	o.messages = append(o.messages, msg)
+	count := len(o.messages)
+	o.mu.Unlock()
+
+	if o.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(o.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+	name := fmt.Sprintf("%d-%04d.eml", time.Now().UnixNano(), count)
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", msg.To, msg.Subject, msg.Body)
+	if err := os.WriteFile(filepath.Join(o.dir, name), []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write outbox message: %w", err)
+	}
+	return nil
+}
+
+// Messages returns a copy of every message sent through the outbox.
+func (o *Outbox) Messages() []Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Message(nil), o.messages...)
+}
+
+// LastTo returns the most recent message sent to the given address.
+func (o *Outbox) LastTo(to string) (Message, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := len(o.messages) - 1; i >= 0; i-- {
+		if strings.EqualFold(o.messages[i].To, to) {
+			return o.messages[i], true
+		}
+	}
+	return Message{}, false
+}
@@ -5,16 +5,30 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/services"
 	"github.com/kfeuerschvenger/task-manager-api/utils"
 )
 
 // Middleware for authentication
-// It checks for a valid JWT token in the Authorization header and extracts the user ID.
+// It accepts either a valid, non-revoked JWT access token or a personal access token
+// in the Authorization header and extracts the user ID.
 
 type contextKey string
 
 const UserIDKey = contextKey("userID")
 
+// ClaimsKey holds the verified *utils.Claims of the access token used for the request.
+// It is only set for JWT-authenticated requests.
+const ClaimsKey = contextKey("claims")
+
+// RoleKey holds the role (string) of the authenticated user.
+const RoleKey = contextKey("role")
+
+// ScopesKey holds the scopes ([]string) of the personal access token used for the request.
+// It is only set for requests authenticated with a personal access token.
+const ScopesKey = contextKey("scopes")
+
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
@@ -24,13 +38,102 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		userID, err := utils.VerifyJWT(tokenString)
+
+		// Personal access tokens
+		if strings.HasPrefix(tokenString, utils.PersonalAccessTokenPrefix) {
+			userID, role, scopes, err := services.AuthenticatePersonalAccessToken(tokenString)
+			if err != nil {
+				utils.Error(w, http.StatusUnauthorized, "Invalid token")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			ctx = context.WithValue(ctx, RoleKey, role)
+			ctx = context.WithValue(ctx, ScopesKey, scopes)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
+		claims, err := utils.VerifyJWT(tokenString)
 		if err != nil {
 			utils.Error(w, http.StatusUnauthorized, "Invalid token")
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), UserIDKey, userID)
+		if services.IsTokenRevoked(claims) {
+			utils.Error(w, http.StatusUnauthorized, "Token has been revoked")
+			return
+		}
+
+		// Last-seen times are buffered in memory and written in batches
+		if claims.SessionID != "" {
+			services.TouchSession(claims.SessionID)
+		}
+
+		// Tokens issued before roles were introduced carry no role claim.
+		role := claims.Role
+		if role == "" {
+			role = models.RoleMember
+		}
+
+		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+		ctx = context.WithValue(ctx, RoleKey, role)
+		ctx = context.WithValue(ctx, ClaimsKey, claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
-}
\ No newline at end of file
+}
+
+// RequireScope restricts a route to personal access tokens granted the given scope.
+// Requests authenticated with a JWT session are not scope-limited. It must run after AuthMiddleware.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if scopes, ok := r.Context().Value(ScopesKey).([]string); ok {
+				if !hasScope(scopes, scope) {
+					utils.Error(w, http.StatusForbidden, "Token is missing the required scope: "+scope)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRole restricts a route to users holding one of the given roles. It must run after AuthMiddleware.
+// The role comes from the access token, so role changes take effect once the user's tokens are renewed.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, _ := r.Context().Value(RoleKey).(string)
+			for _, allowed := range roles {
+				if role == allowed {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			utils.Error(w, http.StatusForbidden, "Insufficient role")
+		})
+	}
+}
+
+// RequireSession restricts a route to JWT sessions, rejecting personal access tokens.
+// It is used for account and credential management. It must run after AuthMiddleware.
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value(ClaimsKey).(*utils.Claims); !ok {
+			utils.Error(w, http.StatusForbidden, "Personal access tokens cannot be used for this endpoint")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// hasScope reports whether scope is in scopes.
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attachment is a file uploaded to a task. Its contents live in the blob store under StorageKey;
+// UploaderID is nil once the uploader's account is deleted.
+type Attachment struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TaskID      uuid.UUID  `gorm:"type:uuid;not null"`
+	UploaderID  *uuid.UUID `gorm:"type:uuid"`
+	FileName    string     `gorm:"not null"`
+	ContentType string     `gorm:"not null"`
+	Size        int64      `gorm:"not null"`
+	Checksum    string     `gorm:"not null"` // hex SHA-256 of the contents
+	StorageKey  string     `gorm:"not null;unique"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
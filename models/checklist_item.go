@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChecklistItem is a step of a task too small to be a subtask. The items of a task
+// are ordered by Position, which runs from 0 without gaps.
+type ChecklistItem struct {
+	ID       uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TaskID   uuid.UUID `gorm:"type:uuid;not null"`
+	Text     string    `gorm:"not null"`
+	Done     bool      `gorm:"not null;default:false"`
+	Position int       `gorm:"not null"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Comment is a message in the discussion thread of a task. Its body is Markdown, stored as written;
+// clients are responsible for rendering it safely. AuthorID is nil once the author's account is deleted.
+type Comment struct {
+	ID       uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TaskID   uuid.UUID  `gorm:"type:uuid;not null"`
+	AuthorID *uuid.UUID `gorm:"type:uuid"`
+	Body     string     `gorm:"not null"`
+
+	// EditedAt is set when the author changes the body.
+	EditedAt *time.Time
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+
+	// Filled in by the comment service; nil once the author's account is deleted.
+	Author *User `gorm:"-"`
+}
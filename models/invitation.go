@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation lets a new teammate register with a given role. The invite link carries a single-use
+// token of which only the SHA-256 hash is stored. An invitation is pending until it is accepted,
+// revoked or expires.
+type Invitation struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Email      string     `gorm:"not null"`
+	Role       string     `gorm:"not null;default:member"`
+	TokenHash  string     `gorm:"unique;not null"`
+	InvitedBy  *uuid.UUID `gorm:"type:uuid"`
+	AcceptedBy *uuid.UUID `gorm:"type:uuid"`
+	ExpiresAt  time.Time  `gorm:"not null"`
+	AcceptedAt *time.Time
+	RevokedAt  *time.Time
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Label scopes.
+const (
+	// LabelScopePersonal labels are only visible to their owner.
+	LabelScopePersonal = "personal"
+	// LabelScopeTeam labels are shared by every user.
+	LabelScopeTeam = "team"
+)
+
+// Label categorizes tasks. Personal labels have an owner; team labels have none.
+// CreatedBy is kept for team labels, which only their creator or an admin may change.
+type Label struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	OwnerID   *uuid.UUID `gorm:"type:uuid"`
+	CreatedBy *uuid.UUID `gorm:"type:uuid"`
+	Name      string     `gorm:"not null"`
+	Color     string     `gorm:"not null"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// Scope returns LabelScopePersonal or LabelScopeTeam.
+func (l Label) Scope() string {
+	if l.OwnerID != nil {
+		return LabelScopePersonal
+	}
+	return LabelScopeTeam
+}
+
+// TaskLabel attaches a label to a task.
+type TaskLabel struct {
+	TaskID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	LabelID uuid.UUID `gorm:"type:uuid;primaryKey"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
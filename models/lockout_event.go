@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LockoutEvent is an audit record written whenever a login key (an email or a client IP)
+// is locked out after too many failed attempts.
+type LockoutEvent struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Key         string    `gorm:"not null"`
+	Failures    int       `gorm:"not null"`
+	LockedUntil time.Time `gorm:"not null"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
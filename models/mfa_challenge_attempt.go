@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// MFAChallengeAttempt counts the second factors tried with a two-factor login challenge (by its jti
+// claim). Rows can be pruned once ExpiresAt has passed, since the challenge is rejected anyway.
+type MFAChallengeAttempt struct {
+	JTI       string    `gorm:"column:jti;primaryKey"`
+	Attempts  int       `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification kinds.
+const (
+	NotificationTaskMention    = "task_mention"    // mentioned in a task's description
+	NotificationCommentMention = "comment_mention" // mentioned in a comment
+)
+
+// Notification tells a user that something happened that concerns them, e.g. that another user
+// mentioned them. ActorID is nil once the acting user's account is deleted.
+type Notification struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null"`
+	ActorID   *uuid.UUID `gorm:"type:uuid"`
+	Kind      string     `gorm:"not null"`
+	TaskID    uuid.UUID  `gorm:"type:uuid;not null"`
+	CommentID *uuid.UUID `gorm:"type:uuid"`
+
+	ReadAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+
+	// Filled in by the notification service.
+	Task  Task  `gorm:"-"`
+	Actor *User `gorm:"-"`
+}
+
+// TaskMention grants a user mentioned in a task's description or comments read access to the task.
+type TaskMention struct {
+	TaskID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID uuid.UUID `gorm:"type:uuid;primaryKey"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
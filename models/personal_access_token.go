@@ -0,0 +1,39 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scopes that can be granted to personal access tokens.
+const (
+	ScopeTasksRead  = "tasks:read"
+	ScopeTasksWrite = "tasks:write"
+	ScopeUsersRead  = "users:read"
+)
+
+// AllScopes lists every scope a personal access token may request.
+var AllScopes = []string{ScopeTasksRead, ScopeTasksWrite, ScopeUsersRead}
+
+// PersonalAccessToken is a long-lived credential for scripts and CI.
+// The token is shown once; only its lookup prefix and SHA-256 hash are stored.
+// Scopes are stored space-separated.
+type PersonalAccessToken struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null"`
+	Name       string    `gorm:"not null"`
+	Prefix     string    `gorm:"unique;not null"`
+	TokenHash  string    `gorm:"not null"`
+	Scopes     string    `gorm:"not null"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// ScopeList returns the token's scopes as a slice.
+func (t PersonalAccessToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
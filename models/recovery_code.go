@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecoveryCode is a one-time code that can replace a TOTP code during login.
+// Only the SHA-256 hash of the normalized code is stored.
+type RecoveryCode struct {
+	ID       uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null"`
+	CodeHash string    `gorm:"not null"`
+	UsedAt   *time.Time
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
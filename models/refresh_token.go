@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is an opaque, database-backed credential used to obtain new access tokens.
+// Only the SHA-256 hash of the token is stored. Tokens issued from the same login share
+// a FamilyID so the whole chain can be revoked when a rotated token is replayed.
+type RefreshToken struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null"`
+	FamilyID     uuid.UUID `gorm:"type:uuid;not null"`
+	TokenHash    string    `gorm:"unique;not null"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	RevokedAt    *time.Time
+	ReplacedByID *uuid.UUID `gorm:"type:uuid"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevokedToken records an access token (by its jti claim) that must no longer be accepted.
+// Rows can be pruned once ExpiresAt has passed, since the token is rejected anyway.
+type RevokedToken struct {
+	JTI       string    `gorm:"column:jti;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is a login of a user on one device. Its ID is the FamilyID of the refresh tokens
+// issued for the login and the "sid" claim of its access tokens, so revoking a session ends both.
+type Session struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null"`
+	UserAgent  string    `gorm:"not null;default:''"`
+	IPAddress  string    `gorm:"not null;default:''"`
+	LastSeenAt time.Time `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	RevokedAt  *time.Time
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
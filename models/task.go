@@ -12,11 +12,40 @@ type Task struct {
 	Description string    `gorm:"not null"`
 	DueDate     time.Time `gorm:"not null"`
 
-	Priority   string    `gorm:"not null;default:'medium'"`
-	Status     string    `gorm:"not null;default:'pending'"`
-	CreatorID  uuid.UUID `gorm:"type:uuid;not null"`
-	AssigneeID uuid.UUID `gorm:"type:uuid;not null"`
+	Priority   string     `gorm:"not null;default:'medium'"`
+	Status     string     `gorm:"not null;default:'pending'"`
+	CreatorID  uuid.UUID  `gorm:"type:uuid;not null"`
+	AssigneeID uuid.UUID  `gorm:"type:uuid;not null"`
+	ParentID   *uuid.UUID `gorm:"type:uuid"` // set on subtasks
 
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime"`
-}
\ No newline at end of file
+
+	// Filled in by the task service: the labels visible to the requesting user, the progress
+	// of the task's direct subtasks and checklist, and the tasks blocking it or blocked by it.
+	// Related tasks the user cannot read are left out.
+	Labels            []Label      `gorm:"-"`
+	SubtaskProgress   TaskProgress `gorm:"-"`
+	ChecklistProgress TaskProgress `gorm:"-"`
+	BlockedBy         []Task       `gorm:"-"`
+	Blocking          []Task       `gorm:"-"`
+}
+
+// TaskProgress counts the finished parts of a task: complete subtasks or checked checklist items.
+type TaskProgress struct {
+	Done  int
+	Total int
+}
+
+// Task statuses, in workflow order.
+const (
+	TaskStatusPending    = "pending"
+	TaskStatusInProgress = "in_progress"
+	TaskStatusComplete   = "complete"
+)
+
+// TaskStatuses lists the task statuses in workflow order.
+var TaskStatuses = []string{TaskStatusPending, TaskStatusInProgress, TaskStatusComplete}
+
+// TaskPriorities lists the task priorities from lowest to highest.
+var TaskPriorities = []string{"low", "medium", "high"}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskDependency records that a task is blocked by another task: it cannot start or be
+// completed while the blocking task is open. Dependencies never form cycles.
+type TaskDependency struct {
+	TaskID      uuid.UUID `gorm:"type:uuid;primaryKey"`
+	BlockedByID uuid.UUID `gorm:"type:uuid;primaryKey"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
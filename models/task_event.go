@@ -0,0 +1,65 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Task event actions.
+const (
+	TaskEventCreated = "created"
+	TaskEventUpdated = "updated"
+	TaskEventDeleted = "deleted"
+)
+
+// TaskEvent records a change to a task. Events are immutable and are kept after the task is deleted.
+// ActorID is nil for changes made by the system, e.g. tasks handed over when an account is purged.
+type TaskEvent struct {
+	ID        uuid.UUID   `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TaskID    uuid.UUID   `gorm:"type:uuid;not null"`
+	ActorID   *uuid.UUID  `gorm:"type:uuid"`
+	Action    string      `gorm:"not null"`
+	Changes   TaskChanges `gorm:"type:jsonb;not null"`
+	CreatedAt time.Time   `gorm:"autoCreateTime"`
+
+	// Filled in by the task event service; nil once the actor's account is deleted.
+	Actor *User `gorm:"-"`
+}
+
+// FieldChange holds the values of a task field before and after a change. Before is nil for
+// created tasks and After for deleted ones.
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// TaskChanges maps task fields to their changes and is stored as JSON.
+type TaskChanges map[string]FieldChange
+
+// Value implements driver.Valuer.
+func (c TaskChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(c)
+	return string(data), err
+}
+
+// Scan implements sql.Scanner.
+func (c *TaskChanges) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	case nil:
+		*c = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into TaskChanges", value)
+	}
+}
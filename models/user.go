@@ -7,6 +7,17 @@ import (
 	"gorm.io/gorm"
 )
 
+// User roles. Admins can manage users and any task, members work on their own tasks
+// and viewers have read-only access.
+const (
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+	RoleViewer = "viewer"
+)
+
+// Roles lists every valid user role.
+var Roles = []string{RoleAdmin, RoleMember, RoleViewer}
+
 type User struct {
 	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
 	FirstName string    `gorm:"not null"`
@@ -14,6 +25,37 @@ type User struct {
 	Email     string    `gorm:"unique;not null"`
 	Password  string    `gorm:"not null"`
 
+	// Username is the optional handle other users can @mention, stored in lowercase.
+	Username *string
+
+	Role string `gorm:"not null;default:member"`
+
+	// DisabledAt is set when an admin disables the account; disabled users cannot authenticate.
+	DisabledAt *time.Time
+
+	// DeletionScheduledAt is set when the user asks to delete the account; it is purged once this
+	// instant passes unless the user restores it first.
+	DeletionScheduledAt *time.Time
+
+	// Display preferences: an IANA time zone name and a BCP 47 language tag.
+	Timezone string `gorm:"not null;default:UTC"`
+	Locale   string `gorm:"not null;default:en"`
+
+	// EmailVerifiedAt is set once the user confirms the address through the verification email.
+	EmailVerifiedAt *time.Time
+
+	// PendingEmail is the new address requested by the user, applied once confirmed from that inbox.
+	PendingEmail *string
+
+	// TOTP two-factor authentication. TOTPSecret is set during setup and
+	// TOTPEnabledAt once the user confirms it with a valid code.
+	TOTPSecret    *string    `gorm:"column:totp_secret"`
+	TOTPEnabledAt *time.Time `gorm:"column:totp_enabled_at"`
+	TOTPLastStep  *int64     `gorm:"column:totp_last_step"`
+
+	// TokensInvalidBefore revokes every access token issued before this instant (logout everywhere).
+	TokensInvalidBefore *time.Time
+
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime"`
 }
@@ -24,4 +66,4 @@ func (user *User) BeforeCreate(tx *gorm.DB) (err error) {
 		user.ID = uuid.New()
 	}
 	return
-}
\ No newline at end of file
+}
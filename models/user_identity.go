@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity links a user to an account at an external OpenID Connect provider.
+// Subject is the provider's stable user ID ("sub" claim); Email is the address it asserted at link time.
+type UserIdentity struct {
+	ID       uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null"`
+	Provider string    `gorm:"not null"`
+	Subject  string    `gorm:"not null"`
+	Email    string    `gorm:"not null"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// OIDCLoginState is a pending OpenID Connect login, keyed by the SHA-256 hash of the state parameter.
+// It keeps the PKCE code verifier and the nonce server-side until the provider redirects back.
+type OIDCLoginState struct {
+	StateHash    string    `gorm:"primaryKey"`
+	Provider     string    `gorm:"not null"`
+	CodeVerifier string    `gorm:"not null"`
+	Nonce        string    `gorm:"not null"`
+	ExpiresAt    time.Time `gorm:"not null"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,59 @@
+package oidc
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// ErrUnknownProvider is returned for provider names that are not configured.
+var ErrUnknownProvider = errors.New("unknown OIDC provider")
+
+// Config describes an OpenID Connect provider registered with the API.
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// ConfigFromEnv returns the configuration of the named provider.
+// Providers are listed in OIDC_PROVIDERS (comma-separated) and configured with
+// OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID, OIDC_<NAME>_CLIENT_SECRET and the optional
+// OIDC_<NAME>_SCOPES (space-separated, default "openid email profile").
+// The redirect URL is <appURL>/auth/oidc/<name>/callback.
+func ConfigFromEnv(name string, appURL string) (Config, error) {
+	if !enabled(name) {
+		return Config{}, ErrUnknownProvider
+	}
+
+	prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+	cfg := Config{
+		Name:         name,
+		Issuer:       strings.TrimSuffix(os.Getenv(prefix+"ISSUER"), "/"),
+		ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+		ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+		RedirectURL:  strings.TrimSuffix(appURL, "/") + "/auth/oidc/" + name + "/callback",
+		Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	if cfg.Issuer == "" || cfg.ClientID == "" {
+		return Config{}, errors.New("OIDC provider " + name + " is missing an issuer or client ID")
+	}
+	return cfg, nil
+}
+
+// enabled reports whether name is listed in OIDC_PROVIDERS.
+func enabled(name string) bool {
+	for _, p := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		if strings.TrimSpace(p) == name && name != "" {
+			return true
+		}
+	}
+	return false
+}
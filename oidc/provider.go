@@ -0,0 +1,200 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// discoveryTTL is how long a provider's discovery document is cached.
+const discoveryTTL = time.Hour
+
+// httpClient is used for every request to providers.
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// metadata is the subset of the OpenID Provider discovery document used by the API.
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider performs the authorization code flow with PKCE against an OpenID Provider.
+type Provider struct {
+	config   Config
+	metadata metadata
+	keys     *keySet
+}
+
+// discovered caches discovery documents and key sets per issuer.
+var discovered = struct {
+	sync.Mutex
+	entries map[string]*discoveryEntry
+}{entries: map[string]*discoveryEntry{}}
+
+type discoveryEntry struct {
+	metadata  metadata
+	keys      *keySet
+	fetchedAt time.Time
+}
+
+// NewProvider returns a provider for cfg, fetching its discovery document if it is not cached.
+func NewProvider(ctx context.Context, cfg Config) (*Provider, error) {
+	discovered.Lock()
+	defer discovered.Unlock()
+
+	entry, ok := discovered.entries[cfg.Issuer]
+	if !ok || time.Since(entry.fetchedAt) > discoveryTTL {
+		var md metadata
+		if err := getJSON(ctx, httpClient, cfg.Issuer+"/.well-known/openid-configuration", &md); err != nil {
+			return nil, fmt.Errorf("discovering %s: %w", cfg.Issuer, err)
+		}
+		if md.Issuer != cfg.Issuer {
+			return nil, fmt.Errorf("discovery document issuer %q does not match %q", md.Issuer, cfg.Issuer)
+		}
+		if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+			return nil, errors.New("incomplete discovery document")
+		}
+
+		entry = &discoveryEntry{
+			metadata:  md,
+			keys:      &keySet{uri: md.JWKSURI, client: httpClient},
+			fetchedAt: time.Now(),
+		}
+		discovered.entries[cfg.Issuer] = entry
+	}
+
+	return &Provider{config: cfg, metadata: entry.metadata, keys: entry.keys}, nil
+}
+
+// AuthCodeURL returns the URL to send the user to. The state and nonce bind the callback to this
+// login attempt and the code verifier (kept server-side) proves the token request comes from us.
+func (p *Provider) AuthCodeURL(state, nonce, codeVerifier string) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.config.ClientID)
+	params.Set("redirect_uri", p.config.RedirectURL)
+	params.Set("scope", strings.Join(p.config.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", S256Challenge(codeVerifier))
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(p.metadata.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.metadata.AuthorizationEndpoint + sep + params.Encode()
+}
+
+// Identity is the verified identity asserted by an ID token.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+	Name          string
+}
+
+// idTokenClaims are the ID token claims read by the API.
+type idTokenClaims struct {
+	Nonce         string      `json:"nonce"`
+	Email         string      `json:"email"`
+	EmailVerified interface{} `json:"email_verified"` // some providers send "true" as a string
+	GivenName     string      `json:"given_name"`
+	FamilyName    string      `json:"family_name"`
+	Name          string      `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// Exchange redeems an authorization code and returns the identity from the verified ID token.
+// The token's nonce must match the one sent with the authorization request.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("client_id", p.config.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if p.config.ClientSecret != "" {
+		form.Set("client_secret", p.config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil || tokens.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	return p.verifyIDToken(ctx, tokens.IDToken, nonce)
+}
+
+// verifyIDToken checks the ID token signature, issuer, audience, expiry and nonce.
+func (p *Provider) verifyIDToken(ctx context.Context, raw string, nonce string) (*Identity, error) {
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.keys.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+		jwt.WithIssuer(p.metadata.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return nil, errors.New("invalid ID token: nonce mismatch")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("invalid ID token: missing subject")
+	}
+
+	verified := false
+	switch v := claims.EmailVerified.(type) {
+	case bool:
+		verified = v
+	case string:
+		verified = v == "true"
+	}
+
+	return &Identity{
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(strings.TrimSpace(claims.Email)),
+		EmailVerified: verified,
+		GivenName:     claims.GivenName,
+		FamilyName:    claims.FamilyName,
+		Name:          claims.Name,
+	}, nil
+}
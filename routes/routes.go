@@ -1,10 +1,13 @@
 package routes
 
 import (
+	"net/http"
+
 	"github.com/gorilla/mux"
 	"github.com/kfeuerschvenger/task-manager-api/controllers"
 	_ "github.com/kfeuerschvenger/task-manager-api/docs"
 	"github.com/kfeuerschvenger/task-manager-api/middleware"
+	"github.com/kfeuerschvenger/task-manager-api/models"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
 
@@ -14,8 +17,18 @@ func SetupRoutes() *mux.Router {
 
 	// Public routes
 	router.HandleFunc("/ping", controllers.Ping).Methods("GET")
+	router.HandleFunc("/.well-known/jwks.json", controllers.JWKS).Methods("GET")
 	router.HandleFunc("/auth/register", controllers.Register).Methods("POST")
 	router.HandleFunc("/auth/login", controllers.Login).Methods("POST")
+	router.HandleFunc("/auth/refresh", controllers.Refresh).Methods("POST")
+	router.HandleFunc("/auth/password/forgot", controllers.ForgotPassword).Methods("POST")
+	router.HandleFunc("/auth/password/reset", controllers.ResetPassword).Methods("POST")
+	router.HandleFunc("/auth/verify", controllers.VerifyEmail).Methods("GET")
+	router.HandleFunc("/auth/2fa/verify", controllers.VerifyTwoFactorLogin).Methods("POST")
+	router.HandleFunc("/auth/email/confirm", controllers.ConfirmEmailChange).Methods("GET")
+	router.HandleFunc("/auth/invitation", controllers.GetInvitation).Methods("GET")
+	router.HandleFunc("/auth/oidc/{provider}/login", controllers.OIDCLogin).Methods("GET")
+	router.HandleFunc("/auth/oidc/{provider}/callback", controllers.OIDCCallback).Methods("GET")
 	
 	// Swagger documentation route
 	router.PathPrefix("/documentation/").Handler(httpSwagger.Handler(
@@ -24,14 +37,97 @@ func SetupRoutes() *mux.Router {
     httpSwagger.DefaultModelsExpandDepth(-1),
 	))
 
-	// Protected routes
+	// Session routes: account and credential management, JWT sessions only
+	session := func(h http.HandlerFunc) http.Handler {
+		return middleware.AuthMiddleware(middleware.RequireSession(h))
+	}
+	router.Handle("/auth/logout", session(controllers.Logout)).Methods("POST")
+	router.Handle("/auth/logout-all", session(controllers.LogoutAll)).Methods("POST")
+	router.Handle("/auth/verify/resend", session(controllers.ResendVerification)).Methods("POST")
+	router.Handle("/auth/2fa/setup", session(controllers.SetupTwoFactor)).Methods("POST")
+	router.Handle("/auth/2fa/confirm", session(controllers.ConfirmTwoFactor)).Methods("POST")
+	router.Handle("/auth/2fa", session(controllers.DisableTwoFactor)).Methods("DELETE")
+	router.Handle("/me", middleware.AuthMiddleware(http.HandlerFunc(controllers.GetMe))).Methods("GET")
+	router.Handle("/me", session(controllers.UpdateMe)).Methods("PATCH")
+	router.Handle("/me", session(controllers.DeleteMe)).Methods("DELETE")
+	router.Handle("/me/restore", session(controllers.RestoreMe)).Methods("POST")
+	router.Handle("/me/export", session(controllers.ExportMe)).Methods("GET")
+	router.Handle("/me/password", session(controllers.ChangePassword)).Methods("POST")
+	router.Handle("/me/email", session(controllers.ChangeEmail)).Methods("POST")
+	router.Handle("/me/tokens", session(controllers.CreatePersonalAccessToken)).Methods("POST")
+	router.Handle("/me/tokens", session(controllers.ListPersonalAccessTokens)).Methods("GET")
+	router.Handle("/me/tokens/{id}", session(controllers.DeletePersonalAccessToken)).Methods("DELETE")
+	router.Handle("/me/sessions", session(controllers.ListSessions)).Methods("GET")
+	router.Handle("/me/sessions/{id}", session(controllers.RevokeSession)).Methods("DELETE")
+
+	// Invitations: admins and members can invite teammates
+	inviter := func(h http.HandlerFunc) http.Handler {
+		return middleware.AuthMiddleware(middleware.RequireSession(middleware.RequireRole(models.RoleAdmin, models.RoleMember)(h)))
+	}
+	router.Handle("/invitations", inviter(controllers.CreateInvitation)).Methods("POST")
+	router.Handle("/invitations", inviter(controllers.ListInvitations)).Methods("GET")
+	router.Handle("/invitations/{id}", inviter(controllers.RevokeInvitation)).Methods("DELETE")
+
+	// Protected routes: JWT sessions or personal access tokens with the required scope
+	scoped := func(scope string, h http.HandlerFunc) http.Handler {
+		return middleware.RequireScope(scope)(h)
+	}
+	router.Handle("/users", middleware.AuthMiddleware(scoped(models.ScopeUsersRead, controllers.ListUsers))).Methods("GET")
+	router.Handle("/users/{id}", middleware.AuthMiddleware(scoped(models.ScopeUsersRead, controllers.GetUser))).Methods("GET")
+	router.Handle("/me/notifications", middleware.AuthMiddleware(scoped(models.ScopeTasksRead, controllers.ListNotifications))).Methods("GET")
+	router.Handle("/me/notifications/read-all", middleware.AuthMiddleware(scoped(models.ScopeTasksWrite, controllers.MarkAllNotificationsRead))).Methods("POST")
+	router.Handle("/me/notifications/{id}/read", middleware.AuthMiddleware(scoped(models.ScopeTasksWrite, controllers.MarkNotificationRead))).Methods("POST")
+
+	// Viewers have read-only access to tasks
+	writer := func(h http.HandlerFunc) http.Handler {
+		return middleware.RequireRole(models.RoleAdmin, models.RoleMember)(scoped(models.ScopeTasksWrite, h))
+	}
 	protected := router.PathPrefix("/tasks").Subrouter()
 	protected.Use(middleware.AuthMiddleware)
-	protected.HandleFunc("", controllers.GetTasks).Methods("GET")
-	protected.HandleFunc("", controllers.CreateTask).Methods("POST")
-	protected.HandleFunc("/{id}", controllers.GetTaskByID).Methods("GET")
-	protected.HandleFunc("/{id}", controllers.UpdateTask).Methods("PUT")
-	protected.HandleFunc("/{id}", controllers.DeleteTask).Methods("DELETE")
+	protected.Handle("", scoped(models.ScopeTasksRead, controllers.GetTasks)).Methods("GET")
+	protected.Handle("", writer(controllers.CreateTask)).Methods("POST")
+	protected.Handle("/search", scoped(models.ScopeTasksRead, controllers.SearchTasks)).Methods("GET")
+	protected.Handle("/{id}", scoped(models.ScopeTasksRead, controllers.GetTaskByID)).Methods("GET")
+	protected.Handle("/{id}", writer(controllers.UpdateTask)).Methods("PUT")
+	protected.Handle("/{id}", writer(controllers.DeleteTask)).Methods("DELETE")
+	protected.Handle("/{id}/history", scoped(models.ScopeTasksRead, controllers.GetTaskHistory)).Methods("GET")
+	protected.Handle("/{id}/labels/{labelId}", writer(controllers.AttachTaskLabel)).Methods("PUT")
+	protected.Handle("/{id}/labels/{labelId}", writer(controllers.DetachTaskLabel)).Methods("DELETE")
+	protected.Handle("/{id}/subtasks", scoped(models.ScopeTasksRead, controllers.ListSubtasks)).Methods("GET")
+	protected.Handle("/{id}/checklist", scoped(models.ScopeTasksRead, controllers.ListChecklist)).Methods("GET")
+	protected.Handle("/{id}/checklist", writer(controllers.AddChecklistItem)).Methods("POST")
+	protected.Handle("/{id}/checklist/{itemId}", writer(controllers.UpdateChecklistItem)).Methods("PUT")
+	protected.Handle("/{id}/checklist/{itemId}", writer(controllers.DeleteChecklistItem)).Methods("DELETE")
+	protected.Handle("/{id}/dependencies", writer(controllers.AddTaskDependency)).Methods("POST")
+	protected.Handle("/{id}/dependencies/{blockedById}", writer(controllers.RemoveTaskDependency)).Methods("DELETE")
+	protected.Handle("/{id}/comments", scoped(models.ScopeTasksRead, controllers.ListComments)).Methods("GET")
+	protected.Handle("/{id}/comments", writer(controllers.CreateComment)).Methods("POST")
+	protected.Handle("/{id}/attachments", scoped(models.ScopeTasksRead, controllers.ListAttachments)).Methods("GET")
+	protected.Handle("/{id}/attachments", writer(controllers.UploadAttachment)).Methods("POST")
+	protected.Handle("/{id}/attachments/{attachmentId}", scoped(models.ScopeTasksRead, controllers.DownloadAttachment)).Methods("GET")
+	protected.Handle("/{id}/attachments/{attachmentId}", writer(controllers.DeleteAttachment)).Methods("DELETE")
+
+	labels := router.PathPrefix("/labels").Subrouter()
+	labels.Use(middleware.AuthMiddleware)
+	labels.Handle("", scoped(models.ScopeTasksRead, controllers.ListLabels)).Methods("GET")
+	labels.Handle("", writer(controllers.CreateLabel)).Methods("POST")
+	labels.Handle("/{id}", writer(controllers.UpdateLabel)).Methods("PUT")
+	labels.Handle("/{id}", writer(controllers.DeleteLabel)).Methods("DELETE")
+
+	comments := router.PathPrefix("/comments").Subrouter()
+	comments.Use(middleware.AuthMiddleware)
+	comments.Handle("/{id}", writer(controllers.UpdateComment)).Methods("PATCH")
+	comments.Handle("/{id}", writer(controllers.DeleteComment)).Methods("DELETE")
+
+	// Admin routes: JWT sessions of admins only
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.AuthMiddleware, middleware.RequireSession, middleware.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/users", controllers.AdminListUsers).Methods("GET")
+	admin.HandleFunc("/users/{id}/role", controllers.AdminUpdateUserRole).Methods("PUT")
+	admin.HandleFunc("/users/{id}/disable", controllers.AdminDisableUser).Methods("POST")
+	admin.HandleFunc("/users/{id}/enable", controllers.AdminEnableUser).Methods("POST")
+	admin.HandleFunc("/users/{id}/password-reset", controllers.AdminForcePasswordReset).Methods("POST")
+	admin.HandleFunc("/users/{id}/tasks", controllers.AdminListUserTasks).Methods("GET")
 
 	return router
 }
\ No newline at end of file
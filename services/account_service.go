@@ -0,0 +1,399 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/mailer"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// accountDeletionGracePeriod returns how long a deleted account can still be restored (ACCOUNT_DELETION_GRACE_PERIOD).
+func accountDeletionGracePeriod() time.Duration {
+	return utils.DurationFromEnv("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour)
+}
+
+// ScheduleAccountDeletion re-authenticates the user and schedules their account for deletion
+// at the end of the grace period. Every session and personal access token is revoked; logging in again and calling
+// CancelAccountDeletion restores the account. Requesting deletion again keeps the original date.
+func ScheduleAccountDeletion(userID string, password string) (dto.AccountDeletionResponse, error) {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return dto.AccountDeletionResponse{}, errors.ErrNotFound("user")
+	}
+
+	if !utils.CheckPasswordHash(password, user.Password) {
+		return dto.AccountDeletionResponse{}, errors.NewAuthError("invalid credentials")
+	}
+
+	if user.DeletionScheduledAt != nil {
+		return dto.AccountDeletionResponse{DeletionScheduledAt: *user.DeletionScheduledAt}, nil
+	}
+
+	// Someone must remain able to manage the deployment
+	if user.Role == models.RoleAdmin {
+		var admins int64
+		if err := database.DB.Model(&models.User{}).
+			Where("role = ? AND id <> ? AND disabled_at IS NULL AND deletion_scheduled_at IS NULL", models.RoleAdmin, user.ID).
+			Count(&admins).Error; err != nil {
+			return dto.AccountDeletionResponse{}, errors.NewInternalServerError("database error")
+		}
+		if admins == 0 {
+			return dto.AccountDeletionResponse{}, errors.NewConflictError("the last admin cannot delete their account")
+		}
+	}
+
+	scheduledAt := time.Now().Add(accountDeletionGracePeriod()).UTC()
+	if err := database.DB.Model(&user).Update("deletion_scheduled_at", scheduledAt).Error; err != nil {
+		return dto.AccountDeletionResponse{}, errors.NewInternalServerError("error scheduling account deletion")
+	}
+
+	if err := revokeUserCredentials(userID); err != nil {
+		return dto.AccountDeletionResponse{}, err
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Your account will be deleted",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nYour account and personal data will be permanently deleted on %s.\n\n"+
+				"Changed your mind? Log in and restore your account before then. If you did not request this, "+
+				"log in, restore your account and change your password.\n",
+			user.FirstName, scheduledAt.Format(time.RFC1123),
+		),
+	}
+	if err := mailer.Default().Send(msg); err != nil {
+		log.Printf("Failed to send account deletion email: %v", err)
+	}
+
+	return dto.AccountDeletionResponse{DeletionScheduledAt: scheduledAt}, nil
+}
+
+// CancelAccountDeletion restores an account scheduled for deletion.
+func CancelAccountDeletion(userID string) (dto.UserResponse, error) {
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND deletion_scheduled_at IS NOT NULL", userID).
+		Update("deletion_scheduled_at", nil)
+	if result.Error != nil {
+		return dto.UserResponse{}, errors.NewInternalServerError("error restoring account")
+	}
+	if result.RowsAffected == 0 {
+		return dto.UserResponse{}, errors.NewValidationError("account deletion is not scheduled")
+	}
+
+	return GetProfile(userID)
+}
+
+// PurgeDeletedAccounts permanently deletes the accounts whose grace period ended before now
+// and returns how many were deleted. Each account is deleted in its own transaction.
+func PurgeDeletedAccounts(now time.Time) (int, error) {
+	var users []models.User
+	if err := database.DB.Where("deletion_scheduled_at <= ?", now).Find(&users).Error; err != nil {
+		return 0, errors.NewInternalServerError("database error")
+	}
+
+	purged := 0
+	for _, user := range users {
+		deleted := false
+		var keys []string
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			// The user may have restored the account in the meantime
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ? AND deletion_scheduled_at <= ?", user.ID, now).First(&user).Error
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			} else if err != nil {
+				return errors.NewInternalServerError("database error")
+			}
+
+			deleted = true
+			keys, err = deleteAccount(tx, user)
+			return err
+		})
+		if err != nil {
+			return purged, err
+		}
+
+		if deleted {
+			deleteBlobs(keys...)
+			resetLoginFailures(user.Email)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// deleteAccount removes a user and their personal data. Tasks shared with other users are kept:
+//   - tasks the user created for someone else are handed over to their assignee;
+//   - tasks assigned to the user by someone else go back to their creator;
+//   - tasks the user created for themselves are deleted.
+//
+// These changes are recorded in the tasks' histories without an actor.
+// Credentials, sessions and linked identities are removed by their ON DELETE CASCADE constraints.
+// The storage keys of the deleted tasks' attachments are returned, for their blobs to be deleted
+// once the transaction commits.
+func deleteAccount(tx *gorm.DB, user models.User) ([]string, error) {
+	keys, err := taskAttachmentKeys(tx, "creator_id = ? AND assignee_id = ?", user.ID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordTaskHandover(tx, user.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Where("creator_id = ? AND assignee_id = ?", user.ID, user.ID).Delete(&models.Task{}).Error; err != nil {
+		return nil, errors.NewInternalServerError("error deleting tasks")
+	}
+	if err := tx.Model(&models.Task{}).Where("creator_id = ?", user.ID).
+		Update("creator_id", gorm.Expr("assignee_id")).Error; err != nil {
+		return nil, errors.NewInternalServerError("error reassigning tasks")
+	}
+	if err := tx.Model(&models.Task{}).Where("assignee_id = ?", user.ID).
+		Update("assignee_id", gorm.Expr("creator_id")).Error; err != nil {
+		return nil, errors.NewInternalServerError("error reassigning tasks")
+	}
+
+	// Rows that only reference the user by email address
+	if err := tx.Where("email = ?", user.Email).Delete(&models.Invitation{}).Error; err != nil {
+		return nil, errors.NewInternalServerError("error deleting invitations")
+	}
+	if err := tx.Where("key = ?", "email:"+user.Email).Delete(&models.LockoutEvent{}).Error; err != nil {
+		return nil, errors.NewInternalServerError("error deleting login history")
+	}
+
+	if err := tx.Delete(&user).Error; err != nil {
+		return nil, errors.NewInternalServerError("error deleting user")
+	}
+	return keys, nil
+}
+
+// recordTaskHandover records the changes deleteAccount makes to the user's tasks in their histories,
+// including the subtasks of deleted tasks losing their parent.
+func recordTaskHandover(tx *gorm.DB, userID uuid.UUID) error {
+	var tasks []models.Task
+	if err := tx.Where("creator_id = ? OR assignee_id = ?", userID, userID).Find(&tasks).Error; err != nil {
+		return errors.NewInternalServerError("error retrieving tasks")
+	}
+
+	for i := range tasks {
+		before := tasks[i]
+		if before.CreatorID == userID && before.AssigneeID == userID {
+			if err := recordSubtasksDetached(tx, nil, before.ID); err != nil {
+				return err
+			}
+			if err := recordTaskEvent(tx, nil, &before, nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		after := before
+		if after.CreatorID == userID {
+			after.CreatorID = after.AssigneeID
+		} else {
+			after.AssigneeID = after.CreatorID
+		}
+		if err := recordTaskEvent(tx, nil, &before, &after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportAccount collects the user's personal data: their profile, the tasks they created or are
+// assigned to, their labels and comments, the changes they made to tasks, their notifications, and their
+// sessions, tokens, linked identities and sent invitations.
+func ExportAccount(userID string) (dto.AccountExport, error) {
+	profile, err := GetProfile(userID)
+	if err != nil {
+		return dto.AccountExport{}, err
+	}
+
+	export := dto.AccountExport{
+		ExportedAt:           time.Now().UTC(),
+		Profile:              profile,
+		Tasks:                []dto.ExportedTask{},
+		Sessions:             []dto.ExportedSession{},
+		PersonalAccessTokens: []dto.PersonalAccessTokenResponse{},
+		Identities:           []dto.ExportedIdentity{},
+		InvitationsSent:      []dto.InvitationResponse{},
+	}
+
+	var tasks []models.Task
+	if err := database.DB.Where("creator_id = ? OR assignee_id = ?", userID, userID).
+		Order("created_at").Find(&tasks).Error; err != nil {
+		return dto.AccountExport{}, errors.NewInternalServerError("error exporting tasks")
+	}
+	taskPointers := make([]*models.Task, 0, len(tasks))
+	for i := range tasks {
+		taskPointers = append(taskPointers, &tasks[i])
+	}
+	if err := loadTaskDetails(userID, taskPointers...); err != nil {
+		return dto.AccountExport{}, errors.NewInternalServerError("error exporting tasks")
+	}
+	for _, t := range tasks {
+		export.Tasks = append(export.Tasks, dto.ExportedTask{
+			TaskResponse: dto.TaskResponse{
+				ID:          t.ID.String(),
+				Title:       t.Title,
+				Description: t.Description,
+				DueDate:     t.DueDate,
+				Status:      t.Status,
+				Priority:    t.Priority,
+				Labels:      exportLabels(t.Labels),
+				ParentID:    exportOptionalID(t.ParentID),
+				Subtasks:    dto.TaskProgress{Done: t.SubtaskProgress.Done, Total: t.SubtaskProgress.Total},
+				Checklist:   dto.TaskProgress{Done: t.ChecklistProgress.Done, Total: t.ChecklistProgress.Total},
+				BlockedBy:   exportTaskReferences(t.BlockedBy),
+				Blocking:    exportTaskReferences(t.Blocking),
+			},
+			CreatorID:  t.CreatorID.String(),
+			AssigneeID: t.AssigneeID.String(),
+			CreatedAt:  t.CreatedAt,
+			UpdatedAt:  t.UpdatedAt,
+		})
+	}
+
+	var labels []models.Label
+	if err := database.DB.Where("owner_id = ? OR created_by = ?", userID, userID).Order("created_at").Find(&labels).Error; err != nil {
+		return dto.AccountExport{}, errors.NewInternalServerError("error exporting labels")
+	}
+	export.Labels = exportLabels(labels)
+
+	var comments []models.Comment
+	if err := database.DB.Where("author_id = ?", userID).Order("created_at, id").Find(&comments).Error; err != nil {
+		return dto.AccountExport{}, errors.NewInternalServerError("error exporting comments")
+	}
+	export.Comments = make([]dto.CommentResponse, 0, len(comments))
+	for _, c := range comments {
+		export.Comments = append(export.Comments, dto.CommentResponse{
+			ID:        c.ID.String(),
+			TaskID:    c.TaskID.String(),
+			Author:    dto.CommentAuthor{ID: &userID, FirstName: profile.FirstName, LastName: profile.LastName},
+			Body:      c.Body,
+			CreatedAt: c.CreatedAt,
+			EditedAt:  c.EditedAt,
+		})
+	}
+
+	var events []models.TaskEvent
+	if err := database.DB.Where("actor_id = ?", userID).Order("created_at, id").Find(&events).Error; err != nil {
+		return dto.AccountExport{}, errors.NewInternalServerError("error exporting activity")
+	}
+	export.Activity = make([]dto.ExportedTaskEvent, 0, len(events))
+	for _, e := range events {
+		event := dto.ExportedTaskEvent{
+			TaskID:    e.TaskID.String(),
+			Action:    e.Action,
+			Changes:   make(map[string]dto.FieldChange, len(e.Changes)),
+			CreatedAt: e.CreatedAt,
+		}
+		for field, change := range e.Changes {
+			event.Changes[field] = dto.FieldChange{Before: change.Before, After: change.After}
+		}
+		export.Activity = append(export.Activity, event)
+	}
+
+	var notifications []models.Notification
+	if err := database.DB.Where("user_id = ?", userID).Order("created_at, id").Find(&notifications).Error; err != nil {
+		return dto.AccountExport{}, errors.NewInternalServerError("error exporting notifications")
+	}
+	export.Notifications = make([]dto.ExportedNotification, 0, len(notifications))
+	for _, n := range notifications {
+		export.Notifications = append(export.Notifications, dto.ExportedNotification{
+			Kind:      n.Kind,
+			TaskID:    n.TaskID.String(),
+			CommentID: exportOptionalID(n.CommentID),
+			ActorID:   exportOptionalID(n.ActorID),
+			ReadAt:    n.ReadAt,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+
+	var sessions []models.Session
+	if err := database.DB.Where("user_id = ?", userID).Order("created_at").Find(&sessions).Error; err != nil {
+		return dto.AccountExport{}, errors.NewInternalServerError("error exporting sessions")
+	}
+	for _, s := range sessions {
+		export.Sessions = append(export.Sessions, dto.ExportedSession{
+			UserAgent:  s.UserAgent,
+			IPAddress:  s.IPAddress,
+			StartedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+			RevokedAt:  s.RevokedAt,
+		})
+	}
+
+	var tokens []models.PersonalAccessToken
+	if err := database.DB.Where("user_id = ?", userID).Order("created_at").Find(&tokens).Error; err != nil {
+		return dto.AccountExport{}, errors.NewInternalServerError("error exporting tokens")
+	}
+	for _, t := range tokens {
+		export.PersonalAccessTokens = append(export.PersonalAccessTokens, toPersonalAccessTokenResponse(t))
+	}
+
+	var identities []models.UserIdentity
+	if err := database.DB.Where("user_id = ?", userID).Order("created_at").Find(&identities).Error; err != nil {
+		return dto.AccountExport{}, errors.NewInternalServerError("error exporting identities")
+	}
+	for _, identity := range identities {
+		export.Identities = append(export.Identities, dto.ExportedIdentity{
+			Provider:  identity.Provider,
+			Subject:   identity.Subject,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+
+	var invitations []models.Invitation
+	if err := database.DB.Where("invited_by = ?", userID).Order("created_at").Find(&invitations).Error; err != nil {
+		return dto.AccountExport{}, errors.NewInternalServerError("error exporting invitations")
+	}
+	for _, invitation := range invitations {
+		export.InvitationsSent = append(export.InvitationsSent, toInvitationResponse(invitation))
+	}
+
+	return export, nil
+}
+
+// exportLabels maps labels to their response DTOs for the account export.
+func exportLabels(labels []models.Label) []dto.LabelResponse {
+	resp := make([]dto.LabelResponse, 0, len(labels))
+	for _, l := range labels {
+		resp = append(resp, dto.LabelResponse{
+			ID:        l.ID.String(),
+			Name:      l.Name,
+			Color:     l.Color,
+			Scope:     l.Scope(),
+			CreatedAt: l.CreatedAt,
+		})
+	}
+	return resp
+}
+
+// exportOptionalID returns an optional ID as a string, or nil when it is not set,
+// e.g. the parent of a top-level task.
+func exportOptionalID(optional *uuid.UUID) *string {
+	if optional == nil {
+		return nil
+	}
+	id := optional.String()
+	return &id
+}
+
+// exportTaskReferences maps the tasks related to an exported task to their references.
+func exportTaskReferences(tasks []models.Task) []dto.TaskReference {
+	refs := make([]dto.TaskReference, 0, len(tasks))
+	for _, t := range tasks {
+		refs = append(refs, dto.TaskReference{ID: t.ID.String(), Title: t.Title, Status: t.Status})
+	}
+	return refs
+}
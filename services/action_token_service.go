@@ -0,0 +1,62 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// createActionToken invalidates any pending token of the same purpose for the user
+// and stores a new one. It returns the plain token to be emailed.
+func createActionToken(tx *gorm.DB, userID uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	err := tx.Model(&models.ActionToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+	if err != nil {
+		return "", errors.NewInternalServerError("error invalidating previous tokens")
+	}
+
+	plain, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", errors.NewInternalServerError("error generating token")
+	}
+
+	token := models.ActionToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := tx.Create(&token).Error; err != nil {
+		return "", errors.NewInternalServerError("error storing token")
+	}
+
+	return plain, nil
+}
+
+// consumeActionToken validates a plain token for the given purpose and marks it as used.
+// It must run inside a transaction so the token cannot be redeemed twice concurrently.
+func consumeActionToken(tx *gorm.DB, purpose string, plain string) (*models.ActionToken, error) {
+	var token models.ActionToken
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ? AND purpose = ?", utils.HashToken(plain), purpose).
+		First(&token).Error; err != nil {
+		return nil, errors.NewValidationError("invalid or expired token")
+	}
+
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, errors.NewValidationError("invalid or expired token")
+	}
+
+	if err := tx.Model(&token).Update("used_at", time.Now()).Error; err != nil {
+		return nil, errors.NewInternalServerError("error consuming token")
+	}
+
+	return &token, nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+)
+
+// ListUsersForAdmin returns a page of every user account, including disabled ones.
+func ListUsersForAdmin(query string, page int, limit int) (dto.AdminUserListResponse, error) {
+	users, total, err := searchUsers(userSearchQuery(query), page, limit)
+	if err != nil {
+		return dto.AdminUserListResponse{}, err
+	}
+
+	resp := dto.AdminUserListResponse{
+		Users: make([]dto.AdminUserResponse, 0, len(users)),
+		Page:  page,
+		Limit: limit,
+		Total: total,
+	}
+	for _, u := range users {
+		resp.Users = append(resp.Users, toAdminUserResponse(u))
+	}
+	return resp, nil
+}
+
+// SetUserRole changes a user's role. Their current access tokens are revoked so the new
+// role takes effect on the next refresh. Admins cannot change their own role.
+func SetUserRole(actorID string, userID string, role string) (dto.AdminUserResponse, error) {
+	if actorID == userID {
+		return dto.AdminUserResponse{}, errors.NewValidationError("you cannot change your own role")
+	}
+
+	user, err := findUserForAdmin(userID)
+	if err != nil {
+		return dto.AdminUserResponse{}, err
+	}
+
+	if err := database.DB.Model(&user).Update("role", role).Error; err != nil {
+		return dto.AdminUserResponse{}, errors.NewInternalServerError("error updating role")
+	}
+
+	if err := revokeUserAccessTokens(userID); err != nil {
+		return dto.AdminUserResponse{}, err
+	}
+
+	user.Role = role
+	return toAdminUserResponse(user), nil
+}
+
+// SetUserRoleByEmail changes the role of the user with the given email. It is used to bootstrap
+// the first admin from the command line.
+func SetUserRoleByEmail(email string, role string) error {
+	var user models.User
+	if err := database.DB.Where("email = ?", utils.CleanEmail(email)).First(&user).Error; err != nil {
+		return errors.ErrNotFound("user")
+	}
+
+	if err := database.DB.Model(&user).Update("role", role).Error; err != nil {
+		return errors.NewInternalServerError("error updating role")
+	}
+	return revokeUserAccessTokens(user.ID.String())
+}
+
+// SetUserDisabled disables or re-enables a user account. Disabling logs the user out everywhere
+// and blocks logins and personal access tokens. Admins cannot disable themselves.
+func SetUserDisabled(actorID string, userID string, disabled bool) (dto.AdminUserResponse, error) {
+	if disabled && actorID == userID {
+		return dto.AdminUserResponse{}, errors.NewValidationError("you cannot disable your own account")
+	}
+
+	user, err := findUserForAdmin(userID)
+	if err != nil {
+		return dto.AdminUserResponse{}, err
+	}
+
+	var disabledAt *time.Time
+	if disabled {
+		now := time.Now()
+		disabledAt = &now
+	}
+
+	if err := database.DB.Model(&user).Update("disabled_at", disabledAt).Error; err != nil {
+		return dto.AdminUserResponse{}, errors.NewInternalServerError("error updating user")
+	}
+
+	if disabled {
+		if err := RevokeAllUserTokens(userID); err != nil {
+			return dto.AdminUserResponse{}, err
+		}
+	}
+
+	user.DisabledAt = disabledAt
+	return toAdminUserResponse(user), nil
+}
+
+// ForcePasswordReset invalidates the user's password, sessions and personal access tokens and emails them a reset link.
+// The user cannot log in again until they choose a new password. Admins cannot reset their own
+// password this way; they change it like any other user.
+func ForcePasswordReset(actorID string, userID string) error {
+	if actorID == userID {
+		return errors.NewValidationError("you cannot force a password reset of your own account")
+	}
+
+	user, err := findUserForAdmin(userID)
+	if err != nil {
+		return err
+	}
+
+	// Replace the password with the hash of a random value nobody knows.
+	random, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return errors.NewInternalServerError("error generating password")
+	}
+	hashedPassword, err := utils.HashPassword(random)
+	if err != nil {
+		return errors.NewInternalServerError("error hashing password")
+	}
+
+	if err := database.DB.Model(&user).Update("password", hashedPassword).Error; err != nil {
+		return errors.NewInternalServerError("error updating password")
+	}
+
+	if err := revokeUserCredentials(userID); err != nil {
+		return err
+	}
+
+	return sendPasswordReset(user)
+}
+
+// ListUserTasksForAdmin returns one page of the tasks created by or assigned to the user, like GetTasks.
+func ListUserTasksForAdmin(userID string, q dto.TaskListQuery) ([]models.Task, string, error) {
+	if _, err := findUserForAdmin(userID); err != nil {
+		return nil, "", err
+	}
+	return GetTasks(userID, q)
+}
+
+// findUserForAdmin loads a user by ID for an admin action.
+func findUserForAdmin(userID string) (models.User, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return models.User{}, errors.ErrNotFound("user")
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return models.User{}, errors.ErrNotFound("user")
+	}
+	return user, nil
+}
+
+// toAdminUserResponse maps a user to its administrative representation.
+func toAdminUserResponse(user models.User) dto.AdminUserResponse {
+	return dto.AdminUserResponse{
+		ID:               user.ID.String(),
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Email:            user.Email,
+		Role:             user.Role,
+		EmailVerified:    user.EmailVerifiedAt != nil,
+		TwoFactorEnabled: user.TOTPEnabledAt != nil,
+		DisabledAt:       user.DisabledAt,
+		CreatedAt:        user.CreatedAt,
+	}
+}
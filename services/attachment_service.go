@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/storage"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"gorm.io/gorm"
+)
+
+// defaultAttachmentTypes are the content types accepted when ATTACHMENT_ALLOWED_TYPES is not set.
+// Office documents are detected as application/zip, and Markdown, CSV and JSON as text/plain.
+const defaultAttachmentTypes = "image/png,image/jpeg,image/gif,image/webp,application/pdf,text/plain,application/zip"
+
+// AttachmentMaxSize returns the largest accepted upload, in bytes (ATTACHMENT_MAX_SIZE, default 10 MiB).
+func AttachmentMaxSize() int64 {
+	return int64(utils.IntFromEnv("ATTACHMENT_MAX_SIZE", 10<<20))
+}
+
+// allowedAttachmentTypes returns the accepted content types (ATTACHMENT_ALLOWED_TYPES, comma-separated).
+func allowedAttachmentTypes() map[string]bool {
+	types := os.Getenv("ATTACHMENT_ALLOWED_TYPES")
+	if types == "" {
+		types = defaultAttachmentTypes
+	}
+
+	allowed := map[string]bool{}
+	for _, t := range strings.Split(types, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			allowed[t] = true
+		}
+	}
+	return allowed
+}
+
+// ListAttachments returns the attachments of a task the user can read, oldest first.
+func ListAttachments(taskID string, userID string, role string) ([]models.Attachment, error) {
+	task, err := findReadableTask(database.DB, taskID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	var attachments []models.Attachment
+	if err := database.DB.Where("task_id = ?", task.ID).Order("created_at, id").Find(&attachments).Error; err != nil {
+		return nil, errors.NewInternalServerError("error retrieving attachments")
+	}
+	return attachments, nil
+}
+
+// UploadAttachment stores a file of size bytes read from file and attaches it to a task visible to the user.
+// The content type is detected from the contents and must be one of the allowed types.
+func UploadAttachment(ctx context.Context, taskID string, userID string, role string, fileName string, file io.ReadSeeker, size int64) (models.Attachment, error) {
+	task, err := findVisibleTask(database.DB, taskID, userID, role)
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	uploaderID, err := uuid.Parse(userID)
+	if err != nil {
+		return models.Attachment{}, errors.ErrInvalidID("user")
+	}
+	if size > AttachmentMaxSize() {
+		return models.Attachment{}, errors.NewValidationError(fmt.Sprintf("file is too large (at most %d bytes)", AttachmentMaxSize()))
+	}
+
+	contentType, err := detectContentType(file)
+	if err != nil {
+		return models.Attachment{}, err
+	}
+
+	attachment := models.Attachment{
+		ID:          uuid.New(),
+		TaskID:      task.ID,
+		UploaderID:  &uploaderID,
+		FileName:    sanitizeFileName(fileName),
+		ContentType: contentType,
+		Size:        size,
+	}
+	attachment.StorageKey = fmt.Sprintf("tasks/%s/%s", task.ID, attachment.ID)
+
+	hash := sha256.New()
+	if err := storage.Default().Put(ctx, attachment.StorageKey, io.TeeReader(file, hash), size, contentType); err != nil {
+		log.Printf("Failed to store attachment %s: %v", attachment.StorageKey, err)
+		return models.Attachment{}, errors.NewInternalServerError("error storing attachment")
+	}
+	attachment.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	if err := database.DB.Create(&attachment).Error; err != nil {
+		deleteBlobs(attachment.StorageKey)
+		return models.Attachment{}, errors.NewInternalServerError("error creating attachment")
+	}
+	return attachment, nil
+}
+
+// OpenAttachment returns an attachment of a task the user can read, with a seekable reader
+// streaming its contents. The caller must close the reader.
+func OpenAttachment(ctx context.Context, taskID string, attachmentID string, userID string, role string) (models.Attachment, io.ReadSeekCloser, error) {
+	task, err := findReadableTask(database.DB, taskID, userID, role)
+	if err != nil {
+		return models.Attachment{}, nil, err
+	}
+	attachment, err := findAttachment(task.ID, attachmentID)
+	if err != nil {
+		return models.Attachment{}, nil, err
+	}
+
+	return attachment, storage.NewReadSeeker(ctx, storage.Default(), attachment.StorageKey, attachment.Size), nil
+}
+
+// DeleteAttachment removes an attachment from a task visible to the user. Only its uploader,
+// the task's creator or an admin may delete it.
+func DeleteAttachment(taskID string, attachmentID string, userID string, role string) error {
+	task, err := findVisibleTask(database.DB, taskID, userID, role)
+	if err != nil {
+		return err
+	}
+	attachment, err := findAttachment(task.ID, attachmentID)
+	if err != nil {
+		return err
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return errors.ErrInvalidID("user")
+	}
+	isUploader := attachment.UploaderID != nil && *attachment.UploaderID == userUUID
+	if !isUploader && !canManageTask(*task, userUUID, role) {
+		return errors.ErrUnauthorizedAction("delete", "attachment")
+	}
+
+	if err := database.DB.Delete(&attachment).Error; err != nil {
+		return errors.NewInternalServerError("error deleting attachment")
+	}
+	deleteBlobs(attachment.StorageKey)
+	return nil
+}
+
+// findAttachment loads an attachment of the task.
+func findAttachment(taskID uuid.UUID, attachmentID string) (models.Attachment, error) {
+	if _, err := uuid.Parse(attachmentID); err != nil {
+		return models.Attachment{}, errors.ErrInvalidID("attachment")
+	}
+
+	var attachment models.Attachment
+	if err := database.DB.Where("id = ? AND task_id = ?", attachmentID, taskID).First(&attachment).Error; err != nil {
+		return models.Attachment{}, errors.ErrNotFound("attachment")
+	}
+	return attachment, nil
+}
+
+// taskAttachmentKeys returns the storage keys of the attachments of the tasks matching the condition,
+// so that their blobs can be deleted along with the tasks.
+func taskAttachmentKeys(tx *gorm.DB, condition string, args ...interface{}) ([]string, error) {
+	tasks := tx.Session(&gorm.Session{NewDB: true}).Model(&models.Task{}).Select("id").Where(condition, args...)
+
+	var keys []string
+	if err := tx.Model(&models.Attachment{}).Where("task_id IN (?)", tasks).Pluck("storage_key", &keys).Error; err != nil {
+		return nil, errors.NewInternalServerError("error retrieving attachments")
+	}
+	return keys, nil
+}
+
+// deleteBlobs removes the contents of deleted attachments. Failures only leave unreferenced blobs
+// behind, so they are logged rather than reported.
+func deleteBlobs(keys ...string) {
+	for _, key := range keys {
+		if err := storage.Default().Delete(context.Background(), key); err != nil {
+			log.Printf("Failed to delete attachment blob %s: %v", key, err)
+		}
+	}
+}
+
+// detectContentType sniffs the content type of the file from its first bytes and checks that it
+// is allowed. The file is rewound afterwards.
+func detectContentType(file io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", errors.NewInternalServerError("error reading upload")
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", errors.NewInternalServerError("error reading upload")
+	}
+
+	contentType := http.DetectContentType(head[:n])
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !allowedAttachmentTypes()[mediaType] {
+		return "", errors.NewValidationError("unsupported file type: " + mediaType)
+	}
+	return contentType, nil
+}
+
+// sanitizeFileName keeps the base name of an uploaded file, without control characters and at
+// most 255 characters long.
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[:255])
+	}
+	return name
+}
@@ -1,8 +1,11 @@
 package services
 
 import (
+	"log"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/kfeuerschvenger/task-manager-api/database"
 	"github.com/kfeuerschvenger/task-manager-api/dto"
 	"github.com/kfeuerschvenger/task-manager-api/errors"
@@ -11,20 +14,27 @@ import (
 	"gorm.io/gorm"
 )
 
-func RegisterUser(req dto.RegisterRequest) (string, error) {
+// RegisterUser creates an account and returns a token pair.
+// With an invite token, the account gets the invitation's role and its email counts as verified;
+// without one, a verification email is sent. Invite-only deployments require an invite token.
+func RegisterUser(req dto.RegisterRequest, client utils.ClientInfo) (dto.AuthResponse, error) {
 	email := strings.ToLower(strings.TrimSpace(req.Email))
 
+	if req.InviteToken == "" && registrationMode() == RegistrationInviteOnly {
+		return dto.AuthResponse{}, errors.NewForbiddenError("registration requires an invitation")
+	}
+
 	var existingUser models.User
 	result := database.DB.Where("email = ?", email).First(&existingUser)
 	if result.Error == nil {
-		return "", errors.NewConflictError("email already registered")
+		return dto.AuthResponse{}, errors.NewConflictError("email already registered")
 	} else if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
-		return "", errors.NewInternalServerError("database error")
+		return dto.AuthResponse{}, errors.NewInternalServerError("database error")
 	}
 
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
-		return "", errors.NewInternalServerError("error hashing password")
+		return dto.AuthResponse{}, errors.NewInternalServerError("error hashing password")
 	}
 
 	user := models.User{
@@ -34,35 +44,102 @@ func RegisterUser(req dto.RegisterRequest) (string, error) {
 		Password:  hashedPassword,
 	}
 
-	if err := database.DB.Create(&user).Error; err != nil {
-		return "", errors.NewInternalServerError("error creating user")
-	}
+	var tokens dto.AuthResponse
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var invitation models.Invitation
+		if req.InviteToken != "" {
+			var err error
+			if invitation, err = lockInvitation(tx, req.InviteToken); err != nil {
+				return err
+			}
+			if invitation.Email != email {
+				return errors.NewValidationError("email does not match the invitation")
+			}
+
+			// The invite link was delivered to this address, which proves ownership
+			now := time.Now()
+			user.Role = invitation.Role
+			user.EmailVerifiedAt = &now
+		}
+
+		if err := tx.Create(&user).Error; err != nil {
+			return errors.NewInternalServerError("error creating user")
+		}
 
-	token, err := utils.GenerateJWT(user.ID.String())
+		if req.InviteToken != "" {
+			if err := acceptInvitation(tx, invitation, user.ID); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		tokens, _, err = issueTokens(tx, user, uuid.Nil, client)
+		return err
+	})
 	if err != nil {
-		return "", errors.NewInternalServerError("error generating token")
+		return dto.AuthResponse{}, err
+	}
+
+	// The account exists by now, so a failure to send the link must not fail the registration;
+	// the user can ask for a new one.
+	if user.EmailVerifiedAt == nil {
+		if err := sendVerificationEmail(user); err != nil {
+			log.Printf("Failed to send verification email: %v", err)
+		}
 	}
 
-	return token, nil
+	return tokens, nil
 }
 
-func AuthenticateUser(req dto.LoginRequest) (string, error) {
+// AuthenticateUser checks the user's credentials and returns a token pair.
+// When two-factor authentication is enabled, a challenge is returned instead and the
+// token pair is only issued by CompleteTwoFactorLogin.
+// Failed attempts, including logins to disabled accounts, are throttled per email and per client IP.
+func AuthenticateUser(req dto.LoginRequest, client utils.ClientInfo) (dto.AuthResponse, *dto.MFAChallengeResponse, error) {
 	email := strings.ToLower(strings.TrimSpace(req.Email))
 
+	if err := checkLoginAllowed(email, client.IP); err != nil {
+		return dto.AuthResponse{}, nil, err
+	}
+
 	var user models.User
 	result := database.DB.Where("email = ?", email).First(&user)
 	if result.Error != nil {
-		return "", errors.NewAuthError("invalid credentials")
+		registerLoginFailure(email, client.IP)
+		return dto.AuthResponse{}, nil, errors.NewAuthError("invalid credentials")
+	}
+
+	// Disabled accounts fail like a wrong password, so logins cannot be used to confirm their passwords
+	if !utils.CheckPasswordHash(req.Password, user.Password) || user.DisabledAt != nil {
+		registerLoginFailure(email, client.IP)
+		return dto.AuthResponse{}, nil, errors.NewAuthError("invalid credentials")
 	}
 
-	if !utils.CheckPasswordHash(req.Password, user.Password) {
-		return "", errors.NewAuthError("invalid credentials")
+	if user.TOTPEnabledAt != nil {
+		// The failure count is only reset once the second factor is verified.
+		challenge, err := twoFactorChallenge(user)
+		return dto.AuthResponse{}, challenge, err
 	}
 
-	token, err := utils.GenerateJWT(user.ID.String())
+	resetLoginFailures(email)
+
+	tokens, _, err := issueTokens(database.DB, user, uuid.Nil, client)
 	if err != nil {
-		return "", errors.NewInternalServerError("error generating token")
+		return dto.AuthResponse{}, nil, err
 	}
 
-	return token, nil
-}
\ No newline at end of file
+	return tokens, nil, nil
+}
+
+// twoFactorChallenge returns the challenge a user with 2FA enabled must complete through CompleteTwoFactorLogin.
+func twoFactorChallenge(user models.User) (*dto.MFAChallengeResponse, error) {
+	challenge, expiresAt, err := utils.GenerateChallengeJWT(user.ID.String())
+	if err != nil {
+		return nil, errors.NewInternalServerError("error generating token")
+	}
+	return &dto.MFAChallengeResponse{
+		MFARequired:    true,
+		ChallengeToken: challenge,
+		ExpiresAt:      expiresAt.UTC().Format(time.RFC3339),
+	}, nil
+}
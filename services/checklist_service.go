@@ -0,0 +1,178 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxChecklistItems caps the number of items in a task's checklist.
+const maxChecklistItems = 100
+
+// ListChecklist returns the checklist of a task the user can read, in order.
+func ListChecklist(taskID string, userID string, role string) ([]models.ChecklistItem, error) {
+	task, err := findReadableTask(database.DB, taskID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []models.ChecklistItem
+	if err := database.DB.Where("task_id = ?", task.ID).Order("position").Find(&items).Error; err != nil {
+		return nil, errors.NewInternalServerError("error retrieving checklist")
+	}
+	return items, nil
+}
+
+// AddChecklistItem adds an item to the checklist of a task visible to the user, at the requested
+// position (the end by default); the items after it shift down.
+func AddChecklistItem(taskID string, userID string, role string, req dto.CreateChecklistItemRequest) (models.ChecklistItem, error) {
+	var item models.ChecklistItem
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		task, err := lockChecklist(tx, taskID, userID, role)
+		if err != nil {
+			return err
+		}
+
+		count, err := countChecklistItems(tx, task.ID)
+		if err != nil {
+			return err
+		}
+		if count >= maxChecklistItems {
+			return errors.NewValidationError("a checklist can have at most 100 items")
+		}
+
+		position := count
+		if req.Position != nil && *req.Position < count {
+			position = *req.Position
+		}
+		if err := tx.Model(&models.ChecklistItem{}).Where("task_id = ? AND position >= ?", task.ID, position).
+			Update("position", gorm.Expr("position + 1")).Error; err != nil {
+			return errors.NewInternalServerError("error updating checklist")
+		}
+
+		item = models.ChecklistItem{
+			ID:        uuid.New(),
+			TaskID:    task.ID,
+			Text:      strings.TrimSpace(req.Text),
+			Position:  position,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return errors.NewInternalServerError("error updating checklist")
+		}
+		return nil
+	})
+	return item, err
+}
+
+// UpdateChecklistItem edits, checks or unchecks, or moves an item of a task's checklist.
+// Moving an item shifts the items between its old and new positions.
+func UpdateChecklistItem(taskID string, itemID string, userID string, role string, req dto.UpdateChecklistItemRequest) (models.ChecklistItem, error) {
+	var item models.ChecklistItem
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		task, err := lockChecklist(tx, taskID, userID, role)
+		if err != nil {
+			return err
+		}
+		if item, err = findChecklistItem(tx, task.ID, itemID); err != nil {
+			return err
+		}
+
+		if req.Text != "" {
+			item.Text = strings.TrimSpace(req.Text)
+		}
+		if req.Done != nil {
+			item.Done = *req.Done
+		}
+		if req.Position != nil {
+			count, err := countChecklistItems(tx, task.ID)
+			if err != nil {
+				return err
+			}
+			position := *req.Position
+			if position > count-1 {
+				position = count - 1
+			}
+
+			// Close the gap at the old position and open one at the new position
+			shift := tx.Model(&models.ChecklistItem{}).Where("task_id = ? AND id <> ?", task.ID, item.ID)
+			if position < item.Position {
+				shift = shift.Where("position >= ? AND position < ?", position, item.Position).
+					Update("position", gorm.Expr("position + 1"))
+			} else if position > item.Position {
+				shift = shift.Where("position > ? AND position <= ?", item.Position, position).
+					Update("position", gorm.Expr("position - 1"))
+			}
+			if shift.Error != nil {
+				return errors.NewInternalServerError("error updating checklist")
+			}
+			item.Position = position
+		}
+
+		item.UpdatedAt = time.Now()
+		if err := tx.Save(&item).Error; err != nil {
+			return errors.NewInternalServerError("error updating checklist")
+		}
+		return nil
+	})
+	return item, err
+}
+
+// DeleteChecklistItem removes an item from a task's checklist; the items after it shift up.
+func DeleteChecklistItem(taskID string, itemID string, userID string, role string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		task, err := lockChecklist(tx, taskID, userID, role)
+		if err != nil {
+			return err
+		}
+		item, err := findChecklistItem(tx, task.ID, itemID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&item).Error; err != nil {
+			return errors.NewInternalServerError("error updating checklist")
+		}
+		if err := tx.Model(&models.ChecklistItem{}).Where("task_id = ? AND position > ?", task.ID, item.Position).
+			Update("position", gorm.Expr("position - 1")).Error; err != nil {
+			return errors.NewInternalServerError("error updating checklist")
+		}
+		return nil
+	})
+}
+
+// lockChecklist loads a task visible to the user and locks it, so that concurrent changes
+// of its checklist keep the positions without gaps or duplicates.
+func lockChecklist(tx *gorm.DB, taskID string, userID string, role string) (*models.Task, error) {
+	return findVisibleTask(tx.Clauses(clause.Locking{Strength: "UPDATE"}), taskID, userID, role)
+}
+
+// findChecklistItem loads an item of the task's checklist.
+func findChecklistItem(tx *gorm.DB, taskID uuid.UUID, itemID string) (models.ChecklistItem, error) {
+	if _, err := uuid.Parse(itemID); err != nil {
+		return models.ChecklistItem{}, errors.ErrInvalidID("checklist item")
+	}
+
+	var item models.ChecklistItem
+	if err := tx.Where("id = ? AND task_id = ?", itemID, taskID).First(&item).Error; err != nil {
+		return models.ChecklistItem{}, errors.ErrNotFound("checklist item")
+	}
+	return item, nil
+}
+
+// countChecklistItems returns the number of items in the task's checklist.
+func countChecklistItems(tx *gorm.DB, taskID uuid.UUID) (int, error) {
+	var count int64
+	if err := tx.Model(&models.ChecklistItem{}).Where("task_id = ?", taskID).Count(&count).Error; err != nil {
+		return 0, errors.NewInternalServerError("error retrieving checklist")
+	}
+	return int(count), nil
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"gorm.io/gorm"
+)
+
+// commentCursor is the position of the last comment of a page.
+type commentCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// ListComments returns one page of the comments of a task the user can read, oldest first,
+// together with the cursor of the next page (empty on the last page).
+func ListComments(taskID string, userID string, role string, cursor string, limit int) ([]models.Comment, string, error) {
+	task, err := findReadableTask(database.DB, taskID, userID, role)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := database.DB.Where("task_id = ?", task.ID)
+	if cursor != "" {
+		var after commentCursor
+		if err := utils.DecodeCursor(cursor, &after); err != nil {
+			return nil, "", err
+		}
+		query = query.Where("(created_at, id) > (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	var comments []models.Comment
+	if err := query.Order("created_at, id").Limit(limit + 1).Find(&comments).Error; err != nil {
+		return nil, "", errors.NewInternalServerError("error retrieving comments")
+	}
+	more := len(comments) > limit
+	if more {
+		comments = comments[:limit]
+	}
+
+	if err := loadCommentAuthors(comments); err != nil {
+		return nil, "", err
+	}
+	if !more {
+		return comments, "", nil
+	}
+
+	last := comments[len(comments)-1]
+	next, err := utils.EncodeCursor(commentCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	if err != nil {
+		return nil, "", errors.NewInternalServerError("error encoding cursor")
+	}
+	return comments, next, nil
+}
+
+// CreateComment adds a comment to a task visible to the user and notifies the users it mentions.
+func CreateComment(taskID string, userID string, role string, req dto.CommentRequest) (models.Comment, error) {
+	task, err := findVisibleTask(database.DB, taskID, userID, role)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	authorID, err := uuid.Parse(userID)
+	if err != nil {
+		return models.Comment{}, errors.ErrInvalidID("user")
+	}
+
+	comment := models.Comment{
+		ID:        uuid.New(),
+		TaskID:    task.ID,
+		AuthorID:  &authorID,
+		Body:      req.Body,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return errors.NewInternalServerError("error creating comment")
+		}
+		return notifyMentions(tx, authorID, models.NotificationCommentMention, task.ID, &comment.ID, comment.Body, "")
+	})
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	comments := []models.Comment{comment}
+	if err := loadCommentAuthors(comments); err != nil {
+		return models.Comment{}, err
+	}
+	return comments[0], nil
+}
+
+// UpdateComment replaces the body of a comment and notifies the users newly mentioned in it. Only its
+// author may edit it, and only while they can still see the task.
+func UpdateComment(commentID string, userID string, role string, req dto.CommentRequest) (models.Comment, error) {
+	comment, err := findAuthoredComment(commentID, userID, role, "edit")
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	if comment.Body != req.Body {
+		now, previous := time.Now(), comment.Body
+		comment.Body = req.Body
+		comment.EditedAt = &now
+		comment.UpdatedAt = now
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Save(&comment).Error; err != nil {
+				return errors.NewInternalServerError("error updating comment")
+			}
+			return notifyMentions(tx, *comment.AuthorID, models.NotificationCommentMention, comment.TaskID, &comment.ID, comment.Body, previous)
+		})
+		if err != nil {
+			return models.Comment{}, err
+		}
+	}
+
+	comments := []models.Comment{comment}
+	if err := loadCommentAuthors(comments); err != nil {
+		return models.Comment{}, err
+	}
+	return comments[0], nil
+}
+
+// DeleteComment deletes a comment. Only its author may delete it, and only while they can still see the task.
+func DeleteComment(commentID string, userID string, role string) error {
+	comment, err := findAuthoredComment(commentID, userID, role, "delete")
+	if err != nil {
+		return err
+	}
+
+	if err := database.DB.Delete(&comment).Error; err != nil {
+		return errors.NewInternalServerError("error deleting comment")
+	}
+	return nil
+}
+
+// findAuthoredComment loads a comment on a task visible to the user, and checks that the user wrote it.
+// Comments on tasks the user cannot see are reported as not found.
+func findAuthoredComment(commentID string, userID string, role string, action string) (models.Comment, error) {
+	if _, err := uuid.Parse(commentID); err != nil {
+		return models.Comment{}, errors.ErrInvalidID("comment")
+	}
+
+	var comment models.Comment
+	if err := database.DB.First(&comment, "id = ?", commentID).Error; err != nil {
+		return models.Comment{}, errors.ErrNotFound("comment")
+	}
+	if _, err := findVisibleTask(database.DB, comment.TaskID.String(), userID, role); err != nil {
+		return models.Comment{}, errors.ErrNotFound("comment")
+	}
+	if comment.AuthorID == nil || comment.AuthorID.String() != userID {
+		return models.Comment{}, errors.ErrUnauthorizedAction(action, "comment")
+	}
+	return comment, nil
+}
+
+// loadCommentAuthors fills in the authors of the comments.
+func loadCommentAuthors(comments []models.Comment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(comments))
+	for _, c := range comments {
+		if c.AuthorID != nil {
+			ids = append(ids, *c.AuthorID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var users []models.User
+	if err := database.DB.Select("id, first_name, last_name").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return errors.NewInternalServerError("error retrieving comment authors")
+	}
+	byID := make(map[uuid.UUID]models.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	for i := range comments {
+		if c := &comments[i]; c.AuthorID != nil {
+			if author, ok := byID[*c.AuthorID]; ok {
+				c.Author = &author
+			}
+		}
+	}
+	return nil
+}
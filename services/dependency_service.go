@@ -0,0 +1,177 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// taskDependencyLock is the advisory lock serializing new dependencies, so that two concurrent
+// inserts cannot together create a cycle.
+const taskDependencyLock = 7316521
+
+// AddDependency marks the task as blocked by the task with ID blockedByID and returns the updated task.
+// Both tasks must be visible to the user, and the dependency must not create a cycle. Adding an
+// existing dependency has no effect.
+func AddDependency(taskID string, blockedByID string, userID string, role string) (*models.Task, error) {
+	var task *models.Task
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if task, err = findVisibleTask(tx, taskID, userID, role); err != nil {
+			return err
+		}
+
+		if _, err := uuid.Parse(blockedByID); err != nil {
+			return errors.NewValidationError("invalid blocking task ID")
+		}
+		blocker, err := findVisibleTask(tx, blockedByID, userID, role)
+		if err != nil {
+			return errors.NewValidationError("blocking task not found")
+		}
+		if blocker.ID == task.ID {
+			return errors.NewValidationError("a task cannot be blocked by itself")
+		}
+
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", taskDependencyLock).Error; err != nil {
+			return errors.NewInternalServerError("database error")
+		}
+		blocked, err := isBlockedBy(tx, blocker.ID, task.ID)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return errors.NewValidationError("the blocking task already waits for this task; the dependency would create a cycle")
+		}
+
+		dependency := models.TaskDependency{TaskID: task.ID, BlockedByID: blocker.ID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dependency).Error; err != nil {
+			return errors.NewInternalServerError("error adding dependency")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := loadTaskDetails(userID, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// RemoveDependency removes the dependency of the task on the task with ID blockedByID.
+// The task must be visible to the user.
+func RemoveDependency(taskID string, blockedByID string, userID string, role string) error {
+	task, err := findVisibleTask(database.DB, taskID, userID, role)
+	if err != nil {
+		return err
+	}
+	if _, err := uuid.Parse(blockedByID); err != nil {
+		return errors.ErrInvalidID("blocking task")
+	}
+
+	result := database.DB.Where("task_id = ? AND blocked_by_id = ?", task.ID, blockedByID).Delete(&models.TaskDependency{})
+	if result.Error != nil {
+		return errors.NewInternalServerError("error removing dependency")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound("dependency")
+	}
+	return nil
+}
+
+// isBlockedBy reports whether the task is blocked by the task with ID blockerID, directly or
+// through other tasks.
+func isBlockedBy(tx *gorm.DB, taskID uuid.UUID, blockerID uuid.UUID) (bool, error) {
+	var found bool
+	err := tx.Raw(`
+		WITH RECURSIVE blockers AS (
+			SELECT blocked_by_id FROM task_dependencies WHERE task_id = ?
+			UNION
+			SELECT task_dependencies.blocked_by_id
+			FROM task_dependencies JOIN blockers ON task_dependencies.task_id = blockers.blocked_by_id
+		)
+		SELECT EXISTS (SELECT 1 FROM blockers WHERE blocked_by_id = ?)`, taskID, blockerID).Scan(&found).Error
+	if err != nil {
+		return false, errors.NewInternalServerError("error retrieving dependencies")
+	}
+	return found, nil
+}
+
+// checkBlockersComplete returns a ConflictError if some tasks blocking the task are not complete.
+func checkBlockersComplete(tx *gorm.DB, taskID uuid.UUID) error {
+	var open int64
+	if err := tx.Table("task_dependencies").
+		Joins("JOIN tasks ON tasks.id = task_dependencies.blocked_by_id").
+		Where("task_dependencies.task_id = ? AND tasks.status <> ?", taskID, models.TaskStatusComplete).
+		Count(&open).Error; err != nil {
+		return errors.NewInternalServerError("error retrieving dependencies")
+	}
+	if open > 0 {
+		return errors.NewConflictError(fmt.Sprintf("task is blocked by %d open tasks; set force to change its status anyway", open))
+	}
+	return nil
+}
+
+// loadTaskDependencies fills in the tasks blocking the tasks and the tasks they block, by due date.
+// Related tasks the user cannot read, as decided by findReadableTask, are left out.
+func loadTaskDependencies(userID string, tasks ...*models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return errors.ErrInvalidID("user")
+	}
+
+	byID := make(map[uuid.UUID]*models.Task, len(tasks))
+	ids := make([]uuid.UUID, 0, len(tasks))
+	for _, t := range tasks {
+		t.BlockedBy, t.Blocking = []models.Task{}, []models.Task{}
+		byID[t.ID] = t
+		ids = append(ids, t.ID)
+	}
+
+	type dependencyRow struct {
+		OwnerID uuid.UUID
+		models.Task
+	}
+	load := func(ownerColumn string, relatedColumn string) ([]dependencyRow, error) {
+		var rows []dependencyRow
+		err := database.DB.Table("task_dependencies").
+			Select("task_dependencies."+ownerColumn+" AS owner_id, tasks.*").
+			Joins("JOIN tasks ON tasks.id = task_dependencies."+relatedColumn).
+			Where("task_dependencies."+ownerColumn+" IN ?", ids).
+			Where("("+readableTaskCondition+" OR EXISTS (SELECT 1 FROM users WHERE users.id = @user AND users.role = @admin))",
+				sql.Named("user", userUUID), sql.Named("admin", models.RoleAdmin)).
+			Order("tasks.due_date, tasks.id").Scan(&rows).Error
+		if err != nil {
+			return nil, errors.NewInternalServerError("error retrieving dependencies")
+		}
+		return rows, nil
+	}
+
+	blockers, err := load("task_id", "blocked_by_id")
+	if err != nil {
+		return err
+	}
+	for _, row := range blockers {
+		t := byID[row.OwnerID]
+		t.BlockedBy = append(t.BlockedBy, row.Task)
+	}
+
+	blocked, err := load("blocked_by_id", "task_id")
+	if err != nil {
+		return err
+	}
+	for _, row := range blocked {
+		t := byID[row.OwnerID]
+		t.Blocking = append(t.Blocking, row.Task)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/mailer"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Registration modes, selected with REGISTRATION_MODE.
+const (
+	// RegistrationOpen lets anyone register.
+	RegistrationOpen = "open"
+	// RegistrationInviteOnly only lets invited people register, through /auth/register or an OIDC provider.
+	RegistrationInviteOnly = "invite_only"
+)
+
+// registrationMode returns the configured registration mode.
+func registrationMode() string {
+	if os.Getenv("REGISTRATION_MODE") == RegistrationInviteOnly {
+		return RegistrationInviteOnly
+	}
+	return RegistrationOpen
+}
+
+// invitationTTL returns how long an invite link stays valid (INVITATION_TTL).
+func invitationTTL() time.Duration {
+	return utils.DurationFromEnv("INVITATION_TTL", 7*24*time.Hour)
+}
+
+// CreateInvitation invites an email address to register with the given role (member by default)
+// and emails the invite link. Inviting an address again replaces its pending invitation.
+// Only admins can invite admins.
+func CreateInvitation(inviterID string, inviterRole string, req dto.CreateInvitationRequest) (dto.InvitationResponse, error) {
+	inviterUUID, err := uuid.Parse(inviterID)
+	if err != nil {
+		return dto.InvitationResponse{}, errors.ErrInvalidID("user")
+	}
+
+	email := utils.CleanEmail(req.Email)
+	role := req.Role
+	if role == "" {
+		role = models.RoleMember
+	}
+	if role == models.RoleAdmin && inviterRole != models.RoleAdmin {
+		return dto.InvitationResponse{}, errors.NewForbiddenError("only admins can invite admins")
+	}
+
+	var inviter models.User
+	if err := database.DB.First(&inviter, "id = ?", inviterUUID).Error; err != nil {
+		return dto.InvitationResponse{}, errors.ErrNotFound("user")
+	}
+
+	var existing int64
+	if err := database.DB.Model(&models.User{}).Where("email = ?", email).Count(&existing).Error; err != nil {
+		return dto.InvitationResponse{}, errors.NewInternalServerError("database error")
+	}
+	if existing > 0 {
+		return dto.InvitationResponse{}, errors.NewConflictError("email already registered")
+	}
+
+	plain, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return dto.InvitationResponse{}, errors.NewInternalServerError("error generating token")
+	}
+
+	invitation := models.Invitation{
+		ID:        uuid.New(),
+		Email:     email,
+		Role:      role,
+		TokenHash: hash,
+		InvitedBy: &inviterUUID,
+		ExpiresAt: time.Now().Add(invitationTTL()),
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Invitation{}).
+			Where("email = ? AND accepted_at IS NULL AND revoked_at IS NULL", email).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return errors.NewInternalServerError("error replacing previous invitation")
+		}
+		if err := tx.Create(&invitation).Error; err != nil {
+			return errors.NewInternalServerError("error storing invitation")
+		}
+		return nil
+	})
+	if err != nil {
+		return dto.InvitationResponse{}, err
+	}
+
+	sendInvitationEmail(invitation, inviter, plain)
+	return toInvitationResponse(invitation), nil
+}
+
+// sendInvitationEmail emails the invite link. Delivery failures are logged; the invitation can be sent again.
+func sendInvitationEmail(invitation models.Invitation, inviter models.User, token string) {
+	link := fmt.Sprintf("%s/auth/invitation?token=%s", utils.AppURL(), url.QueryEscape(token))
+	msg := mailer.Message{
+		To:      invitation.Email,
+		Subject: "You have been invited to Task Manager",
+		Body: fmt.Sprintf(
+			"Hi,\n\n%s %s invited you to join Task Manager as a %s.\n\n"+
+				"Open the link below to view the invitation, then register with this email address and the invite token:\n\n%s\n\n"+
+				"The invitation expires in %s.\n",
+			inviter.FirstName, inviter.LastName, invitation.Role, link, invitationTTL(),
+		),
+	}
+	if err := mailer.Default().Send(msg); err != nil {
+		log.Printf("Failed to send invitation email: %v", err)
+	}
+}
+
+// ListInvitations returns pending invitations, newest first. Admins see every invitation,
+// other users the ones they sent.
+func ListInvitations(userID string, role string) ([]dto.InvitationResponse, error) {
+	query := database.DB.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", time.Now())
+	if role != models.RoleAdmin {
+		query = query.Where("invited_by = ?", userID)
+	}
+
+	var invitations []models.Invitation
+	if err := query.Order("created_at DESC").Find(&invitations).Error; err != nil {
+		return nil, errors.NewInternalServerError("database error")
+	}
+
+	responses := make([]dto.InvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		responses = append(responses, toInvitationResponse(invitation))
+	}
+	return responses, nil
+}
+
+// RevokeInvitation cancels a pending invitation. Only its sender or an admin can revoke it.
+func RevokeInvitation(userID string, role string, invitationID string) error {
+	invitationUUID, err := uuid.Parse(invitationID)
+	if err != nil {
+		return errors.ErrInvalidID("invitation")
+	}
+
+	var invitation models.Invitation
+	if err := database.DB.Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", invitationUUID).
+		First(&invitation).Error; err != nil {
+		return errors.ErrNotFound("invitation")
+	}
+
+	if role != models.RoleAdmin && (invitation.InvitedBy == nil || invitation.InvitedBy.String() != userID) {
+		return errors.ErrUnauthorizedAction("revoke", "invitation")
+	}
+
+	if err := database.DB.Model(&invitation).Update("revoked_at", time.Now()).Error; err != nil {
+		return errors.NewInternalServerError("error revoking invitation")
+	}
+	return nil
+}
+
+// GetInvitation returns the pending invitation for an invite token, so the invitee can see
+// which email and role it is for before registering.
+func GetInvitation(token string) (dto.InvitationResponse, error) {
+	var invitation models.Invitation
+	if err := database.DB.Where("token_hash = ?", utils.HashToken(token)).First(&invitation).Error; err != nil {
+		return dto.InvitationResponse{}, errors.NewValidationError("invalid or expired invitation")
+	}
+	if !invitationPending(invitation) {
+		return dto.InvitationResponse{}, errors.NewValidationError("invalid or expired invitation")
+	}
+	return toInvitationResponse(invitation), nil
+}
+
+// lockInvitation returns the pending invitation for an invite token, locked for the rest of the transaction.
+func lockInvitation(tx *gorm.DB, token string) (models.Invitation, error) {
+	var invitation models.Invitation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", utils.HashToken(token)).First(&invitation).Error; err != nil {
+		return models.Invitation{}, errors.NewValidationError("invalid or expired invitation")
+	}
+	if !invitationPending(invitation) {
+		return models.Invitation{}, errors.NewValidationError("invalid or expired invitation")
+	}
+	return invitation, nil
+}
+
+// lockInvitationForEmail returns the newest pending invitation for an email address, if any,
+// locked for the rest of the transaction.
+func lockInvitationForEmail(tx *gorm.DB, email string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("email = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", email, time.Now()).
+		Order("created_at DESC").First(&invitation).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.NewInternalServerError("database error")
+	}
+	return &invitation, nil
+}
+
+// acceptInvitation marks an invitation as used by the new user.
+func acceptInvitation(tx *gorm.DB, invitation models.Invitation, userID uuid.UUID) error {
+	if err := tx.Model(&invitation).Updates(map[string]interface{}{
+		"accepted_at": time.Now(),
+		"accepted_by": userID,
+	}).Error; err != nil {
+		return errors.NewInternalServerError("error accepting invitation")
+	}
+	return nil
+}
+
+// invitationPending reports whether an invitation can still be accepted.
+func invitationPending(invitation models.Invitation) bool {
+	return invitation.AcceptedAt == nil && invitation.RevokedAt == nil && time.Now().Before(invitation.ExpiresAt)
+}
+
+func toInvitationResponse(invitation models.Invitation) dto.InvitationResponse {
+	var invitedBy *string
+	if invitation.InvitedBy != nil {
+		id := invitation.InvitedBy.String()
+		invitedBy = &id
+	}
+
+	return dto.InvitationResponse{
+		ID:        invitation.ID.String(),
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		InvitedBy: invitedBy,
+		ExpiresAt: invitation.ExpiresAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+}
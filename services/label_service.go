@@ -0,0 +1,235 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultLabelColor is the color of labels created without one.
+const defaultLabelColor = "#6b7280"
+
+// ListLabels returns the labels visible to the user: the team labels and their personal labels.
+func ListLabels(userID string) ([]models.Label, error) {
+	var labels []models.Label
+	if err := visibleLabels(database.DB, userID).
+		Order("owner_id IS NULL, LOWER(name)").Find(&labels).Error; err != nil {
+		return nil, errors.NewInternalServerError("error retrieving labels")
+	}
+	return labels, nil
+}
+
+// CreateLabel creates a personal label (the default) or a team label.
+// Names are unique, ignoring case, among the user's personal labels and among team labels.
+func CreateLabel(userID string, req dto.CreateLabelRequest) (models.Label, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return models.Label{}, errors.ErrInvalidID("user")
+	}
+
+	label := models.Label{
+		ID:        uuid.New(),
+		CreatedBy: &userUUID,
+		Name:      strings.TrimSpace(req.Name),
+		Color:     strings.ToLower(req.Color),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if label.Color == "" {
+		label.Color = defaultLabelColor
+	}
+	if req.Scope != models.LabelScopeTeam {
+		label.OwnerID = &userUUID
+	}
+
+	if err := checkLabelNameAvailable(label); err != nil {
+		return models.Label{}, err
+	}
+	if err := database.DB.Create(&label).Error; err != nil {
+		if database.IsUniqueViolation(err) {
+			return models.Label{}, errors.NewConflictError("a label with this name already exists")
+		}
+		return models.Label{}, errors.NewInternalServerError("error creating label")
+	}
+	return label, nil
+}
+
+// UpdateLabel renames or recolors a label. Personal labels can only be changed by their owner,
+// team labels by their creator or an admin.
+func UpdateLabel(userID string, role string, labelID string, req dto.UpdateLabelRequest) (models.Label, error) {
+	label, err := findManageableLabel(userID, role, labelID, "update")
+	if err != nil {
+		return models.Label{}, err
+	}
+
+	if req.Name != "" {
+		label.Name = strings.TrimSpace(req.Name)
+		if err := checkLabelNameAvailable(label); err != nil {
+			return models.Label{}, err
+		}
+	}
+	if req.Color != "" {
+		label.Color = strings.ToLower(req.Color)
+	}
+	label.UpdatedAt = time.Now()
+
+	if err := database.DB.Save(&label).Error; err != nil {
+		if database.IsUniqueViolation(err) {
+			return models.Label{}, errors.NewConflictError("a label with this name already exists")
+		}
+		return models.Label{}, errors.NewInternalServerError("error updating label")
+	}
+	return label, nil
+}
+
+// DeleteLabel deletes a label, detaching it from every task. The same rules as UpdateLabel apply.
+func DeleteLabel(userID string, role string, labelID string) error {
+	label, err := findManageableLabel(userID, role, labelID, "delete")
+	if err != nil {
+		return err
+	}
+
+	if err := database.DB.Delete(&label).Error; err != nil {
+		return errors.NewInternalServerError("error deleting label")
+	}
+	return nil
+}
+
+// AttachLabel adds a label to a task. Anyone who can see the task may label it with a label they can see;
+// attaching a label twice has no effect.
+func AttachLabel(taskID string, labelID string, userID string, role string) error {
+	task, label, err := findTaskAndLabel(taskID, labelID, userID, role)
+	if err != nil {
+		return err
+	}
+
+	link := models.TaskLabel{TaskID: task.ID, LabelID: label.ID}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&link).Error; err != nil {
+		return errors.NewInternalServerError("error attaching label")
+	}
+	return nil
+}
+
+// DetachLabel removes a label from a task. Removing a label the task does not carry has no effect.
+func DetachLabel(taskID string, labelID string, userID string, role string) error {
+	task, label, err := findTaskAndLabel(taskID, labelID, userID, role)
+	if err != nil {
+		return err
+	}
+
+	if err := database.DB.Where("task_id = ? AND label_id = ?", task.ID, label.ID).
+		Delete(&models.TaskLabel{}).Error; err != nil {
+		return errors.NewInternalServerError("error detaching label")
+	}
+	return nil
+}
+
+// loadTaskLabels fills in the labels of the tasks that are visible to the user, sorted by name.
+func loadTaskLabels(userID string, tasks ...*models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	byID := make(map[uuid.UUID]*models.Task, len(tasks))
+	ids := make([]uuid.UUID, 0, len(tasks))
+	for _, t := range tasks {
+		t.Labels = []models.Label{}
+		byID[t.ID] = t
+		ids = append(ids, t.ID)
+	}
+
+	var rows []struct {
+		TaskID       uuid.UUID
+		models.Label `gorm:"embedded"`
+	}
+	err := visibleLabels(database.DB, userID).Model(&models.Label{}).
+		Select("task_labels.task_id, labels.*").
+		Joins("JOIN task_labels ON task_labels.label_id = labels.id").
+		Where("task_labels.task_id IN ?", ids).
+		Order("LOWER(labels.name)").
+		Scan(&rows).Error
+	if err != nil {
+		return errors.NewInternalServerError("error retrieving labels")
+	}
+
+	for _, row := range rows {
+		t := byID[row.TaskID]
+		t.Labels = append(t.Labels, row.Label)
+	}
+	return nil
+}
+
+// visibleLabels scopes a labels query to the team labels and the user's personal labels.
+func visibleLabels(db *gorm.DB, userID string) *gorm.DB {
+	return db.Where("labels.owner_id IS NULL OR labels.owner_id = ?", userID)
+}
+
+// findVisibleLabel loads a label visible to the user.
+func findVisibleLabel(userID string, labelID string) (models.Label, error) {
+	if _, err := uuid.Parse(labelID); err != nil {
+		return models.Label{}, errors.ErrInvalidID("label")
+	}
+
+	var label models.Label
+	if err := visibleLabels(database.DB, userID).First(&label, "labels.id = ?", labelID).Error; err != nil {
+		return models.Label{}, errors.ErrNotFound("label")
+	}
+	return label, nil
+}
+
+// findManageableLabel loads a label the user may change: their own personal label,
+// or a team label they created. Admins may change every team label.
+func findManageableLabel(userID string, role string, labelID string, action string) (models.Label, error) {
+	label, err := findVisibleLabel(userID, labelID)
+	if err != nil {
+		return models.Label{}, err
+	}
+
+	if label.Scope() == models.LabelScopeTeam && role != models.RoleAdmin &&
+		(label.CreatedBy == nil || label.CreatedBy.String() != userID) {
+		return models.Label{}, errors.ErrUnauthorizedAction(action, "label")
+	}
+	return label, nil
+}
+
+// findTaskAndLabel loads a task and a label the user can see, for labeling the task. Users who were
+// only mentioned in the task cannot label it.
+func findTaskAndLabel(taskID string, labelID string, userID string, role string) (*models.Task, models.Label, error) {
+	task, err := findVisibleTask(database.DB, taskID, userID, role)
+	if err != nil {
+		return nil, models.Label{}, err
+	}
+
+	label, err := findVisibleLabel(userID, labelID)
+	if err != nil {
+		return nil, models.Label{}, err
+	}
+	return task, label, nil
+}
+
+// checkLabelNameAvailable returns a ConflictError if another label of the same scope
+// (the owner's personal labels, or the team labels) already has the label's name.
+func checkLabelNameAvailable(label models.Label) error {
+	query := database.DB.Model(&models.Label{}).Where("LOWER(name) = LOWER(?) AND id <> ?", label.Name, label.ID)
+	if label.OwnerID != nil {
+		query = query.Where("owner_id = ?", *label.OwnerID)
+	} else {
+		query = query.Where("owner_id IS NULL")
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return errors.NewInternalServerError("database error")
+	}
+	if count > 0 {
+		return errors.NewConflictError("a label with this name already exists")
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/lockout"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+)
+
+var (
+	loginStoreMu sync.Mutex
+	loginStore   lockout.Store
+)
+
+// loginAttemptStore returns the store tracking failed logins, selected with LOGIN_THROTTLE_STORE:
+// "postgres" (the default, shared by all replicas) or "memory" (single process).
+func loginAttemptStore() lockout.Store {
+	loginStoreMu.Lock()
+	defer loginStoreMu.Unlock()
+
+	if loginStore == nil {
+		if os.Getenv("LOGIN_THROTTLE_STORE") == "memory" {
+			loginStore = lockout.NewMemoryStore()
+		} else {
+			loginStore = lockout.NewPostgresStore(database.DB)
+		}
+	}
+	return loginStore
+}
+
+// SetLoginAttemptStore replaces the store tracking failed logins.
+func SetLoginAttemptStore(store lockout.Store) {
+	loginStoreMu.Lock()
+	loginStore = store
+	loginStoreMu.Unlock()
+}
+
+// emailPolicy throttles guesses against a single account.
+func emailPolicy() lockout.Policy {
+	return lockout.Policy{
+		FreeAttempts:    3,
+		MaxFailures:     utils.IntFromEnv("LOGIN_MAX_FAILURES", 10),
+		BaseDelay:       time.Second,
+		LockoutDuration: utils.DurationFromEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		Window:          15 * time.Minute,
+	}
+}
+
+// ipPolicy throttles a single client spraying guesses across many accounts.
+// It is more lenient because many users may share an address behind NAT.
+func ipPolicy() lockout.Policy {
+	return lockout.Policy{
+		FreeAttempts:    20,
+		MaxFailures:     utils.IntFromEnv("LOGIN_IP_MAX_FAILURES", 100),
+		BaseDelay:       time.Second,
+		LockoutDuration: utils.DurationFromEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		Window:          15 * time.Minute,
+	}
+}
+
+// loginKeys returns the throttling keys and their policies for an attempt.
+func loginKeys(email, ip string) map[string]lockout.Policy {
+	keys := map[string]lockout.Policy{"email:" + email: emailPolicy()}
+	if ip != "" {
+		keys["ip:"+ip] = ipPolicy()
+	}
+	return keys
+}
+
+// checkLoginAllowed rejects the attempt while the email or the client IP is backing off or locked out.
+// Store failures are logged and do not block logins.
+func checkLoginAllowed(email, ip string) error {
+	store := loginAttemptStore()
+	now := time.Now()
+
+	var retryAfter time.Duration
+	for key := range loginKeys(email, ip) {
+		state, err := store.Get(key)
+		if err != nil {
+			log.Printf("Failed to read login attempts: %v", err)
+			continue
+		}
+		if wait := state.RetryAfter(now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return errors.NewTooManyRequestsError("too many failed login attempts, try again later", retryAfter)
+	}
+	return nil
+}
+
+// registerLoginFailure records a failed attempt and writes an audit record for each lockout.
+func registerLoginFailure(email, ip string) {
+	store := loginAttemptStore()
+	now := time.Now()
+
+	for key, policy := range loginKeys(email, ip) {
+		state, err := store.RegisterFailure(key, policy, now)
+		if err != nil {
+			log.Printf("Failed to record login attempt: %v", err)
+			continue
+		}
+
+		if policy.LockedOut(state) {
+			event := models.LockoutEvent{Key: key, Failures: state.Failures, LockedUntil: state.LockedUntil}
+			if err := database.DB.Create(&event).Error; err != nil {
+				log.Printf("Failed to record lockout: %v", err)
+			}
+			log.Printf("Login locked out for %s until %s after %d failures", key, state.LockedUntil.Format(time.RFC3339), state.Failures)
+		}
+	}
+}
+
+// throttleAttempt counts an attempt against each key and its policy, and rejects it while any of them
+// is backing off or locked out; rejected attempts are not counted. It throttles requests that cost
+// something whether or not they succeed, such as password reset emails. Store failures are logged and do not block requests.
+func throttleAttempt(keys map[string]lockout.Policy) error {
+	store := loginAttemptStore()
+	now := time.Now()
+
+	var retryAfter time.Duration
+	for key := range keys {
+		state, err := store.Get(key)
+		if err != nil {
+			log.Printf("Failed to read attempts: %v", err)
+			continue
+		}
+		if wait := state.RetryAfter(now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	if retryAfter > 0 {
+		return errors.NewTooManyRequestsError("too many requests, try again later", retryAfter)
+	}
+
+	for key, policy := range keys {
+		if _, err := store.RegisterFailure(key, policy, now); err != nil {
+			log.Printf("Failed to record attempt: %v", err)
+		}
+	}
+	return nil
+}
+
+// resetLoginFailures clears the account's failure count after a successful login.
+// The client IP counter is left to expire so a valid login cannot mask password spraying.
+func resetLoginFailures(email string) {
+	if err := loginAttemptStore().Reset("email:" + email); err != nil {
+		log.Printf("Failed to reset login attempts: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// mentionPattern matches @username and @email mentions. A mention starts the text or follows a
+// character that cannot be part of a word or address, so "me@example.com" is not a mention.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w.@+-])@([A-Za-z0-9][A-Za-z0-9_.+-]*(?:@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)+)?)`)
+
+// extractMentions returns the lowercase email addresses and usernames mentioned in text, without duplicates.
+func extractMentions(text string) (emails []string, usernames []string) {
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		// Punctuation after a mention, as in "thanks @jdoe.", is not part of it
+		mention := strings.ToLower(strings.TrimRight(match[1], ".-+"))
+		if mention == "" || seen[mention] {
+			continue
+		}
+		seen[mention] = true
+
+		if strings.Contains(mention, "@") {
+			emails = append(emails, mention)
+		} else {
+			usernames = append(usernames, mention)
+		}
+	}
+	return emails, usernames
+}
+
+// resolveMentions returns the active users mentioned in text.
+func resolveMentions(tx *gorm.DB, text string) ([]models.User, error) {
+	emails, usernames := extractMentions(text)
+	if len(emails) == 0 && len(usernames) == 0 {
+		return nil, nil
+	}
+
+	query := tx.Where("disabled_at IS NULL AND deletion_scheduled_at IS NULL")
+	switch {
+	case len(emails) == 0:
+		query = query.Where("username IN ?", usernames)
+	case len(usernames) == 0:
+		query = query.Where("email IN ?", emails)
+	default:
+		query = query.Where("email IN ? OR username IN ?", emails, usernames)
+	}
+
+	var users []models.User
+	if err := query.Find(&users).Error; err != nil {
+		return nil, errors.NewInternalServerError("error resolving mentions")
+	}
+	return users, nil
+}
+
+// notifyMentions notifies the users mentioned in text, except those already mentioned in previous
+// (the text before an edit) and the actor, and grants them read access to the task.
+func notifyMentions(tx *gorm.DB, actorID uuid.UUID, kind string, taskID uuid.UUID, commentID *uuid.UUID, text string, previous string) error {
+	mentioned, err := resolveMentions(tx, text)
+	if err != nil || len(mentioned) == 0 {
+		return err
+	}
+	before, err := resolveMentions(tx, previous)
+	if err != nil {
+		return err
+	}
+	already := map[uuid.UUID]bool{actorID: true}
+	for _, u := range before {
+		already[u.ID] = true
+	}
+
+	now := time.Now()
+	for _, u := range mentioned {
+		if already[u.ID] {
+			continue
+		}
+
+		mention := models.TaskMention{TaskID: taskID, UserID: u.ID, CreatedAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&mention).Error; err != nil {
+			return errors.NewInternalServerError("error recording mention")
+		}
+
+		notification := models.Notification{
+			ID:        uuid.New(),
+			UserID:    u.ID,
+			ActorID:   &actorID,
+			Kind:      kind,
+			TaskID:    taskID,
+			CommentID: commentID,
+			CreatedAt: now,
+		}
+		if err := tx.Create(&notification).Error; err != nil {
+			return errors.NewInternalServerError("error creating notification")
+		}
+	}
+	return nil
+}
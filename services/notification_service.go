@@ -0,0 +1,143 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+)
+
+// notificationCursor is the position of the last notification of a page.
+type notificationCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// ListNotifications returns one page of the user's notifications, newest first, together with the
+// cursor of the next page (empty on the last page). With unread set, only unread ones are listed.
+func ListNotifications(userID string, unread bool, cursor string, limit int) ([]models.Notification, string, error) {
+	query := database.DB.Where("user_id = ?", userID)
+	if unread {
+		query = query.Where("read_at IS NULL")
+	}
+	if cursor != "" {
+		var after notificationCursor
+		if err := utils.DecodeCursor(cursor, &after); err != nil {
+			return nil, "", err
+		}
+		query = query.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	var notifications []models.Notification
+	if err := query.Order("created_at DESC, id DESC").Limit(limit + 1).Find(&notifications).Error; err != nil {
+		return nil, "", errors.NewInternalServerError("error retrieving notifications")
+	}
+	more := len(notifications) > limit
+	if more {
+		notifications = notifications[:limit]
+	}
+
+	if err := loadNotificationDetails(notifications); err != nil {
+		return nil, "", err
+	}
+	if !more {
+		return notifications, "", nil
+	}
+
+	last := notifications[len(notifications)-1]
+	next, err := utils.EncodeCursor(notificationCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	if err != nil {
+		return nil, "", errors.NewInternalServerError("error encoding cursor")
+	}
+	return notifications, next, nil
+}
+
+// CountUnreadNotifications returns how many of the user's notifications are unread.
+func CountUnreadNotifications(userID string) (int64, error) {
+	var count int64
+	if err := database.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		return 0, errors.NewInternalServerError("error retrieving notifications")
+	}
+	return count, nil
+}
+
+// MarkNotificationRead marks one of the user's notifications as read. Marking it again has no effect.
+func MarkNotificationRead(userID string, notificationID string) error {
+	if _, err := uuid.Parse(notificationID); err != nil {
+		return errors.ErrInvalidID("notification")
+	}
+
+	var notification models.Notification
+	if err := database.DB.Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		return errors.ErrNotFound("notification")
+	}
+	if notification.ReadAt != nil {
+		return nil
+	}
+
+	if err := database.DB.Model(&notification).Update("read_at", time.Now()).Error; err != nil {
+		return errors.NewInternalServerError("error updating notification")
+	}
+	return nil
+}
+
+// MarkAllNotificationsRead marks all of the user's unread notifications as read.
+func MarkAllNotificationsRead(userID string) error {
+	if err := database.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error; err != nil {
+		return errors.NewInternalServerError("error updating notifications")
+	}
+	return nil
+}
+
+// loadNotificationDetails fills in the tasks and actors of the notifications.
+func loadNotificationDetails(notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	taskIDs := make([]uuid.UUID, 0, len(notifications))
+	actorIDs := make([]uuid.UUID, 0, len(notifications))
+	for _, n := range notifications {
+		taskIDs = append(taskIDs, n.TaskID)
+		if n.ActorID != nil {
+			actorIDs = append(actorIDs, *n.ActorID)
+		}
+	}
+
+	var tasks []models.Task
+	if err := database.DB.Where("id IN ?", taskIDs).Find(&tasks).Error; err != nil {
+		return errors.NewInternalServerError("error retrieving notifications")
+	}
+	tasksByID := make(map[uuid.UUID]models.Task, len(tasks))
+	for _, t := range tasks {
+		tasksByID[t.ID] = t
+	}
+
+	actorsByID := map[uuid.UUID]models.User{}
+	if len(actorIDs) > 0 {
+		var actors []models.User
+		if err := database.DB.Where("id IN ?", actorIDs).Find(&actors).Error; err != nil {
+			return errors.NewInternalServerError("error retrieving notifications")
+		}
+		for _, u := range actors {
+			actorsByID[u.ID] = u
+		}
+	}
+
+	for i := range notifications {
+		n := &notifications[i]
+		n.Task = tasksByID[n.TaskID]
+		if n.ActorID != nil {
+			if actor, ok := actorsByID[*n.ActorID]; ok {
+				n.Actor = &actor
+			}
+		}
+	}
+	return nil
+}
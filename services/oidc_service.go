@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/oidc"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"gorm.io/gorm"
+)
+
+// oidcRequestTimeout bounds the calls made to an identity provider while handling one request.
+const oidcRequestTimeout = 15 * time.Second
+
+// oidcStateTTL returns how long a started OIDC login can be completed (OIDC_STATE_TTL).
+func oidcStateTTL() time.Duration {
+	return utils.DurationFromEnv("OIDC_STATE_TTL", 10*time.Minute)
+}
+
+// oidcProvider returns the configured provider with the given name.
+func oidcProvider(ctx context.Context, name string) (*oidc.Provider, error) {
+	cfg, err := oidc.ConfigFromEnv(name, utils.AppURL())
+	if err == oidc.ErrUnknownProvider {
+		return nil, errors.ErrNotFound("identity provider")
+	}
+	if err != nil {
+		log.Printf("OIDC configuration error: %v", err)
+		return nil, errors.NewInternalServerError("identity provider is misconfigured")
+	}
+
+	provider, err := oidc.NewProvider(ctx, cfg)
+	if err != nil {
+		log.Printf("OIDC discovery failed for %s: %v", name, err)
+		return nil, errors.NewInternalServerError("identity provider unavailable")
+	}
+	return provider, nil
+}
+
+// StartOIDCLogin begins an authorization code flow with PKCE and returns the provider URL
+// the user must be redirected to. The code verifier and nonce are kept server-side,
+// keyed by the hash of the state parameter.
+func StartOIDCLogin(providerName string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), oidcRequestTimeout)
+	defer cancel()
+
+	provider, err := oidcProvider(ctx, providerName)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		return "", errors.NewInternalServerError("error generating state")
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return "", errors.NewInternalServerError("error generating nonce")
+	}
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		return "", errors.NewInternalServerError("error generating code verifier")
+	}
+
+	// Expired logins are never completed; clean them up as new ones start
+	database.DB.Where("expires_at < ?", time.Now()).Delete(&models.OIDCLoginState{})
+
+	if err := database.DB.Create(&models.OIDCLoginState{
+		StateHash:    utils.HashToken(state),
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(oidcStateTTL()),
+	}).Error; err != nil {
+		return "", errors.NewInternalServerError("error storing login state")
+	}
+
+	return provider.AuthCodeURL(state, nonce, verifier), nil
+}
+
+// CompleteOIDCLogin handles the provider's redirect back to the API. It consumes the login state,
+// redeems the code and signs the user in:
+//   - a user already linked to the provider identity is signed in;
+//   - otherwise the identity is linked to the user with the same email, if the provider verified it;
+//   - otherwise a new, verified user is created.
+//
+// Like AuthenticateUser, a challenge is returned instead of tokens when the user has 2FA enabled.
+func CompleteOIDCLogin(providerName string, code string, state string, client utils.ClientInfo) (dto.AuthResponse, *dto.MFAChallengeResponse, error) {
+	loginState, err := consumeOIDCLoginState(providerName, state)
+	if err != nil {
+		return dto.AuthResponse{}, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oidcRequestTimeout)
+	defer cancel()
+
+	provider, err := oidcProvider(ctx, providerName)
+	if err != nil {
+		return dto.AuthResponse{}, nil, err
+	}
+
+	identity, err := provider.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		log.Printf("OIDC code exchange failed for %s: %v", providerName, err)
+		return dto.AuthResponse{}, nil, errors.NewAuthError("identity provider login failed")
+	}
+
+	var user models.User
+	claimed := false
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		user, claimed, err = findOrLinkOIDCUser(tx, providerName, identity)
+		return err
+	})
+	if err != nil {
+		return dto.AuthResponse{}, nil, err
+	}
+
+	if claimed {
+		// Whoever registered the unverified account never proved they own the address,
+		// so their sessions and personal access tokens end along with the password reset by the claim.
+		if err := revokeUserCredentials(user.ID.String()); err != nil {
+			return dto.AuthResponse{}, nil, err
+		}
+	}
+
+	if user.DisabledAt != nil {
+		return dto.AuthResponse{}, nil, errors.NewAuthError("account disabled")
+	}
+
+	if user.TOTPEnabledAt != nil {
+		challenge, err := twoFactorChallenge(user)
+		return dto.AuthResponse{}, challenge, err
+	}
+
+	tokens, _, err := issueTokens(database.DB, user, uuid.Nil, client)
+	if err != nil {
+		return dto.AuthResponse{}, nil, err
+	}
+	return tokens, nil, nil
+}
+
+// consumeOIDCLoginState deletes and returns the pending login for the state parameter.
+// Each state can be used once, and only with the provider it was issued for.
+func consumeOIDCLoginState(providerName string, state string) (models.OIDCLoginState, error) {
+	var loginState models.OIDCLoginState
+	if err := database.DB.Where("state_hash = ? AND provider = ?", utils.HashToken(state), providerName).
+		First(&loginState).Error; err != nil {
+		return models.OIDCLoginState{}, errors.NewAuthError("invalid or expired login state")
+	}
+
+	// Only the request that deletes the row may continue, so a state cannot be replayed concurrently
+	result := database.DB.Where("state_hash = ?", loginState.StateHash).Delete(&models.OIDCLoginState{})
+	if result.Error != nil {
+		return models.OIDCLoginState{}, errors.NewInternalServerError("error consuming login state")
+	}
+	if result.RowsAffected == 0 || time.Now().After(loginState.ExpiresAt) {
+		return models.OIDCLoginState{}, errors.NewAuthError("invalid or expired login state")
+	}
+	return loginState, nil
+}
+
+// findOrLinkOIDCUser returns the user for a provider identity, linking or creating one if needed.
+// It reports whether an unverified account was claimed, in which case its sessions must be revoked.
+func findOrLinkOIDCUser(tx *gorm.DB, providerName string, identity *oidc.Identity) (models.User, bool, error) {
+	var user models.User
+
+	var link models.UserIdentity
+	err := tx.Where("provider = ? AND subject = ?", providerName, identity.Subject).First(&link).Error
+	if err == nil {
+		if err := tx.First(&user, "id = ?", link.UserID).Error; err != nil {
+			return models.User{}, false, errors.NewInternalServerError("database error")
+		}
+		return user, false, nil
+	} else if err != gorm.ErrRecordNotFound {
+		return models.User{}, false, errors.NewInternalServerError("database error")
+	}
+
+	// Linking by email is only safe when the provider vouches for the address
+	if identity.Email == "" || !identity.EmailVerified {
+		return models.User{}, false, errors.NewAuthError("identity provider did not return a verified email address")
+	}
+
+	claimed := false
+	err = tx.Where("email = ?", identity.Email).First(&user).Error
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		user, err = createOIDCUser(tx, identity)
+		if err != nil {
+			return models.User{}, false, err
+		}
+	case err != nil:
+		return models.User{}, false, errors.NewInternalServerError("database error")
+	case user.EmailVerifiedAt == nil:
+		if err := claimUnverifiedUser(tx, &user); err != nil {
+			return models.User{}, false, err
+		}
+		claimed = true
+	}
+
+	if err := tx.Create(&models.UserIdentity{
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}).Error; err != nil {
+		return models.User{}, false, errors.NewInternalServerError("error linking identity")
+	}
+	return user, claimed, nil
+}
+
+// createOIDCUser registers a user from a provider identity. The account gets a random password;
+// the user can set one through the password reset flow. A pending invitation for the email is
+// accepted and sets the role; invite-only deployments require one.
+func createOIDCUser(tx *gorm.DB, identity *oidc.Identity) (models.User, error) {
+	invitation, err := lockInvitationForEmail(tx, identity.Email)
+	if err != nil {
+		return models.User{}, err
+	}
+	if invitation == nil && registrationMode() == RegistrationInviteOnly {
+		return models.User{}, errors.NewForbiddenError("registration requires an invitation")
+	}
+
+	password, err := oidc.RandomString()
+	if err != nil {
+		return models.User{}, errors.NewInternalServerError("error generating password")
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return models.User{}, errors.NewInternalServerError("error hashing password")
+	}
+
+	firstName, lastName := identity.GivenName, identity.FamilyName
+	if firstName == "" && lastName == "" {
+		firstName, lastName, _ = strings.Cut(strings.TrimSpace(identity.Name), " ")
+	}
+
+	now := time.Now()
+	user := models.User{
+		FirstName:       firstName,
+		LastName:        lastName,
+		Email:           identity.Email,
+		Password:        hashedPassword,
+		EmailVerifiedAt: &now,
+	}
+	if invitation != nil {
+		user.Role = invitation.Role
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		return models.User{}, errors.NewInternalServerError("error creating user")
+	}
+
+	if invitation != nil {
+		if err := acceptInvitation(tx, *invitation, user.ID); err != nil {
+			return models.User{}, err
+		}
+	}
+	return user, nil
+}
+
+// claimUnverifiedUser hands an unverified account over to the owner of its email address
+// by replacing the password chosen by whoever registered it.
+func claimUnverifiedUser(tx *gorm.DB, user *models.User) error {
+	password, err := oidc.RandomString()
+	if err != nil {
+		return errors.NewInternalServerError("error generating password")
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return errors.NewInternalServerError("error hashing password")
+	}
+
+	if err := tx.Model(user).Updates(map[string]interface{}{
+		"password":          hashedPassword,
+		"email_verified_at": time.Now(),
+	}).Error; err != nil {
+		return errors.NewInternalServerError("error updating user")
+	}
+	return nil
+}
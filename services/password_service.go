@@ -0,0 +1,136 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/lockout"
+	"github.com/kfeuerschvenger/task-manager-api/mailer"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"gorm.io/gorm"
+)
+
+// passwordResetTTL returns how long a password reset link stays valid (PASSWORD_RESET_TTL).
+func passwordResetTTL() time.Duration {
+	return utils.DurationFromEnv("PASSWORD_RESET_TTL", time.Hour)
+}
+
+// passwordResetResponseTime returns the minimum time a password reset request takes
+// (PASSWORD_RESET_RESPONSE_TIME), so that its timing does not reveal whether an account exists.
+func passwordResetResponseTime() time.Duration {
+	return utils.DurationFromEnv("PASSWORD_RESET_RESPONSE_TIME", time.Second)
+}
+
+// passwordResetEmailPolicy limits the reset emails that can be requested for a single address.
+func passwordResetEmailPolicy() lockout.Policy {
+	return lockout.Policy{
+		FreeAttempts:    3,
+		MaxFailures:     utils.IntFromEnv("PASSWORD_RESET_MAX_REQUESTS", 5),
+		BaseDelay:       time.Minute,
+		LockoutDuration: time.Hour,
+		Window:          time.Hour,
+	}
+}
+
+// passwordResetIPPolicy limits the reset requests a single client can make across addresses.
+func passwordResetIPPolicy() lockout.Policy {
+	return lockout.Policy{
+		FreeAttempts:    20,
+		MaxFailures:     utils.IntFromEnv("PASSWORD_RESET_IP_MAX_REQUESTS", 50),
+		BaseDelay:       time.Second,
+		LockoutDuration: time.Hour,
+		Window:          time.Hour,
+	}
+}
+
+// RequestPasswordReset emails a single-use reset link to the given address. Requests are counted per
+// address and per client IP in the login attempt store, and rejected with a TooManyRequestsError while
+// either is throttled. Unknown addresses and delivery failures are only logged, and every request takes
+// at least PASSWORD_RESET_RESPONSE_TIME, so callers cannot tell whether an account exists.
+func RequestPasswordReset(email string, ip string) error {
+	email = utils.CleanEmail(email)
+
+	keys := map[string]lockout.Policy{"reset-email:" + email: passwordResetEmailPolicy()}
+	if ip != "" {
+		keys["reset-ip:"+ip] = passwordResetIPPolicy()
+	}
+	if err := throttleAttempt(keys); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	defer func() {
+		time.Sleep(passwordResetResponseTime() - time.Since(start))
+	}()
+
+	var user models.User
+	if err := database.DB.Where("email = ?", email).First(&user).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			log.Printf("Password reset lookup failed: %v", err)
+		}
+		return nil
+	}
+	if err := sendPasswordReset(user); err != nil {
+		log.Printf("Failed to issue password reset: %v", err)
+	}
+	return nil
+}
+
+// sendPasswordReset issues a new reset token for the user and emails it.
+func sendPasswordReset(user models.User) error {
+	token, err := createActionToken(database.DB, user.ID, models.PurposePasswordReset, passwordResetTTL())
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", utils.AppURL(), url.QueryEscape(token))
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nWe received a request to reset your password. Use the link below to choose a new one:\n\n%s\n\n"+
+				"Your reset token is: %s\n\nThe link expires in %s. If you did not request a reset, you can ignore this email.\n",
+			user.FirstName, link, token, passwordResetTTL(),
+		),
+	}
+	if err := mailer.Default().Send(msg); err != nil {
+		// Do not surface delivery failures to the caller; that would reveal the account exists.
+		log.Printf("Failed to send password reset email: %v", err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token, logs the user out everywhere and revokes
+// their personal access tokens.
+func ResetPassword(token string, newPassword string) error {
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return errors.NewInternalServerError("error hashing password")
+	}
+
+	var userID string
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		resetToken, err := consumeActionToken(tx, models.PurposePasswordReset, token)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", resetToken.UserID).
+			Update("password", hashedPassword).Error; err != nil {
+			return errors.NewInternalServerError("error updating password")
+		}
+
+		userID = resetToken.UserID.String()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return revokeUserCredentials(userID)
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+)
+
+// lastUsedResolution limits how often a token's last-used timestamp is written.
+const lastUsedResolution = time.Minute
+
+// CreatePersonalAccessToken creates a new token for the user. The returned response is the only
+// place where the plain token is ever exposed.
+func CreatePersonalAccessToken(userID string, req dto.CreatePersonalAccessTokenRequest) (dto.CreatedPersonalAccessTokenResponse, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return dto.CreatedPersonalAccessTokenResponse{}, errors.ErrInvalidID("user")
+	}
+
+	plain, lookup, hash, err := utils.GeneratePersonalAccessToken()
+	if err != nil {
+		return dto.CreatedPersonalAccessTokenResponse{}, errors.NewInternalServerError("error generating token")
+	}
+
+	token := models.PersonalAccessToken{
+		ID:        uuid.New(),
+		UserID:    userUUID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    lookup,
+		TokenHash: hash,
+		Scopes:    strings.Join(uniqueScopes(req.Scopes), " "),
+	}
+	if req.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return dto.CreatedPersonalAccessTokenResponse{}, errors.ErrInvalidField("expires_at")
+		}
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := database.DB.Create(&token).Error; err != nil {
+		return dto.CreatedPersonalAccessTokenResponse{}, errors.NewInternalServerError("error storing token")
+	}
+
+	return dto.CreatedPersonalAccessTokenResponse{
+		PersonalAccessTokenResponse: toPersonalAccessTokenResponse(token),
+		Token:                       plain,
+	}, nil
+}
+
+// ListPersonalAccessTokens returns the user's tokens, newest first.
+func ListPersonalAccessTokens(userID string) ([]dto.PersonalAccessTokenResponse, error) {
+	var tokens []models.PersonalAccessToken
+	if err := database.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.PersonalAccessTokenResponse, 0, len(tokens))
+	for _, t := range tokens {
+		resp = append(resp, toPersonalAccessTokenResponse(t))
+	}
+	return resp, nil
+}
+
+// DeletePersonalAccessToken revokes one of the user's tokens.
+func DeletePersonalAccessToken(userID string, tokenID string) error {
+	if _, err := uuid.Parse(tokenID); err != nil {
+		return errors.ErrNotFound("token")
+	}
+
+	result := database.DB.Where("id = ? AND user_id = ?", tokenID, userID).Delete(&models.PersonalAccessToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound("token")
+	}
+	return nil
+}
+
+// AuthenticatePersonalAccessToken validates a personal access token and returns its owner's ID and role
+// together with the token's scopes. Tokens of disabled users are rejected.
+func AuthenticatePersonalAccessToken(plain string) (string, string, []string, error) {
+	lookup, ok := utils.ParsePersonalAccessToken(plain)
+	if !ok {
+		return "", "", nil, errors.NewAuthError("invalid token")
+	}
+
+	var token models.PersonalAccessToken
+	if err := database.DB.Where("prefix = ?", lookup).First(&token).Error; err != nil {
+		return "", "", nil, errors.NewAuthError("invalid token")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token.TokenHash), []byte(utils.HashToken(plain))) != 1 {
+		return "", "", nil, errors.NewAuthError("invalid token")
+	}
+
+	now := time.Now()
+	if token.ExpiresAt != nil && now.After(*token.ExpiresAt) {
+		return "", "", nil, errors.NewAuthError("token expired")
+	}
+
+	var owner models.User
+	if err := database.DB.Select("id", "role", "disabled_at").First(&owner, "id = ?", token.UserID).Error; err != nil {
+		return "", "", nil, errors.NewAuthError("invalid token")
+	}
+	if owner.DisabledAt != nil {
+		return "", "", nil, errors.NewAuthError("account disabled")
+	}
+
+	// Record usage, but write at most once per lastUsedResolution per token.
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > lastUsedResolution {
+		database.DB.Model(&token).Update("last_used_at", now)
+	}
+
+	return token.UserID.String(), owner.Role, token.ScopeList(), nil
+}
+
+// toPersonalAccessTokenResponse maps a token to its public representation.
+func toPersonalAccessTokenResponse(t models.PersonalAccessToken) dto.PersonalAccessTokenResponse {
+	return dto.PersonalAccessTokenResponse{
+		ID:         t.ID.String(),
+		Name:       t.Name,
+		Prefix:     utils.PersonalAccessTokenPrefix + t.Prefix,
+		Scopes:     t.ScopeList(),
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+// uniqueScopes removes duplicate scopes while preserving order.
+func uniqueScopes(scopes []string) []string {
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	return result
+}
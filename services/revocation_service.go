@@ -0,0 +1,198 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"gorm.io/gorm/clause"
+)
+
+// revocationCache is an in-process copy of the revocation store.
+// It is consulted on every authenticated request and re-synchronized from Postgres
+// at most once per sync interval, so revocations made by other replicas are picked up
+// within TOKEN_REVOCATION_SYNC_INTERVAL.
+type revocationCache struct {
+	mu       sync.RWMutex
+	tokens   map[string]time.Time // jti -> token expiration
+	users    map[string]time.Time // user ID -> tokens issued up to this instant are revoked
+	sessions map[string]time.Time // session ID -> revocation time
+	syncedAt time.Time
+}
+
+var revocations = &revocationCache{
+	tokens:   make(map[string]time.Time),
+	users:    make(map[string]time.Time),
+	sessions: make(map[string]time.Time),
+}
+
+// revocationSyncInterval returns how often the in-process cache is reloaded from the database.
+func revocationSyncInterval() time.Duration {
+	return utils.DurationFromEnv("TOKEN_REVOCATION_SYNC_INTERVAL", 30*time.Second)
+}
+
+// sync reloads the cache from the database if it is older than the sync interval.
+// Only entries that can still match an unexpired access token are loaded.
+func (c *revocationCache) sync() {
+	c.mu.RLock()
+	fresh := time.Since(c.syncedAt) < revocationSyncInterval()
+	c.mu.RUnlock()
+	if fresh {
+		return
+	}
+
+	now := time.Now()
+
+	var tokens []models.RevokedToken
+	if err := database.DB.Where("expires_at > ?", now).Find(&tokens).Error; err != nil {
+		log.Printf("Failed to sync revoked tokens: %v", err)
+		return
+	}
+
+	var users []models.User
+	if err := database.DB.Select("id", "tokens_invalid_before").
+		Where("tokens_invalid_before > ?", now.Add(-utils.AccessTokenTTL())).
+		Find(&users).Error; err != nil {
+		log.Printf("Failed to sync revoked user tokens: %v", err)
+		return
+	}
+
+	var sessions []models.Session
+	if err := database.DB.Select("id", "revoked_at").
+		Where("revoked_at > ?", now.Add(-utils.AccessTokenTTL())).
+		Find(&sessions).Error; err != nil {
+		log.Printf("Failed to sync revoked sessions: %v", err)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokens = make(map[string]time.Time, len(tokens))
+	for _, t := range tokens {
+		c.tokens[t.JTI] = t.ExpiresAt
+	}
+	c.users = make(map[string]time.Time, len(users))
+	for _, u := range users {
+		c.users[u.ID.String()] = *u.TokensInvalidBefore
+	}
+	c.sessions = make(map[string]time.Time, len(sessions))
+	for _, s := range sessions {
+		c.sessions[s.ID.String()] = *s.RevokedAt
+	}
+	c.syncedAt = now
+}
+
+// IsTokenRevoked reports whether an access token was revoked, either individually
+// (logout), because its session was ended, or because every token of its user was invalidated (logout-all).
+func IsTokenRevoked(claims *utils.Claims) bool {
+	revocations.sync()
+
+	revocations.mu.RLock()
+	defer revocations.mu.RUnlock()
+
+	if _, ok := revocations.tokens[claims.ID]; ok {
+		return true
+	}
+	if claims.SessionID != "" {
+		if _, ok := revocations.sessions[claims.SessionID]; ok {
+			return true
+		}
+	}
+	if cutoff, ok := revocations.users[claims.UserID]; ok {
+		if claims.IssuedAt == nil || !claims.IssuedAt.Time.After(cutoff) {
+			return true
+		}
+	}
+	return false
+}
+
+// RevokeAccessToken stores the token's jti in the revocation store so it is rejected until it expires.
+func RevokeAccessToken(claims *utils.Claims) error {
+	userUUID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return errors.ErrInvalidID("user")
+	}
+
+	expiresAt := time.Now().Add(utils.AccessTokenTTL())
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+
+	revoked := models.RevokedToken{JTI: claims.ID, UserID: userUUID, ExpiresAt: expiresAt}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&revoked).Error; err != nil {
+		return errors.NewInternalServerError("error revoking token")
+	}
+
+	revocations.mu.Lock()
+	revocations.tokens[claims.ID] = expiresAt
+	revocations.mu.Unlock()
+	return nil
+}
+
+// RevokeRefreshToken revokes the family of the given refresh token if it belongs to the user.
+// Unknown tokens are ignored so logout stays idempotent.
+func RevokeRefreshToken(userID string, refreshToken string) error {
+	var token models.RefreshToken
+	if err := database.DB.Where("token_hash = ? AND user_id = ?", utils.HashToken(refreshToken), userID).
+		First(&token).Error; err != nil {
+		return nil
+	}
+	return revokeTokenFamily(database.DB, token.FamilyID)
+}
+
+// RevokeAllUserTokens logs a user out everywhere: every session and refresh token is revoked and
+// every access token issued up to now is rejected.
+func RevokeAllUserTokens(userID string) error {
+	now := time.Now()
+	err := database.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error
+	if err != nil {
+		return errors.NewInternalServerError("error revoking refresh tokens")
+	}
+
+	if err := database.DB.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error; err != nil {
+		return errors.NewInternalServerError("error revoking sessions")
+	}
+
+	return revokeUserAccessTokens(userID)
+}
+
+// revokeUserCredentials logs a user out everywhere like RevokeAllUserTokens and also deletes their
+// personal access tokens, for when their password may be known to someone else or their account is going away.
+func revokeUserCredentials(userID string) error {
+	if err := RevokeAllUserTokens(userID); err != nil {
+		return err
+	}
+
+	if err := database.DB.Where("user_id = ?", userID).Delete(&models.PersonalAccessToken{}).Error; err != nil {
+		return errors.NewInternalServerError("error revoking personal access tokens")
+	}
+	return nil
+}
+
+// revokeUserAccessTokens rejects every access token issued to the user up to now.
+// Refresh tokens stay valid, so clients can obtain new access tokens with up-to-date claims.
+func revokeUserAccessTokens(userID string) error {
+	// Token "iat" claims have millisecond precision, so tokens issued before this call are rejected
+	// even within the same second, while the ones issued right after it stay valid.
+	cutoff := time.Now()
+
+	if err := database.DB.Model(&models.User{}).Where("id = ?", userID).
+		Update("tokens_invalid_before", cutoff).Error; err != nil {
+		return errors.NewInternalServerError("error revoking tokens")
+	}
+
+	revocations.mu.Lock()
+	revocations.users[userID] = cutoff
+	revocations.mu.Unlock()
+	return nil
+}
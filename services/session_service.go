@@ -0,0 +1,209 @@
+package services
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"gorm.io/gorm"
+)
+
+// maxUserAgentLength caps the user agent stored for a session.
+const maxUserAgentLength = 512
+
+// sessionActivityBatchSize caps the number of sessions updated by a single statement.
+const sessionActivityBatchSize = 1000
+
+// sessionActivityFlushInterval returns how often last-seen times are written to the database
+// (SESSION_ACTIVITY_FLUSH_INTERVAL).
+func sessionActivityFlushInterval() time.Duration {
+	return utils.DurationFromEnv("SESSION_ACTIVITY_FLUSH_INTERVAL", time.Minute)
+}
+
+// sessionActivity buffers the last time each session was used, so authenticated requests
+// do not write to the database; the buffer is written in one statement per flush interval.
+type sessionActivity struct {
+	mu        sync.Mutex
+	seen      map[string]time.Time // session ID -> last request
+	flushedAt time.Time
+}
+
+var activity = &sessionActivity{
+	seen:      make(map[string]time.Time),
+	flushedAt: time.Now(),
+}
+
+// TouchSession records that the session was just used. It is called by the auth middleware
+// on every request made with an access token.
+func TouchSession(sessionID string) {
+	now := time.Now()
+
+	activity.mu.Lock()
+	activity.seen[sessionID] = now
+	var batch map[string]time.Time
+	if now.Sub(activity.flushedAt) >= sessionActivityFlushInterval() {
+		batch = activity.take(now)
+	}
+	activity.mu.Unlock()
+
+	if batch != nil {
+		go writeSessionActivity(batch)
+	}
+}
+
+// FlushSessionActivity writes the buffered last-seen times right away, e.g. before shutdown.
+func FlushSessionActivity() {
+	activity.mu.Lock()
+	batch := activity.take(time.Now())
+	activity.mu.Unlock()
+
+	writeSessionActivity(batch)
+}
+
+// take empties the buffer and returns its contents. The caller must hold the lock.
+func (a *sessionActivity) take(now time.Time) map[string]time.Time {
+	batch := a.seen
+	a.seen = make(map[string]time.Time)
+	a.flushedAt = now
+	return batch
+}
+
+// lastSeen returns the buffered last-seen time of a session, if it was used since the last flush.
+func (a *sessionActivity) lastSeen(sessionID string) (time.Time, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	seenAt, ok := a.seen[sessionID]
+	return seenAt, ok
+}
+
+// writeSessionActivity stores the given last-seen times, never moving a session's time backwards.
+func writeSessionActivity(batch map[string]time.Time) {
+	values := make([]string, 0, sessionActivityBatchSize)
+	args := make([]interface{}, 0, 2*sessionActivityBatchSize)
+
+	write := func() {
+		if len(values) == 0 {
+			return
+		}
+		err := database.DB.Exec(
+			"UPDATE sessions SET last_seen_at = v.seen_at FROM (VALUES "+strings.Join(values, ", ")+") AS v(id, seen_at) "+
+				"WHERE sessions.id = v.id AND sessions.last_seen_at < v.seen_at",
+			args...,
+		).Error
+		if err != nil {
+			log.Printf("Failed to record session activity: %v", err)
+		}
+		values, args = values[:0], args[:0]
+	}
+
+	for sessionID, seenAt := range batch {
+		values = append(values, "(?::uuid, ?::timestamp)")
+		args = append(args, sessionID, seenAt)
+		if len(values) == sessionActivityBatchSize {
+			write()
+		}
+	}
+	write()
+}
+
+// startSession records a new login. Its ID is the family ID of the refresh tokens issued for it.
+func startSession(tx *gorm.DB, userID uuid.UUID, sessionID uuid.UUID, client utils.ClientInfo, expiresAt time.Time) error {
+	session := models.Session{
+		ID:         sessionID,
+		UserID:     userID,
+		UserAgent:  truncateUserAgent(client.UserAgent),
+		IPAddress:  client.IP,
+		LastSeenAt: time.Now(),
+		ExpiresAt:  expiresAt,
+	}
+	if err := tx.Create(&session).Error; err != nil {
+		return errors.NewInternalServerError("error storing session")
+	}
+	return nil
+}
+
+// renewSession records a token refresh: the client details are updated, since the device
+// may have moved networks or upgraded its browser, and the session lives as long as its newest refresh token.
+func renewSession(tx *gorm.DB, sessionID uuid.UUID, client utils.ClientInfo, expiresAt time.Time) error {
+	err := tx.Model(&models.Session{}).Where("id = ?", sessionID).Updates(map[string]interface{}{
+		"user_agent":   truncateUserAgent(client.UserAgent),
+		"ip_address":   client.IP,
+		"last_seen_at": time.Now(),
+		"expires_at":   expiresAt,
+	}).Error
+	if err != nil {
+		return errors.NewInternalServerError("error updating session")
+	}
+	return nil
+}
+
+// ListSessions returns the user's active sessions, most recently used first.
+// The session of the access token used for the request is flagged as current.
+func ListSessions(userID string, currentSessionID string) ([]dto.SessionResponse, error) {
+	var sessions []models.Session
+	if err := database.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Find(&sessions).Error; err != nil {
+		return nil, errors.NewInternalServerError("error retrieving sessions")
+	}
+
+	resp := make([]dto.SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		lastSeenAt := s.LastSeenAt
+		if seenAt, ok := activity.lastSeen(s.ID.String()); ok && seenAt.After(lastSeenAt) {
+			lastSeenAt = seenAt
+		}
+		resp = append(resp, dto.SessionResponse{
+			ID:         s.ID.String(),
+			UserAgent:  s.UserAgent,
+			IPAddress:  s.IPAddress,
+			Current:    s.ID.String() == currentSessionID,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: lastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+		})
+	}
+
+	// Buffered activity may be newer than the stored times, so sort after merging it
+	sort.Slice(resp, func(i, j int) bool { return resp[i].LastSeenAt.After(resp[j].LastSeenAt) })
+	return resp, nil
+}
+
+// RevokeSession logs the user out of one of their sessions: its refresh tokens are revoked
+// and its access tokens are rejected from now on.
+func RevokeSession(userID string, sessionID string) error {
+	sessionUUID, err := uuid.Parse(sessionID)
+	if err != nil {
+		return errors.ErrInvalidID("session")
+	}
+
+	var session models.Session
+	if err := database.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionUUID, userID).
+		First(&session).Error; err != nil {
+		return errors.ErrNotFound("session")
+	}
+
+	if err := revokeTokenFamily(database.DB, session.ID); err != nil {
+		return err
+	}
+
+	revocations.mu.Lock()
+	revocations.sessions[session.ID.String()] = time.Now()
+	revocations.mu.Unlock()
+	return nil
+}
+
+// truncateUserAgent shortens overly long user agents to maxUserAgentLength bytes.
+func truncateUserAgent(userAgent string) string {
+	if len(userAgent) <= maxUserAgentLength {
+		return userAgent
+	}
+	return strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+}
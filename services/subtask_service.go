@@ -0,0 +1,183 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"gorm.io/gorm"
+)
+
+// taskHierarchyLock is the advisory lock serializing changes of task parents, so that two
+// concurrent moves cannot together create a cycle or exceed the maximum depth.
+const taskHierarchyLock = 7316520
+
+// maxTaskDepth returns how many levels deep tasks may be nested (TASK_MAX_DEPTH);
+// 1 disables subtasks, and the default of 3 allows sub-subtasks.
+func maxTaskDepth() int {
+	return utils.IntFromEnv("TASK_MAX_DEPTH", 3)
+}
+
+// ListSubtasks returns the direct subtasks of a task the user can read, by due date.
+// Only the subtasks the user can see themselves are listed.
+func ListSubtasks(taskID string, userID string, role string) ([]models.Task, error) {
+	parent, err := findReadableTask(database.DB, taskID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	query := database.DB.Where("parent_id = ?", parent.ID)
+	if role != models.RoleAdmin {
+		query = query.Where("creator_id = ? OR assignee_id = ?", userID, userID)
+	}
+
+	var subtasks []models.Task
+	if err := query.Order("due_date, id").Find(&subtasks).Error; err != nil {
+		return nil, errors.NewInternalServerError("error retrieving subtasks")
+	}
+
+	page := make([]*models.Task, 0, len(subtasks))
+	for i := range subtasks {
+		page = append(page, &subtasks[i])
+	}
+	if err := loadTaskDetails(userID, page...); err != nil {
+		return nil, err
+	}
+	return subtasks, nil
+}
+
+// setTaskParent makes the task a subtask of the task with ID parentID, or a top-level task
+// when parentID is empty. The parent must be visible to the user, must not be the task itself
+// or one of its subtasks, and the task's own subtasks must stay within the maximum depth.
+func setTaskParent(tx *gorm.DB, task *models.Task, parentID string, userID string, role string) error {
+	if parentID == "" {
+		task.ParentID = nil
+		return nil
+	}
+
+	if _, err := uuid.Parse(parentID); err != nil {
+		return errors.NewValidationError("invalid parent task ID")
+	}
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", taskHierarchyLock).Error; err != nil {
+		return errors.NewInternalServerError("database error")
+	}
+
+	parent, err := findVisibleTask(tx, parentID, userID, role)
+	if err != nil {
+		return errors.NewValidationError("parent task not found")
+	}
+
+	ancestors, err := taskAncestors(tx, parent.ID)
+	if err != nil {
+		return err
+	}
+	for _, id := range ancestors {
+		if id == task.ID {
+			return errors.NewValidationError("a task cannot be a subtask of itself or of its own subtasks")
+		}
+	}
+
+	height, err := subtaskHeight(tx, task.ID)
+	if err != nil {
+		return err
+	}
+	if len(ancestors)+height > maxTaskDepth() {
+		return errors.NewValidationError(fmt.Sprintf("subtasks can be nested at most %d levels deep", maxTaskDepth()))
+	}
+
+	task.ParentID = &parent.ID
+	return nil
+}
+
+// taskAncestors returns the IDs of the task and its ancestors, from the task up to its top-level task.
+func taskAncestors(tx *gorm.DB, taskID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := tx.Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id, 1 AS depth FROM tasks WHERE id = ?
+			UNION ALL
+			SELECT tasks.id, tasks.parent_id, ancestors.depth + 1
+			FROM tasks JOIN ancestors ON tasks.id = ancestors.parent_id
+		)
+		SELECT id FROM ancestors ORDER BY depth`, taskID).Scan(&ids).Error
+	if err != nil {
+		return nil, errors.NewInternalServerError("error retrieving parent tasks")
+	}
+	return ids, nil
+}
+
+// subtaskHeight returns how many levels the task and its subtasks span: 1 for a task without subtasks.
+// New tasks have no subtasks yet.
+func subtaskHeight(tx *gorm.DB, taskID uuid.UUID) (int, error) {
+	var height int
+	err := tx.Raw(`
+		WITH RECURSIVE descendants AS (
+			SELECT id, 1 AS level FROM tasks WHERE id = ?
+			UNION ALL
+			SELECT tasks.id, descendants.level + 1
+			FROM tasks JOIN descendants ON tasks.parent_id = descendants.id
+		)
+		SELECT COALESCE(MAX(level), 1) FROM descendants`, taskID).Scan(&height).Error
+	if err != nil {
+		return 0, errors.NewInternalServerError("error retrieving subtasks")
+	}
+	return height, nil
+}
+
+// checkSubtasksComplete returns a ConflictError if some direct subtasks of the task are not complete.
+func checkSubtasksComplete(tx *gorm.DB, taskID uuid.UUID) error {
+	var open int64
+	if err := tx.Model(&models.Task{}).Where("parent_id = ? AND status <> ?", taskID, models.TaskStatusComplete).
+		Count(&open).Error; err != nil {
+		return errors.NewInternalServerError("error retrieving subtasks")
+	}
+	if open > 0 {
+		return errors.NewConflictError(fmt.Sprintf("task has %d open subtasks; set force to complete it anyway", open))
+	}
+	return nil
+}
+
+// loadTaskProgress fills in how many direct subtasks and checklist items of the tasks are done.
+func loadTaskProgress(tasks ...*models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	byID := make(map[uuid.UUID]*models.Task, len(tasks))
+	ids := make([]uuid.UUID, 0, len(tasks))
+	for _, t := range tasks {
+		t.SubtaskProgress, t.ChecklistProgress = models.TaskProgress{}, models.TaskProgress{}
+		byID[t.ID] = t
+		ids = append(ids, t.ID)
+	}
+
+	type progressRow struct {
+		TaskID uuid.UUID
+		Done   int
+		Total  int
+	}
+
+	var subtasks []progressRow
+	if err := database.DB.Model(&models.Task{}).
+		Select("parent_id AS task_id, COUNT(*) FILTER (WHERE status = ?) AS done, COUNT(*) AS total", models.TaskStatusComplete).
+		Where("parent_id IN ?", ids).Group("parent_id").Scan(&subtasks).Error; err != nil {
+		return errors.NewInternalServerError("error retrieving subtasks")
+	}
+	for _, row := range subtasks {
+		byID[row.TaskID].SubtaskProgress = models.TaskProgress{Done: row.Done, Total: row.Total}
+	}
+
+	var checklists []progressRow
+	if err := database.DB.Model(&models.ChecklistItem{}).
+		Select("task_id, COUNT(*) FILTER (WHERE done) AS done, COUNT(*) AS total").
+		Where("task_id IN ?", ids).Group("task_id").Scan(&checklists).Error; err != nil {
+		return errors.NewInternalServerError("error retrieving checklists")
+	}
+	for _, row := range checklists {
+		byID[row.TaskID].ChecklistProgress = models.TaskProgress{Done: row.Done, Total: row.Total}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"gorm.io/gorm"
+)
+
+// taskEventCursor is the position of the last event of a history page.
+type taskEventCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// ListTaskHistory returns one page of a task's events, newest first. The history of a deleted
+// task can only be read by admins.
+func ListTaskHistory(taskID string, userID string, role string, cursor string, limit int) ([]models.TaskEvent, string, error) {
+	taskUUID, err := uuid.Parse(taskID)
+	if err != nil {
+		return nil, "", errors.ErrNotFound("task")
+	}
+	if _, err := findReadableTask(database.DB, taskID, userID, role); err != nil {
+		if role != models.RoleAdmin {
+			return nil, "", err
+		}
+		var count int64
+		if err := database.DB.Model(&models.TaskEvent{}).Where("task_id = ?", taskUUID).Count(&count).Error; err != nil {
+			return nil, "", errors.NewInternalServerError("error retrieving task history")
+		}
+		if count == 0 {
+			return nil, "", errors.ErrNotFound("task")
+		}
+	}
+
+	query := database.DB.Where("task_id = ?", taskUUID)
+	if cursor != "" {
+		var after taskEventCursor
+		if err := utils.DecodeCursor(cursor, &after); err != nil {
+			return nil, "", err
+		}
+		query = query.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	var events []models.TaskEvent
+	if err := query.Order("created_at DESC, id DESC").Limit(limit + 1).Find(&events).Error; err != nil {
+		return nil, "", errors.NewInternalServerError("error retrieving task history")
+	}
+	more := len(events) > limit
+	if more {
+		events = events[:limit]
+	}
+
+	if err := loadTaskEventActors(events); err != nil {
+		return nil, "", err
+	}
+	if !more {
+		return events, "", nil
+	}
+
+	last := events[len(events)-1]
+	next, err := utils.EncodeCursor(taskEventCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	if err != nil {
+		return nil, "", errors.NewInternalServerError("error encoding cursor")
+	}
+	return events, next, nil
+}
+
+// recordTaskEvent appends an event for a change to a task within tx. before is nil for created
+// tasks and after for deleted ones; updates that change no tracked field are not recorded.
+func recordTaskEvent(tx *gorm.DB, actorID *uuid.UUID, before *models.Task, after *models.Task) error {
+	event := models.TaskEvent{
+		ID:        uuid.New(),
+		ActorID:   actorID,
+		Changes:   diffTasks(before, after),
+		CreatedAt: time.Now(),
+	}
+	switch {
+	case before == nil:
+		event.TaskID, event.Action = after.ID, models.TaskEventCreated
+	case after == nil:
+		event.TaskID, event.Action = before.ID, models.TaskEventDeleted
+	default:
+		event.TaskID, event.Action = after.ID, models.TaskEventUpdated
+		if len(event.Changes) == 0 {
+			return nil
+		}
+	}
+
+	if err := tx.Create(&event).Error; err != nil {
+		return errors.NewInternalServerError("error recording task history")
+	}
+	return nil
+}
+
+// diffTasks returns the tracked fields that differ between two versions of a task, either of
+// which may be nil.
+func diffTasks(before *models.Task, after *models.Task) models.TaskChanges {
+	oldFields, newFields := taskFields(before), taskFields(after)
+
+	changes := models.TaskChanges{}
+	for name := range taskFields(&models.Task{}) {
+		if oldFields[name] != newFields[name] {
+			changes[name] = models.FieldChange{Before: oldFields[name], After: newFields[name]}
+		}
+	}
+	return changes
+}
+
+// taskFields returns the tracked fields of a task, as they appear in task responses.
+func taskFields(task *models.Task) map[string]interface{} {
+	if task == nil {
+		return map[string]interface{}{}
+	}
+
+	var parentID interface{}
+	if task.ParentID != nil {
+		parentID = task.ParentID.String()
+	}
+	return map[string]interface{}{
+		"title":       task.Title,
+		"description": task.Description,
+		"due_date":    task.DueDate.UTC().Format(time.RFC3339),
+		"priority":    task.Priority,
+		"status":      task.Status,
+		"creator_id":  task.CreatorID.String(),
+		"assignee_id": task.AssigneeID.String(),
+		"parent_id":   parentID,
+	}
+}
+
+// loadTaskEventActors fills in the users who made the changes.
+func loadTaskEventActors(events []models.TaskEvent) error {
+	actorIDs := make([]uuid.UUID, 0, len(events))
+	for _, e := range events {
+		if e.ActorID != nil {
+			actorIDs = append(actorIDs, *e.ActorID)
+		}
+	}
+	if len(actorIDs) == 0 {
+		return nil
+	}
+
+	var actors []models.User
+	if err := database.DB.Where("id IN ?", actorIDs).Find(&actors).Error; err != nil {
+		return errors.NewInternalServerError("error retrieving task history")
+	}
+	actorsByID := make(map[uuid.UUID]models.User, len(actors))
+	for _, u := range actors {
+		actorsByID[u.ID] = u
+	}
+
+	for i := range events {
+		e := &events[i]
+		if e.ActorID != nil {
+			if actor, ok := actorsByID[*e.ActorID]; ok {
+				e.Actor = &actor
+			}
+		}
+	}
+	return nil
+}
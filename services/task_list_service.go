@@ -0,0 +1,368 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"gorm.io/gorm"
+)
+
+// defaultTaskSort is the order of task listings when none is requested.
+const defaultTaskSort = "due_date"
+
+// taskSortField is a field task listings can be sorted by.
+type taskSortField struct {
+	expr   string                                     // SQL expression sorted on
+	value  func(TaskMatch) interface{}                // value of the expression for a task, stored in cursors
+	decode func(json.RawMessage) (interface{}, error) // parses a value stored in a cursor
+	search bool                                       // only available to full-text searches
+}
+
+// taskSortFields whitelists the sortable fields. Priority and status sort by rank
+// (low < medium < high, pending < in_progress < complete) rather than alphabetically,
+// and "rank" sorts search results by relevance.
+var taskSortFields = map[string]taskSortField{
+	"due_date":   {expr: "tasks.due_date", value: func(t TaskMatch) interface{} { return t.DueDate }, decode: decodeTimeValue},
+	"created_at": {expr: "tasks.created_at", value: func(t TaskMatch) interface{} { return t.CreatedAt }, decode: decodeTimeValue},
+	"updated_at": {expr: "tasks.updated_at", value: func(t TaskMatch) interface{} { return t.UpdatedAt }, decode: decodeTimeValue},
+	"title":      {expr: "tasks.title", value: func(t TaskMatch) interface{} { return t.Title }, decode: decodeStringValue},
+	"priority": {
+		expr:   rankExpr("tasks.priority", models.TaskPriorities),
+		value:  func(t TaskMatch) interface{} { return rankOf(models.TaskPriorities, t.Priority) },
+		decode: decodeIntValue,
+	},
+	"status": {
+		expr:   rankExpr("tasks.status", models.TaskStatuses),
+		value:  func(t TaskMatch) interface{} { return rankOf(models.TaskStatuses, t.Status) },
+		decode: decodeIntValue,
+	},
+	"rank": {
+		expr:   taskRankExpr,
+		value:  func(t TaskMatch) interface{} { return t.Rank },
+		decode: decodeFloatValue,
+		search: true,
+	},
+}
+
+// taskSortKey is one field of a requested sort order.
+type taskSortKey struct {
+	field taskSortField
+	desc  bool
+}
+
+// taskCursor is the position of a task in a listing: the sort values and ID of the last task of a page.
+// It records the sort order it was issued for, since its values are meaningless under another one.
+type taskCursor struct {
+	Sort   string            `json:"sort"`
+	Values []json.RawMessage `json:"values"`
+	ID     uuid.UUID         `json:"id"`
+}
+
+// GetTasks returns one page of the tasks the user created or is assigned to, filtered and sorted
+// as requested, together with the cursor of the next page (empty on the last page).
+// Pages are keyset-paginated over the sort fields followed by the task ID, so tasks created or
+// deleted while a client pages through the list never cause rows to be skipped or repeated.
+// The query is expected to have been validated, except for the sort order and search terms.
+func GetTasks(userID string, q dto.TaskListQuery) ([]models.Task, string, error) {
+	matches, next, err := listTasks(userID, q, false)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tasks := make([]models.Task, 0, len(matches))
+	for _, m := range matches {
+		tasks = append(tasks, m.Task)
+	}
+	return tasks, next, nil
+}
+
+// listTasks returns one page of the user's tasks matching q, as described by GetTasks.
+// Search matches are ranked and, when highlight is set, come with highlighted snippets.
+func listTasks(userID string, q dto.TaskListQuery, highlight bool) ([]TaskMatch, string, error) {
+	keys, sortSpec, err := parseTaskSort(q.Sort, q.Search != "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	query, err := filterTasks(database.DB, userID, q)
+	if err != nil {
+		return nil, "", err
+	}
+	if q.Search != "" {
+		query = selectSearchColumns(query, highlight)
+	}
+
+	if q.Cursor != "" {
+		var after taskCursor
+		if err := utils.DecodeCursor(q.Cursor, &after); err != nil {
+			return nil, "", err
+		}
+		if after.Sort != sortSpec || len(after.Values) != len(keys) {
+			return nil, "", errors.NewValidationError("cursor does not match the sort order")
+		}
+
+		condition, args, err := keysetCondition(keys, after)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where(condition, args...)
+	}
+
+	for _, key := range keys {
+		direction := " ASC"
+		if key.desc {
+			direction = " DESC"
+		}
+		query = query.Order(key.field.expr + direction)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	var tasks []TaskMatch
+	if err := query.Order("tasks.id ASC").Limit(q.Limit + 1).Scan(&tasks).Error; err != nil {
+		return nil, "", err
+	}
+	if highlight {
+		for i := range tasks {
+			tasks[i].TitleHighlight = renderHighlight(tasks[i].TitleHighlight)
+			tasks[i].DescriptionHighlight = renderHighlight(tasks[i].DescriptionHighlight)
+		}
+	}
+	more := len(tasks) > q.Limit
+	if more {
+		tasks = tasks[:q.Limit]
+	}
+
+	page := make([]*models.Task, 0, len(tasks))
+	for i := range tasks {
+		page = append(page, &tasks[i].Task)
+	}
+	if err := loadTaskDetails(userID, page...); err != nil {
+		return nil, "", err
+	}
+	if !more {
+		return tasks, "", nil
+	}
+
+	last := tasks[len(tasks)-1]
+	cursor := taskCursor{Sort: sortSpec, Values: make([]json.RawMessage, len(keys)), ID: last.ID}
+	for i, key := range keys {
+		if cursor.Values[i], err = json.Marshal(key.field.value(last)); err != nil {
+			return nil, "", errors.NewInternalServerError("error encoding cursor")
+		}
+	}
+
+	next, err := utils.EncodeCursor(cursor)
+	if err != nil {
+		return nil, "", errors.NewInternalServerError("error encoding cursor")
+	}
+	return tasks, next, nil
+}
+
+// filterTasks scopes a tasks query to the user's tasks matching the filters and search terms of q.
+func filterTasks(db *gorm.DB, userID string, q dto.TaskListQuery) (*gorm.DB, error) {
+	query := db.Model(&models.Task{})
+
+	if q.Search != "" {
+		tsquery, args, err := parseTaskSearch(q.Search)
+		if err != nil {
+			return nil, err
+		}
+		// The parsed query is computed once and referenced as search.query
+		query = query.Joins("CROSS JOIN (SELECT "+tsquery+" AS query) AS search", args...).
+			Where("tasks.search_vector @@ search.query")
+	}
+
+	switch q.Role {
+	case dto.TaskRoleCreator:
+		query = query.Where("tasks.creator_id = ?", userID)
+	case dto.TaskRoleAssignee:
+		query = query.Where("tasks.assignee_id = ?", userID)
+	default:
+		query = query.Where("tasks.creator_id = ? OR tasks.assignee_id = ?", userID, userID)
+	}
+
+	if len(q.Statuses) > 0 {
+		query = query.Where("tasks.status IN ?", q.Statuses)
+	}
+	if len(q.Priorities) > 0 {
+		query = query.Where("tasks.priority IN ?", q.Priorities)
+	}
+	if q.AssigneeID != "" {
+		query = query.Where("tasks.assignee_id = ?", q.AssigneeID)
+	}
+	if len(q.Labels) > 0 {
+		query = filterTaskLabels(query, userID, q.Labels, q.LabelMatch)
+	}
+	if q.DueAfter != nil {
+		query = query.Where("tasks.due_date >= ?", q.DueAfter.UTC())
+	}
+	if q.DueBefore != nil {
+		query = query.Where("tasks.due_date < ?", q.DueBefore.UTC())
+	}
+	if q.CreatedAfter != nil {
+		query = query.Where("tasks.created_at >= ?", q.CreatedAfter.UTC())
+	}
+	if q.Overdue != nil {
+		now := time.Now().UTC()
+		if *q.Overdue {
+			query = query.Where("tasks.due_date < ? AND tasks.status <> ?", now, models.TaskStatusComplete)
+		} else {
+			query = query.Where("tasks.due_date >= ? OR tasks.status = ?", now, models.TaskStatusComplete)
+		}
+	}
+	return query, nil
+}
+
+// filterTaskLabels keeps the tasks carrying any of the labels or, with dto.TaskLabelMatchAll, all of them.
+// Only labels visible to the user count, so other users' personal labels never match.
+func filterTaskLabels(query *gorm.DB, userID string, labelIDs []string, match string) *gorm.DB {
+	unique := make([]uuid.UUID, 0, len(labelIDs))
+	seen := map[uuid.UUID]bool{}
+	for _, raw := range labelIDs {
+		// The IDs were validated with the rest of the query
+		if id, err := uuid.Parse(raw); err == nil && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	labeled := "SELECT task_labels.task_id FROM task_labels JOIN labels ON labels.id = task_labels.label_id " +
+		"WHERE task_labels.label_id IN ? AND (labels.owner_id IS NULL OR labels.owner_id = ?)"
+	if match == dto.TaskLabelMatchAll {
+		return query.Where("tasks.id IN ("+labeled+" GROUP BY task_labels.task_id HAVING COUNT(*) = ?)", unique, userID, len(unique))
+	}
+	return query.Where("tasks.id IN ("+labeled+")", unique, userID)
+}
+
+// parseTaskSort parses a sort order such as "-priority,due_date" into its keys.
+// It also returns the order in canonical form, which cursors are tied to.
+// Sorting by relevance is only possible when searching.
+func parseTaskSort(spec string, searching bool) ([]taskSortKey, string, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		spec = defaultTaskSort
+	}
+
+	var keys []taskSortKey
+	var names []string
+	used := map[string]bool{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		name := strings.TrimPrefix(item, "-")
+
+		field, ok := taskSortFields[name]
+		if !ok {
+			return nil, "", errors.NewValidationError(fmt.Sprintf("cannot sort by %q; sortable fields are: %s", name, sortableTaskFields()))
+		}
+		if field.search && !searching {
+			return nil, "", errors.NewValidationError(fmt.Sprintf("sorting by %q requires search terms (q)", name))
+		}
+		if used[name] {
+			return nil, "", errors.NewValidationError(fmt.Sprintf("cannot sort by %q twice", name))
+		}
+		used[name] = true
+
+		keys = append(keys, taskSortKey{field: field, desc: item != name})
+		names = append(names, item)
+	}
+	return keys, strings.Join(names, ","), nil
+}
+
+// keysetCondition builds the condition selecting the tasks that come after the cursor:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... OR (k1 = v1 AND ... AND id > cursor ID),
+// with < instead of > for descending keys.
+func keysetCondition(keys []taskSortKey, after taskCursor) (string, []interface{}, error) {
+	exprs := make([]string, 0, len(keys)+1)
+	ops := make([]string, 0, len(keys)+1)
+	values := make([]interface{}, 0, len(keys)+1)
+	for i, key := range keys {
+		value, err := key.field.decode(after.Values[i])
+		if err != nil {
+			return "", nil, errors.NewValidationError("invalid cursor")
+		}
+		op := ">"
+		if key.desc {
+			op = "<"
+		}
+		exprs, ops, values = append(exprs, key.field.expr), append(ops, op), append(values, value)
+	}
+	exprs, ops, values = append(exprs, "tasks.id"), append(ops, ">"), append(values, after.ID)
+
+	var branches []string
+	var args []interface{}
+	for i := range exprs {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, exprs[j]+" = ?")
+			args = append(args, values[j])
+		}
+		terms = append(terms, exprs[i]+" "+ops[i]+" ?")
+		args = append(args, values[i])
+		branches = append(branches, "("+strings.Join(terms, " AND ")+")")
+	}
+	return "(" + strings.Join(branches, " OR ") + ")", args, nil
+}
+
+// sortableTaskFields lists the names of the sortable fields.
+func sortableTaskFields() string {
+	names := make([]string, 0, len(taskSortFields))
+	for name := range taskSortFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// rankExpr returns a SQL expression mapping the column's values to their position in the given order.
+// The values are constants, never user input.
+func rankExpr(column string, ordered []string) string {
+	expr := "CASE " + column
+	for i, value := range ordered {
+		expr += fmt.Sprintf(" WHEN '%s' THEN %d", value, i)
+	}
+	return expr + " END"
+}
+
+// rankOf returns the position of value in the given order, as computed by rankExpr.
+func rankOf(ordered []string, value string) int {
+	for i, v := range ordered {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// decodeTimeValue, decodeFloatValue, decodeIntValue and decodeStringValue parse sort values stored in cursors.
+func decodeTimeValue(raw json.RawMessage) (interface{}, error) {
+	var v time.Time
+	err := json.Unmarshal(raw, &v)
+	return v, err
+}
+
+func decodeFloatValue(raw json.RawMessage) (interface{}, error) {
+	var v float64
+	err := json.Unmarshal(raw, &v)
+	return v, err
+}
+
+func decodeIntValue(raw json.RawMessage) (interface{}, error) {
+	var v int
+	err := json.Unmarshal(raw, &v)
+	return v, err
+}
+
+func decodeStringValue(raw json.RawMessage) (interface{}, error) {
+	var v string
+	err := json.Unmarshal(raw, &v)
+	return v, err
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"gorm.io/gorm"
+)
+
+// maxSearchLength caps the length of full-text search terms.
+const maxSearchLength = 200
+
+// taskRankExpr scores how well a task matches the search; title matches weigh more than description matches.
+const taskRankExpr = "ts_rank_cd(tasks.search_vector, search.query)"
+
+// Highlighted matches are delimited by private-use characters, which are replaced with <mark> tags
+// once the rest of the snippet has been HTML-escaped.
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
+
+// Options of ts_headline for titles, which are shown whole, and descriptions, which are cut to their best fragments.
+var (
+	highlightSelectors          = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `"`
+	titleHighlightOptions       = highlightSelectors + ", HighlightAll=true"
+	descriptionHighlightOptions = highlightSelectors + `, MaxFragments=2, MaxWords=30, MinWords=10, FragmentDelimiter=" … "`
+)
+
+// TaskMatch is a task returned by a listing. For full-text searches it also carries
+// its relevance and, when requested, highlighted snippets of its title and description.
+type TaskMatch struct {
+	models.Task          `gorm:"embedded"`
+	Rank                 float64
+	TitleHighlight       string
+	DescriptionHighlight string
+}
+
+// SearchTasks returns one page of the user's tasks matching the search terms of q, most relevant
+// first unless another sort order is requested, with highlighted snippets. The other filters of q apply too.
+//
+// Terms are matched against titles and descriptions after stemming, so "documents" finds "documentation".
+// Quoted terms match as a phrase and terms ending with * match as a prefix, e.g. `"release notes" deploy*`.
+func SearchTasks(userID string, q dto.TaskListQuery) ([]TaskMatch, string, error) {
+	if strings.TrimSpace(q.Search) == "" {
+		return nil, "", errors.NewValidationError("q is required")
+	}
+	if q.Sort == "" {
+		q.Sort = "-rank"
+	}
+	return listTasks(userID, q, true)
+}
+
+// selectSearchColumns adds the relevance and, if highlight is set, the highlighted snippets to a search query.
+func selectSearchColumns(query *gorm.DB, highlight bool) *gorm.DB {
+	if !highlight {
+		return query.Select("tasks.*, " + taskRankExpr + " AS rank")
+	}
+	return query.Select(
+		"tasks.*, "+taskRankExpr+" AS rank, "+
+			"ts_headline('english', tasks.title, search.query, ?) AS title_highlight, "+
+			"ts_headline('english', tasks.description, search.query, ?) AS description_highlight",
+		titleHighlightOptions, descriptionHighlightOptions,
+	)
+}
+
+// parseTaskSearch turns search terms into a SQL tsquery expression and its arguments.
+// Plain words must all match, "quoted phrases" must match as a phrase and words ending with *
+// match any word starting with them. User input only reaches the database as arguments.
+func parseTaskSearch(input string) (string, []interface{}, error) {
+	if len(input) > maxSearchLength {
+		return "", nil, errors.NewValidationError("q must be at most 200 characters")
+	}
+
+	var parts []string
+	var args []interface{}
+	var words []string
+
+	rest := input
+	for {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			break
+		}
+
+		// "a quoted phrase"; an unterminated quote runs to the end of the input
+		if rest[0] == '"' {
+			phrase := rest[1:]
+			rest = ""
+			if end := strings.IndexByte(phrase, '"'); end >= 0 {
+				phrase, rest = phrase[:end], phrase[end+1:]
+			}
+			if strings.TrimSpace(phrase) != "" {
+				parts = append(parts, "phraseto_tsquery('english', ?)")
+				args = append(args, phrase)
+			}
+			continue
+		}
+
+		end := strings.IndexFunc(rest, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+		if end < 0 {
+			end = len(rest)
+		}
+		word := rest[:end]
+		rest = rest[end:]
+
+		if !strings.HasSuffix(word, "*") {
+			words = append(words, word)
+			continue
+		}
+
+		// prefix*: only letters and digits reach to_tsquery, so the input cannot break its syntax
+		lexemes := strings.FieldsFunc(strings.TrimRight(word, "*"), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(lexemes) == 0 {
+			continue
+		}
+		lexemes[len(lexemes)-1] += ":*"
+		parts = append(parts, "to_tsquery('english', ?)")
+		args = append(args, strings.Join(lexemes, " & "))
+	}
+
+	if len(words) > 0 {
+		parts = append(parts, "plainto_tsquery('english', ?)")
+		args = append(args, strings.Join(words, " "))
+	}
+	if len(parts) == 0 {
+		return "", nil, errors.NewValidationError("q must contain at least one word")
+	}
+	return "(" + strings.Join(parts, " && ") + ")", args, nil
+}
+
+// renderHighlight escapes a snippet produced by ts_headline and marks its matches with <mark> tags.
+func renderHighlight(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, highlightStart, "<mark>")
+	return strings.ReplaceAll(escaped, highlightStop, "</mark>")
+}
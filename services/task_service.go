@@ -1,6 +1,7 @@
 package services
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,9 +9,13 @@ import (
 	"github.com/kfeuerschvenger/task-manager-api/dto"
 	"github.com/kfeuerschvenger/task-manager-api/errors"
 	"github.com/kfeuerschvenger/task-manager-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func CreateTask(input dto.CreateTaskInput, creatorID string) (models.Task, error) {
+// CreateTask creates a task, or a subtask of a task visible to the creator when input.ParentID is set.
+// Users mentioned in the description are notified.
+func CreateTask(input dto.CreateTaskInput, creatorID string, role string) (models.Task, error) {
 	creatorUUID, err := uuid.Parse(creatorID)
 	if err != nil {
 		return models.Task{}, errors.ErrInvalidID("user")
@@ -21,6 +26,14 @@ func CreateTask(input dto.CreateTaskInput, creatorID string) (models.Task, error
 		return models.Task{}, errors.ErrInvalidID("assignee")
 	}
 
+	// Enforce the email verification policy
+	if err := checkCreatorVerified(creatorID); err != nil {
+		return models.Task{}, err
+	}
+	if err := checkAssigneeVerified(creatorID, assigneeUUID); err != nil {
+		return models.Task{}, err
+	}
+
 	task := models.Task{
 		ID:          uuid.New(),
 		Title:       input.Title,
@@ -34,28 +47,61 @@ func CreateTask(input dto.CreateTaskInput, creatorID string) (models.Task, error
 		UpdatedAt:   time.Now(),
 	}
 
-	err = database.DB.Create(&task).Error
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if input.ParentID != "" {
+			if err := setTaskParent(tx, &task, input.ParentID, creatorID, role); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&task).Error; err != nil {
+			return err
+		}
+		if err := recordTaskEvent(tx, &creatorUUID, nil, &task); err != nil {
+			return err
+		}
+		return notifyMentions(tx, creatorUUID, models.NotificationTaskMention, task.ID, nil, task.Description, "")
+	})
 	return task, err
 }
 
-func GetTasks(userID, status, priority string) ([]models.Task, error) {
-	var tasks []models.Task
+// GetTaskByID returns a task visible to the user: one they created or are assigned to, or
+// were mentioned in. Admins can see every task.
+func GetTaskByID(taskID string, userID string, role string) (*models.Task, error) {
+	task, err := findReadableTask(database.DB, taskID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadTaskDetails(userID, task); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
 
-	query := database.DB.
-		Where("creator_id = ? OR assignee_id = ?", userID, userID)
+// findVisibleTask loads a task the user created or is assigned to, or any task for admins.
+func findVisibleTask(db *gorm.DB, taskID string, userID string, role string) (*models.Task, error) {
+	var task models.Task
 
-	if status != "" {
-		query = query.Where("status = ?", status)
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.ErrInvalidID("user")
 	}
-	if priority != "" {
-		query = query.Where("priority = ?", priority)
+
+	query := db.Where("id = ?", taskID)
+	if role != models.RoleAdmin {
+		query = query.Where("creator_id = ? OR assignee_id = ?", userUUID, userUUID)
+	}
+
+	if err := query.First(&task).Error; err != nil {
+		return nil, errors.ErrNotFound("task")
 	}
 
-	err := query.Order("due_date ASC").Find(&tasks).Error
-	return tasks, err
+	return &task, nil
 }
 
-func GetTaskByID(taskID string, userID string) (*models.Task, error) {
+// findReadableTask loads a task visible to the user, or one they were mentioned in.
+// Mentioned users can read the task and its comments, checklist and subtasks, but not change them.
+func findReadableTask(db *gorm.DB, taskID string, userID string, role string) (*models.Task, error) {
 	var task models.Task
 
 	userUUID, err := uuid.Parse(userID)
@@ -63,14 +109,28 @@ func GetTaskByID(taskID string, userID string) (*models.Task, error) {
 		return nil, errors.ErrInvalidID("user")
 	}
 
-	if err := database.DB.Where("id = ? AND (creator_id = ? OR assignee_id = ?)", taskID, userUUID, userUUID).First(&task).Error; err != nil {
+	query := db.Where("id = ?", taskID)
+	if role != models.RoleAdmin {
+		query = query.Where(readableTaskCondition, sql.Named("user", userUUID))
+	}
+
+	if err := query.First(&task).Error; err != nil {
 		return nil, errors.ErrNotFound("task")
 	}
 
 	return &task, nil
 }
 
-func UpdateTask(taskID string, userID string, dto dto.UpdateTaskDTO) (*models.Task, error) {
+// readableTaskCondition matches the tasks a user other than an admin can read, with the user's ID
+// as the named argument "user": tasks they created or are assigned to, or were mentioned in.
+const readableTaskCondition = "(tasks.creator_id = @user OR tasks.assignee_id = @user OR " +
+	"tasks.id IN (SELECT task_id FROM task_mentions WHERE user_id = @user))"
+
+// UpdateTask applies a partial update to a task. Only its creator or an admin may update it.
+// A task with open subtasks can only be completed, and a task blocked by open tasks can only be
+// started or completed, when dto.Force is set. Users newly mentioned in the description are notified, and the changed
+// fields are recorded in the task's history.
+func UpdateTask(taskID string, userID string, role string, dto dto.UpdateTaskDTO) (*models.Task, error) {
 	var task models.Task
 
 	// Find the task by ID
@@ -84,45 +144,94 @@ func UpdateTask(taskID string, userID string, dto dto.UpdateTaskDTO) (*models.Ta
 		return nil, errors.ErrInvalidID("user")
 	}
 
-	if task.CreatorID != userUUID {
+	if !canManageTask(task, userUUID, role) {
 		return nil, errors.ErrUnauthorizedAction("update", "task")
 	}
 
-	// Aply updates from the DTO
-	if dto.Status != "" {
-    task.Status = dto.Status
-	}
-	if dto.Priority != "" {
-		task.Priority = dto.Priority
-	}
+	// Validate the DTO before touching the task
+	var dueDate time.Time
 	if dto.DueDate != "" {
-		parsedDate, err := time.Parse(time.RFC3339, dto.DueDate)
-		if err != nil {
+		if dueDate, err = time.Parse(time.RFC3339, dto.DueDate); err != nil {
 			return nil, errors.ErrInvalidField("due_date")
 		}
-		task.DueDate = parsedDate
-	}
-	if dto.Description != "" {
-		task.Description = dto.Description
 	}
+	var assigneeUUID uuid.UUID
 	if dto.AssigneeID != "" {
-		assigneeUUID, err := uuid.Parse(dto.AssigneeID)
-		if err != nil {
+		if assigneeUUID, err = uuid.Parse(dto.AssigneeID); err != nil {
 			return nil, errors.ErrInvalidID("assignee")
 		}
-		task.AssigneeID = assigneeUUID
+		if err := checkAssigneeVerified(userID, assigneeUUID); err != nil {
+			return nil, err
+		}
 	}
 
-	task.UpdatedAt = time.Now()
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Reload the task locked, so the history diffs against the row this update replaces
+		// even when another update commits in between
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&task, "id = ?", task.ID).Error; err != nil {
+			return errors.ErrNotFound("task")
+		}
+		if !canManageTask(task, userUUID, role) {
+			return errors.ErrUnauthorizedAction("update", "task")
+		}
+
+		original := task
+		previousStatus, previousDescription := task.Status, task.Description
+		wasComplete := previousStatus == models.TaskStatusComplete
+
+		// Aply updates from the DTO
+		if dto.Status != "" {
+			task.Status = dto.Status
+		}
+		if dto.Priority != "" {
+			task.Priority = dto.Priority
+		}
+		if dto.DueDate != "" {
+			task.DueDate = dueDate
+		}
+		if dto.Description != "" {
+			task.Description = dto.Description
+		}
+		if dto.AssigneeID != "" {
+			task.AssigneeID = assigneeUUID
+		}
+		task.UpdatedAt = time.Now()
 
-	if err := database.DB.Save(&task).Error; err != nil {
+		if dto.ParentID != nil {
+			if err := setTaskParent(tx, &task, *dto.ParentID, userID, role); err != nil {
+				return err
+			}
+		}
+		if task.Status == models.TaskStatusComplete && !wasComplete && !dto.Force {
+			if err := checkSubtasksComplete(tx, task.ID); err != nil {
+				return err
+			}
+		}
+		if task.Status != previousStatus && task.Status != models.TaskStatusPending && !dto.Force {
+			if err := checkBlockersComplete(tx, task.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(&task).Error; err != nil {
+			return err
+		}
+		if err := recordTaskEvent(tx, &userUUID, &original, &task); err != nil {
+			return err
+		}
+		return notifyMentions(tx, userUUID, models.NotificationTaskMention, task.ID, nil, task.Description, previousDescription)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := loadTaskDetails(userID, &task); err != nil {
 		return nil, err
 	}
 
 	return &task, nil
 }
 
-func DeleteTask(taskID string, userID string) error {
+// DeleteTask deletes a task. Only its creator or an admin may delete it.
+func DeleteTask(taskID string, userID string, role string) error {
 	var task models.Task
 
 	// Find the task by ID
@@ -136,14 +245,64 @@ func DeleteTask(taskID string, userID string) error {
 		return errors.ErrInvalidID("user")
 	}
 
-	if task.CreatorID != userUUID {
+	if !canManageTask(task, userUUID, role) {
 		return errors.ErrUnauthorizedAction("delete", "task")
 	}
 
-	// Delete the task
-	if err := database.DB.Delete(&task).Error; err != nil {
+	// Delete the task and record it in its history, then the contents of its attachments once the deletion is committed
+	var keys []string
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if keys, err = taskAttachmentKeys(tx, "id = ?", task.ID); err != nil {
+			return err
+		}
+		if err := recordSubtasksDetached(tx, &userUUID, task.ID); err != nil {
+			return err
+		}
+		if err := tx.Delete(&task).Error; err != nil {
+			return err
+		}
+		return recordTaskEvent(tx, &userUUID, &task, nil)
+	})
+	if err != nil {
 		return err
 	}
+	deleteBlobs(keys...)
 
 	return nil
-}
\ No newline at end of file
+}
+
+// recordSubtasksDetached records in the history of each direct subtask of a task about to be deleted
+// that it no longer has a parent, as the database clears their parent_id with the task. The actor is nil
+// for changes made by the system.
+func recordSubtasksDetached(tx *gorm.DB, actorID *uuid.UUID, taskID uuid.UUID) error {
+	var subtasks []models.Task
+	if err := tx.Where("parent_id = ?", taskID).Find(&subtasks).Error; err != nil {
+		return errors.NewInternalServerError("error retrieving subtasks")
+	}
+
+	for i := range subtasks {
+		before := subtasks[i]
+		after := before
+		after.ParentID = nil
+		if err := recordTaskEvent(tx, actorID, &before, &after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadTaskDetails fills in the labels visible to the user, the progress and the dependencies of the tasks.
+func loadTaskDetails(userID string, tasks ...*models.Task) error {
+	if err := loadTaskLabels(userID, tasks...); err != nil {
+		return err
+	}
+	if err := loadTaskProgress(tasks...); err != nil {
+		return err
+	}
+	return loadTaskDependencies(userID, tasks...)
+}
+
+// canManageTask reports whether the user may modify or delete the task: its creator or an admin.
+func canManageTask(task models.Task, userID uuid.UUID, role string) bool {
+	return role == models.RoleAdmin || task.CreatorID == userID
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// refreshTokenTTL returns the lifetime of a refresh token, configurable through REFRESH_TOKEN_TTL.
+func refreshTokenTTL() time.Duration {
+	return utils.DurationFromEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+}
+
+// issueTokens creates a new access token and a refresh token belonging to the given family.
+// A new family, and the session it stands for, is started when familyID is uuid.Nil;
+// otherwise the session is renewed with the client's current details.
+func issueTokens(tx *gorm.DB, user models.User, familyID uuid.UUID, client utils.ClientInfo) (dto.AuthResponse, *models.RefreshToken, error) {
+	plain, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return dto.AuthResponse{}, nil, errors.NewInternalServerError("error generating token")
+	}
+
+	refreshExpiresAt := time.Now().Add(refreshTokenTTL())
+	if familyID == uuid.Nil {
+		familyID = uuid.New()
+		err = startSession(tx, user.ID, familyID, client, refreshExpiresAt)
+	} else {
+		err = renewSession(tx, familyID, client, refreshExpiresAt)
+	}
+	if err != nil {
+		return dto.AuthResponse{}, nil, err
+	}
+
+	refresh := models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: refreshExpiresAt,
+	}
+	if err := tx.Create(&refresh).Error; err != nil {
+		return dto.AuthResponse{}, nil, errors.NewInternalServerError("error storing refresh token")
+	}
+
+	access, expiresAt, err := utils.GenerateJWT(user.ID.String(), user.Role, familyID.String())
+	if err != nil {
+		return dto.AuthResponse{}, nil, errors.NewInternalServerError("error generating token")
+	}
+
+	return dto.AuthResponse{
+		Token:        access,
+		RefreshToken: plain,
+		TokenType:    "Bearer",
+		ExpiresAt:    expiresAt.UTC().Format(time.RFC3339),
+	}, &refresh, nil
+}
+
+// RefreshTokens exchanges a valid refresh token for a new token pair.
+// The presented token is revoked and replaced, so every refresh token can be used only once.
+// Presenting a token that was already rotated is treated as theft: the whole family is revoked.
+func RefreshTokens(refreshToken string, client utils.ClientInfo) (dto.AuthResponse, error) {
+	hash := utils.HashToken(refreshToken)
+
+	var resp dto.AuthResponse
+	reused := false
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var current models.RefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", hash).First(&current).Error; err != nil {
+			return errors.NewAuthError("invalid refresh token")
+		}
+
+		if current.RevokedAt != nil {
+			if current.ReplacedByID != nil {
+				// A rotated token was replayed; commit the family revocation and reject below.
+				reused = true
+				return revokeTokenFamily(tx, current.FamilyID)
+			}
+			return errors.NewAuthError("invalid refresh token")
+		}
+
+		if time.Now().After(current.ExpiresAt) {
+			return errors.NewAuthError("refresh token expired")
+		}
+
+		var user models.User
+		if err := tx.First(&user, "id = ?", current.UserID).Error; err != nil {
+			return errors.NewAuthError("invalid refresh token")
+		}
+		if user.DisabledAt != nil {
+			return errors.NewAuthError("account disabled")
+		}
+
+		pair, next, err := issueTokens(tx, user, current.FamilyID, client)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&current).Updates(map[string]interface{}{
+			"revoked_at":     time.Now(),
+			"replaced_by_id": next.ID,
+		}).Error; err != nil {
+			return errors.NewInternalServerError("error rotating refresh token")
+		}
+
+		resp = pair
+		return nil
+	})
+	if err != nil {
+		return dto.AuthResponse{}, err
+	}
+	if reused {
+		return dto.AuthResponse{}, errors.NewAuthError("refresh token reuse detected")
+	}
+
+	return resp, nil
+}
+
+// revokeTokenFamily revokes every still-active refresh token in the given family and ends its session.
+// Access tokens of the session are rejected once the revocation cache is synced.
+func revokeTokenFamily(tx *gorm.DB, familyID uuid.UUID) error {
+	now := time.Now()
+	err := tx.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now).Error
+	if err != nil {
+		return errors.NewInternalServerError("error revoking refresh tokens")
+	}
+
+	if err := tx.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now).Error; err != nil {
+		return errors.NewInternalServerError("error revoking session")
+	}
+	return nil
+}
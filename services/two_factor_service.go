@@ -0,0 +1,283 @@
+package services
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"gorm.io/gorm"
+)
+
+// recoveryCodeCount is the number of recovery codes generated when 2FA is enabled.
+const recoveryCodeCount = 10
+
+// totpIssuer returns the issuer name shown in authenticator apps (TOTP_ISSUER).
+func totpIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Task Manager"
+}
+
+// SetupTwoFactor generates a new TOTP secret for the user. It is not enforced until confirmed.
+// Setup is refused when TOTP_ENCRYPTION_KEY is not set, as the secret would be stored in plaintext.
+func SetupTwoFactor(userID string) (dto.TwoFactorSetupResponse, error) {
+	if !utils.TOTPEncryptionConfigured() {
+		log.Printf("Refused two-factor setup: TOTP_ENCRYPTION_KEY is not set")
+		return dto.TwoFactorSetupResponse{}, errors.NewInternalServerError("two-factor authentication is not configured")
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return dto.TwoFactorSetupResponse{}, errors.ErrNotFound("user")
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return dto.TwoFactorSetupResponse{}, errors.NewConflictError("two-factor authentication already enabled")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return dto.TwoFactorSetupResponse{}, errors.NewInternalServerError("error generating secret")
+	}
+
+	sealed, err := utils.SealTOTPSecret(secret)
+	if err != nil {
+		return dto.TwoFactorSetupResponse{}, errors.NewInternalServerError("error encrypting secret")
+	}
+	if err := database.DB.Model(&user).Update("totp_secret", sealed).Error; err != nil {
+		return dto.TwoFactorSetupResponse{}, errors.NewInternalServerError("error storing secret")
+	}
+
+	return dto.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPURI(totpIssuer(), user.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor enables 2FA once the user proves the authenticator app produces valid codes.
+// It returns freshly generated recovery codes, which are only shown this once.
+func ConfirmTwoFactor(userID string, code string) (dto.RecoveryCodesResponse, error) {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return dto.RecoveryCodesResponse{}, errors.ErrNotFound("user")
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return dto.RecoveryCodesResponse{}, errors.NewConflictError("two-factor authentication already enabled")
+	}
+	if user.TOTPSecret == nil {
+		return dto.RecoveryCodesResponse{}, errors.NewValidationError("two-factor setup has not been started")
+	}
+
+	secret, err := utils.OpenTOTPSecret(*user.TOTPSecret)
+	if err != nil {
+		return dto.RecoveryCodesResponse{}, errors.NewInternalServerError("error reading secret")
+	}
+	step, ok := utils.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return dto.RecoveryCodesResponse{}, errors.NewValidationError("invalid two-factor code")
+	}
+
+	var codes []string
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_enabled_at": time.Now(),
+			"totp_last_step":  step,
+		}).Error; err != nil {
+			return errors.NewInternalServerError("error enabling two-factor authentication")
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return dto.RecoveryCodesResponse{}, err
+	}
+
+	return dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor turns 2FA off after re-authenticating the user with their password and a second factor.
+func DisableTwoFactor(userID string, password string, code string) error {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return errors.ErrNotFound("user")
+	}
+
+	if user.TOTPEnabledAt == nil {
+		return errors.NewValidationError("two-factor authentication is not enabled")
+	}
+
+	if !utils.CheckPasswordHash(password, user.Password) {
+		return errors.NewAuthError("invalid credentials")
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if !verifySecondFactor(tx, user, code) {
+			return errors.NewAuthError("invalid two-factor code")
+		}
+
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_secret":     nil,
+			"totp_enabled_at": nil,
+			"totp_last_step":  nil,
+		}).Error; err != nil {
+			return errors.NewInternalServerError("error disabling two-factor authentication")
+		}
+
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return errors.NewInternalServerError("error removing recovery codes")
+		}
+		return nil
+	})
+}
+
+// CompleteTwoFactorLogin exchanges a login challenge and a second factor for a token pair.
+// Each challenge token can be redeemed only once and tried at most mfaChallengeMaxAttempts times,
+// and wrong codes count as failed logins.
+func CompleteTwoFactorLogin(challengeToken string, code string, client utils.ClientInfo) (dto.AuthResponse, error) {
+	claims, err := utils.VerifyChallengeJWT(challengeToken)
+	if err != nil || IsTokenRevoked(claims) {
+		return dto.AuthResponse{}, errors.NewAuthError("invalid or expired challenge")
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", claims.UserID).Error; err != nil {
+		return dto.AuthResponse{}, errors.NewAuthError("invalid or expired challenge")
+	}
+	if user.DisabledAt != nil {
+		return dto.AuthResponse{}, errors.NewAuthError("account disabled")
+	}
+
+	if err := checkLoginAllowed(user.Email, client.IP); err != nil {
+		return dto.AuthResponse{}, err
+	}
+
+	attempts, err := useChallengeAttempt(claims)
+	if err != nil {
+		return dto.AuthResponse{}, err
+	}
+	if attempts > mfaChallengeMaxAttempts() {
+		return dto.AuthResponse{}, errors.NewAuthError("invalid or expired challenge")
+	}
+
+	var tokens dto.AuthResponse
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if !verifySecondFactor(tx, user, code) {
+			return errors.NewAuthError("invalid two-factor code")
+		}
+
+		var err error
+		tokens, _, err = issueTokens(tx, user, uuid.Nil, client)
+		return err
+	})
+	if err != nil {
+		if _, ok := err.(*errors.AuthError); ok {
+			registerLoginFailure(user.Email, client.IP)
+			// The last attempt revokes the challenge; the user has to log in with their password again
+			if attempts >= mfaChallengeMaxAttempts() {
+				if err := RevokeAccessToken(claims); err != nil {
+					log.Printf("Failed to revoke two-factor challenge: %v", err)
+				}
+			}
+		}
+		return dto.AuthResponse{}, err
+	}
+
+	resetLoginFailures(user.Email)
+
+	if err := RevokeAccessToken(claims); err != nil {
+		return dto.AuthResponse{}, err
+	}
+
+	return tokens, nil
+}
+
+// mfaChallengeMaxAttempts returns how many second factors can be tried with one login challenge (MFA_CHALLENGE_MAX_ATTEMPTS).
+func mfaChallengeMaxAttempts() int {
+	return utils.IntFromEnv("MFA_CHALLENGE_MAX_ATTEMPTS", 5)
+}
+
+// useChallengeAttempt counts an attempt to complete the challenge and returns the number of attempts
+// made with it so far, including this one. Counting happens before the code is checked, so that
+// concurrent guesses cannot exceed the limit.
+func useChallengeAttempt(claims *utils.Claims) (int, error) {
+	expiresAt := time.Now().Add(utils.AccessTokenTTL())
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+
+	// Prune the attempts of expired challenges as we go
+	database.DB.Where("expires_at < ?", time.Now()).Delete(&models.MFAChallengeAttempt{})
+
+	var attempt models.MFAChallengeAttempt
+	err := database.DB.Raw(`
+		INSERT INTO mfa_challenge_attempts (jti, attempts, expires_at) VALUES (?, 1, ?)
+		ON CONFLICT (jti) DO UPDATE SET attempts = mfa_challenge_attempts.attempts + 1
+		RETURNING jti, attempts, expires_at`, claims.ID, expiresAt).Scan(&attempt).Error
+	if err != nil {
+		return 0, errors.NewInternalServerError("error recording two-factor attempt")
+	}
+	return attempt.Attempts, nil
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code for the user.
+// TOTP codes cannot be replayed and recovery codes are consumed on use.
+func verifySecondFactor(tx *gorm.DB, user models.User, code string) bool {
+	if user.TOTPEnabledAt == nil || user.TOTPSecret == nil {
+		return false
+	}
+
+	secret, err := utils.OpenTOTPSecret(*user.TOTPSecret)
+	if err != nil {
+		log.Printf("Failed to read TOTP secret: %v", err)
+		return false
+	}
+	if step, ok := utils.ValidateTOTP(secret, code, time.Now()); ok {
+		// Only advance the last used step; a code from an already used step is a replay.
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND (totp_last_step IS NULL OR totp_last_step < ?)", user.ID, step).
+			Update("totp_last_step", step)
+		return result.Error == nil && result.RowsAffected == 1
+	}
+
+	result := tx.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, utils.HashToken(utils.NormalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and stores a new set, returning the plain codes.
+func replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, errors.NewInternalServerError("error removing recovery codes")
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, errors.NewInternalServerError("error generating recovery codes")
+		}
+		codes = append(codes, code)
+		records = append(records, models.RecoveryCode{
+			ID:       uuid.New(),
+			UserID:   userID,
+			CodeHash: utils.HashToken(utils.NormalizeRecoveryCode(code)),
+		})
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, errors.NewInternalServerError("error storing recovery codes")
+	}
+	return codes, nil
+}
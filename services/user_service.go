@@ -0,0 +1,323 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/mailer"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"golang.org/x/text/language"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// emailChangeTTL returns how long an email change confirmation link stays valid (EMAIL_CHANGE_TTL).
+func emailChangeTTL() time.Duration {
+	return utils.DurationFromEnv("EMAIL_CHANGE_TTL", 24*time.Hour)
+}
+
+// GetProfile returns the profile of the given user.
+func GetProfile(userID string) (dto.UserResponse, error) {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return dto.UserResponse{}, errors.ErrNotFound("user")
+	}
+	return toUserResponse(user), nil
+}
+
+// UpdateProfile applies the non-empty fields of the request to the user's profile.
+// The request is expected to have been validated.
+func UpdateProfile(userID string, req dto.UpdateProfileRequest) (dto.UserResponse, error) {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return dto.UserResponse{}, errors.ErrNotFound("user")
+	}
+
+	updates := map[string]interface{}{}
+	if req.FirstName != "" {
+		updates["first_name"] = strings.TrimSpace(req.FirstName)
+	}
+	if req.LastName != "" {
+		updates["last_name"] = strings.TrimSpace(req.LastName)
+	}
+	if req.Username != "" {
+		username := strings.ToLower(req.Username)
+		var taken int64
+		if err := database.DB.Model(&models.User{}).Where("username = ? AND id <> ?", username, user.ID).
+			Count(&taken).Error; err != nil {
+			return dto.UserResponse{}, errors.NewInternalServerError("error updating profile")
+		}
+		if taken > 0 {
+			return dto.UserResponse{}, errors.NewConflictError("username is already taken")
+		}
+		updates["username"] = username
+	}
+	if req.Timezone != "" {
+		updates["timezone"] = req.Timezone
+	}
+	if req.Locale != "" {
+		// Store the canonical form of the tag, e.g. "en_us" becomes "en-US".
+		tag, err := language.Parse(req.Locale)
+		if err != nil {
+			return dto.UserResponse{}, errors.NewValidationError("invalid locale")
+		}
+		updates["locale"] = tag.String()
+	}
+
+	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+		// Another user may have claimed the username since the check above
+		if database.IsUniqueViolation(err) {
+			return dto.UserResponse{}, errors.NewConflictError("username is already taken")
+		}
+		return dto.UserResponse{}, errors.NewInternalServerError("error updating profile")
+	}
+
+	return GetProfile(userID)
+}
+
+// ChangePassword replaces the user's password after checking the current one.
+// Every existing session and personal access token is revoked and a fresh token pair is returned for the caller.
+func ChangePassword(userID string, req dto.ChangePasswordRequest, client utils.ClientInfo) (dto.AuthResponse, error) {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return dto.AuthResponse{}, errors.ErrNotFound("user")
+	}
+
+	if !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		return dto.AuthResponse{}, errors.NewAuthError("invalid credentials")
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return dto.AuthResponse{}, errors.NewInternalServerError("error hashing password")
+	}
+
+	if err := database.DB.Model(&user).Update("password", hashedPassword).Error; err != nil {
+		return dto.AuthResponse{}, errors.NewInternalServerError("error updating password")
+	}
+
+	if err := revokeUserCredentials(userID); err != nil {
+		return dto.AuthResponse{}, err
+	}
+
+	tokens, _, err := issueTokens(database.DB, user, uuid.Nil, client)
+	if err != nil {
+		return dto.AuthResponse{}, err
+	}
+	return tokens, nil
+}
+
+// RequestEmailChange records the new address as pending and emails a confirmation link to it.
+// The current address is notified so an unexpected change can be noticed.
+func RequestEmailChange(userID string, req dto.ChangeEmailRequest) error {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return errors.ErrNotFound("user")
+	}
+
+	if !utils.CheckPasswordHash(req.Password, user.Password) {
+		return errors.NewAuthError("invalid credentials")
+	}
+
+	email := utils.CleanEmail(req.Email)
+	if email == user.Email {
+		return errors.NewValidationError("new email must be different from the current one")
+	}
+	if err := checkEmailAvailable(database.DB, email); err != nil {
+		return err
+	}
+
+	var token string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("pending_email", email).Error; err != nil {
+			return errors.NewInternalServerError("error storing pending email")
+		}
+
+		var err error
+		token, err = createActionToken(tx, user.ID, models.PurposeEmailChange, emailChangeTTL())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/auth/email/confirm?token=%s", utils.AppURL(), url.QueryEscape(token))
+	messages := []mailer.Message{
+		{
+			To:      email,
+			Subject: "Confirm your new email address",
+			Body: fmt.Sprintf(
+				"Hi %s,\n\nOpen the link below to start using this address for your account:\n\n%s\n\nThe link expires in %s.\n",
+				user.FirstName, link, emailChangeTTL(),
+			),
+		},
+		{
+			To:      user.Email,
+			Subject: "Your email address is being changed",
+			Body: fmt.Sprintf(
+				"Hi %s,\n\nA request was made to change your account email to %s. "+
+					"If this was not you, change your password right away.\n",
+				user.FirstName, email,
+			),
+		},
+	}
+	for _, msg := range messages {
+		if err := mailer.Default().Send(msg); err != nil {
+			log.Printf("Failed to send email change message: %v", err)
+		}
+	}
+	return nil
+}
+
+// ConfirmEmailChange moves the owner of the token to their pending address.
+// Since the link was opened from that inbox, the new address is also marked as verified.
+func ConfirmEmailChange(token string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		change, err := consumeActionToken(tx, models.PurposeEmailChange, token)
+		if err != nil {
+			return err
+		}
+
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", change.UserID).Error; err != nil {
+			return errors.NewValidationError("invalid or expired token")
+		}
+		if user.PendingEmail == nil {
+			return errors.NewValidationError("invalid or expired token")
+		}
+
+		// The address may have been registered since the change was requested.
+		if err := checkEmailAvailable(tx, *user.PendingEmail); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"email":             *user.PendingEmail,
+			"email_verified_at": time.Now(),
+			"pending_email":     nil,
+		}).Error; err != nil {
+			return errors.NewInternalServerError("error updating email")
+		}
+		return nil
+	})
+}
+
+// likeEscaper escapes the LIKE wildcards in user input.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchUsers returns a page of the user directory. A non-empty query matches users whose
+// first name, last name, full name or email starts with it, case-insensitively.
+// Disabled accounts and accounts scheduled for deletion are not listed.
+func SearchUsers(query string, page int, limit int) (dto.UserListResponse, error) {
+	users, total, err := searchUsers(userSearchQuery(query).Where("disabled_at IS NULL AND deletion_scheduled_at IS NULL"), page, limit)
+	if err != nil {
+		return dto.UserListResponse{}, err
+	}
+
+	resp := dto.UserListResponse{
+		Users: make([]dto.PublicUserResponse, 0, len(users)),
+		Page:  page,
+		Limit: limit,
+		Total: total,
+	}
+	for _, u := range users {
+		resp.Users = append(resp.Users, toPublicUserResponse(u))
+	}
+	return resp, nil
+}
+
+// userSearchQuery scopes a users query to the given name, username or email prefix, if any.
+func userSearchQuery(query string) *gorm.DB {
+	db := database.DB.Model(&models.User{})
+
+	if query = strings.ToLower(strings.TrimSpace(query)); query != "" {
+		prefix := likeEscaper.Replace(query) + "%"
+		conditions := "lower(first_name) LIKE @prefix OR lower(last_name) LIKE @prefix OR lower(email) LIKE @prefix OR username LIKE @prefix"
+		if strings.Contains(query, " ") {
+			conditions += " OR lower(first_name || ' ' || last_name) LIKE @prefix"
+		}
+		db = db.Where(conditions, map[string]interface{}{"prefix": prefix})
+	}
+	return db
+}
+
+// searchUsers returns one page of the users matched by db, ordered by name, and the total match count.
+func searchUsers(db *gorm.DB, page int, limit int) ([]models.User, int64, error) {
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, errors.NewInternalServerError("error searching users")
+	}
+
+	var users []models.User
+	if err := db.Order("lower(first_name), lower(last_name), id").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&users).Error; err != nil {
+		return nil, 0, errors.NewInternalServerError("error searching users")
+	}
+	return users, total, nil
+}
+
+// GetPublicProfile returns the public profile of a user listed in the directory. Like in SearchUsers,
+// disabled accounts and accounts scheduled for deletion are reported as not found.
+func GetPublicProfile(userID string) (dto.PublicUserResponse, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return dto.PublicUserResponse{}, errors.ErrInvalidID("user")
+	}
+
+	var user models.User
+	if err := database.DB.Where("disabled_at IS NULL AND deletion_scheduled_at IS NULL").
+		First(&user, "id = ?", userID).Error; err != nil {
+		return dto.PublicUserResponse{}, errors.ErrNotFound("user")
+	}
+	return toPublicUserResponse(user), nil
+}
+
+// checkEmailAvailable returns a ConflictError if the address belongs to an existing account.
+func checkEmailAvailable(tx *gorm.DB, email string) error {
+	var count int64
+	if err := tx.Model(&models.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
+		return errors.NewInternalServerError("database error")
+	}
+	if count > 0 {
+		return errors.NewConflictError("email already registered")
+	}
+	return nil
+}
+
+// toUserResponse maps a user to its profile representation.
+func toUserResponse(user models.User) dto.UserResponse {
+	return dto.UserResponse{
+		ID:                  user.ID.String(),
+		FirstName:           user.FirstName,
+		LastName:            user.LastName,
+		Username:            user.Username,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerifiedAt != nil,
+		PendingEmail:        user.PendingEmail,
+		Role:                user.Role,
+		Timezone:            user.Timezone,
+		Locale:              user.Locale,
+		TwoFactorEnabled:    user.TOTPEnabledAt != nil,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+	}
+}
+
+// toPublicUserResponse maps a user to the profile visible to other users.
+func toPublicUserResponse(user models.User) dto.PublicUserResponse {
+	return dto.PublicUserResponse{
+		ID:        user.ID.String(),
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Username:  user.Username,
+		Email:     user.Email,
+	}
+}
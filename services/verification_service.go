@@ -0,0 +1,129 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/mailer"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"gorm.io/gorm"
+)
+
+// Email verification policies, selected with EMAIL_VERIFICATION_POLICY.
+const (
+	// VerificationPolicyOff does not restrict unverified accounts.
+	VerificationPolicyOff = "off"
+	// VerificationPolicyAssign prevents assigning tasks to unverified accounts.
+	VerificationPolicyAssign = "assign"
+	// VerificationPolicyStrict additionally prevents unverified accounts from creating tasks.
+	VerificationPolicyStrict = "strict"
+)
+
+// verificationPolicy returns the configured email verification policy.
+func verificationPolicy() string {
+	switch policy := os.Getenv("EMAIL_VERIFICATION_POLICY"); policy {
+	case VerificationPolicyAssign, VerificationPolicyStrict:
+		return policy
+	default:
+		return VerificationPolicyOff
+	}
+}
+
+// emailVerificationTTL returns how long a verification link stays valid (EMAIL_VERIFICATION_TTL).
+func emailVerificationTTL() time.Duration {
+	return utils.DurationFromEnv("EMAIL_VERIFICATION_TTL", 48*time.Hour)
+}
+
+// sendVerificationEmail issues a new verification token for the user and emails it.
+// Delivery failures are logged; the user can request a new link later.
+func sendVerificationEmail(user models.User) error {
+	token, err := createActionToken(database.DB, user.ID, models.PurposeEmailVerification, emailVerificationTTL())
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/auth/verify?token=%s", utils.AppURL(), url.QueryEscape(token))
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			user.FirstName, link, emailVerificationTTL(),
+		),
+	}
+	if err := mailer.Default().Send(msg); err != nil {
+		log.Printf("Failed to send verification email: %v", err)
+	}
+	return nil
+}
+
+// VerifyEmail marks the owner of the verification token as verified.
+func VerifyEmail(token string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		verification, err := consumeActionToken(tx, models.PurposeEmailVerification, token)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.User{}).
+			Where("id = ? AND email_verified_at IS NULL", verification.UserID).
+			Update("email_verified_at", time.Now()).Error; err != nil {
+			return errors.NewInternalServerError("error verifying email")
+		}
+		return nil
+	})
+}
+
+// ResendVerificationEmail sends a fresh verification link to the user, invalidating previous ones.
+func ResendVerificationEmail(userID string) error {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return errors.ErrNotFound("user")
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return errors.NewConflictError("email already verified")
+	}
+
+	return sendVerificationEmail(user)
+}
+
+// checkCreatorVerified enforces the strict policy: unverified users cannot create tasks.
+func checkCreatorVerified(creatorID string) error {
+	if verificationPolicy() != VerificationPolicyStrict {
+		return nil
+	}
+
+	var creator models.User
+	if err := database.DB.Select("id", "email_verified_at").First(&creator, "id = ?", creatorID).Error; err != nil {
+		return errors.ErrNotFound("user")
+	}
+	if creator.EmailVerifiedAt == nil {
+		return errors.NewForbiddenError("verify your email address before creating tasks")
+	}
+	return nil
+}
+
+// checkAssigneeVerified enforces the assign and strict policies: tasks cannot be assigned
+// by actorID to another user whose email is unverified. Self-assignment is always allowed.
+func checkAssigneeVerified(actorID string, assigneeID uuid.UUID) error {
+	if verificationPolicy() == VerificationPolicyOff || assigneeID.String() == actorID {
+		return nil
+	}
+
+	var assignee models.User
+	if err := database.DB.Select("id", "email_verified_at").First(&assignee, "id = ?", assigneeID).Error; err != nil {
+		return errors.NewValidationError("assignee not found")
+	}
+	if assignee.EmailVerifiedAt == nil {
+		return errors.NewForbiddenError("tasks cannot be assigned to users with an unverified email")
+	}
+	return nil
+}
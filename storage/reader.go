@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// blobReader is a lazily opened, seekable view of a blob of known size. Seeking only records
+// the offset; the next read opens the blob there, so http.ServeContent can serve Range requests
+// without downloading the parts of the blob it skips.
+type blobReader struct {
+	ctx    context.Context
+	store  BlobStore
+	key    string
+	size   int64
+	offset int64
+	body   io.ReadCloser
+}
+
+// NewReadSeeker returns a seekable reader over the blob stored under key, which is size bytes long.
+// The caller must close it.
+func NewReadSeeker(ctx context.Context, store BlobStore, key string, size int64) io.ReadSeekCloser {
+	return &blobReader{ctx: ctx, store: store, key: key, size: size}
+}
+
+func (b *blobReader) Read(p []byte) (int, error) {
+	if b.offset >= b.size {
+		return 0, io.EOF
+	}
+	if b.body == nil {
+		body, err := b.store.Open(b.ctx, b.key, b.offset)
+		if err != nil {
+			return 0, err
+		}
+		b.body = body
+	}
+
+	n, err := b.body.Read(p)
+	b.offset += int64(n)
+	return n, err
+}
+
+func (b *blobReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += b.offset
+	case io.SeekEnd:
+		offset += b.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if offset != b.offset && b.body != nil {
+		b.body.Close()
+		b.body = nil
+	}
+	b.offset = offset
+	return offset, nil
+}
+
+func (b *blobReader) Close() error {
+	if b.body == nil {
+		return nil
+	}
+	err := b.body.Close()
+	b.body = nil
+	return err
+}
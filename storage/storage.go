@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"sync"
+)
+
+// ErrNotFound is returned when a blob does not exist.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore stores the contents of uploaded files under opaque keys such as "tasks/<id>/<id>".
+// Implementations must be safe for concurrent use.
+type BlobStore interface {
+	// Put stores the size bytes read from r under key, replacing any existing blob.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open streams the blob from offset to its end. The caller must close the reader.
+	Open(ctx context.Context, key string, offset int64) (io.ReadCloser, error)
+	// Delete removes the blob. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	mu      sync.RWMutex
+	current BlobStore
+)
+
+// Default returns the process-wide blob store, building it from the environment on first use.
+// BLOB_STORE selects the implementation: "local" (the default, files under BLOB_LOCAL_DIR) or
+// "s3" (an S3-compatible bucket configured with the S3_* variables).
+func Default() BlobStore {
+	mu.RLock()
+	s := current
+	mu.RUnlock()
+	if s != nil {
+		return s
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if current == nil {
+		current = fromEnv()
+	}
+	return current
+}
+
+// SetDefault replaces the process-wide blob store, e.g. with a temporary directory in tests.
+func SetDefault(s BlobStore) {
+	mu.Lock()
+	current = s
+	mu.Unlock()
+}
+
+// fromEnv builds a blob store from the BLOB_* and S3_* environment variables.
+func fromEnv() BlobStore {
+	switch os.Getenv("BLOB_STORE") {
+	case "s3":
+		store, err := NewS3Store(S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PathStyle:       os.Getenv("S3_PATH_STYLE") != "false",
+		})
+		if err != nil {
+			log.Fatalf("Invalid S3 blob store configuration: %v", err)
+		}
+		return store
+	default:
+		dir := os.Getenv("BLOB_LOCAL_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		return NewLocalStore(dir)
+	}
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// createAssignedTask creates a task assigned to assigneeID and returns its ID.
+func createAssignedTask(t *testing.T, token string, assigneeID string) string {
+	resp := postJSON("/tasks", map[string]string{
+		"title":       "Shared Task",
+		"description": "Test Description",
+		"due_date":    time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+		"assignee_id": assigneeID,
+	}, token)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Failed to create task: %s", resp.Body.String())
+	}
+
+	var task map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &task)
+	return task["id"].(string)
+}
+
+func TestAccountDeletionCanBeCancelled(t *testing.T) {
+	session := registerAndLogin(t, "delete-restore")
+	pat := createPersonalAccessToken(t, session["token"], "tasks:read")
+
+	resp := sendJSON(http.MethodDelete, "/me", map[string]string{"password": "wrong-password"}, session["token"])
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = sendJSON(http.MethodDelete, "/me", map[string]string{"password": testPass}, session["token"])
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	var deletion dto.AccountDeletionResponse
+	json.Unmarshal(resp.Body.Bytes(), &deletion)
+	assert.True(t, deletion.DeletionScheduledAt.After(time.Now()))
+
+	// Every session and personal access token is revoked, but the user can still log in to restore the account
+	resp = postJSON("/auth/refresh", map[string]string{"refresh_token": session["refresh_token"]}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, http.StatusUnauthorized, getJSON("/tasks", pat).Code)
+
+	resp = postJSON("/auth/login", map[string]string{"email": session["email"], "password": testPass}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var login map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &login)
+
+	resp = getJSON("/me", login["token"])
+	var profile dto.UserResponse
+	json.Unmarshal(resp.Body.Bytes(), &profile)
+	assert.NotNil(t, profile.DeletionScheduledAt)
+
+	resp = postJSON("/me/restore", nil, login["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var restored dto.UserResponse
+	json.Unmarshal(resp.Body.Bytes(), &restored)
+	assert.Nil(t, restored.DeletionScheduledAt)
+
+	// Restored accounts are not purged
+	_, err := services.PurgeDeletedAccounts(time.Now().Add(365 * 24 * time.Hour))
+	assert.NoError(t, err)
+
+	resp = postJSON("/auth/login", map[string]string{"email": session["email"], "password": testPass}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestAccountPurgeKeepsSharedTasks(t *testing.T) {
+	leaving := registerAndLogin(t, "delete-leaving")
+	teammate := registerAndLogin(t, "delete-teammate")
+	leavingID := userIDFromToken(t, leaving["token"])
+	teammateID := userIDFromToken(t, teammate["token"])
+
+	personalTask := createTestTask(t, leaving["token"], "low", "pending")
+	delegatedTask := createAssignedTask(t, leaving["token"], teammateID)
+	receivedTask := createAssignedTask(t, teammate["token"], leavingID)
+
+	resp := sendJSON(http.MethodDelete, "/me", map[string]string{"password": testPass}, leaving["token"])
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	_, err := services.PurgeDeletedAccounts(time.Now().Add(365 * 24 * time.Hour))
+	assert.NoError(t, err)
+
+	resp = postJSON("/auth/login", map[string]string{"email": leaving["email"], "password": testPass}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	// The teammate keeps both shared tasks and now owns the one delegated to them
+	resp = getJSON("/tasks", teammate["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), delegatedTask)
+	assert.Contains(t, resp.Body.String(), receivedTask)
+	assert.NotContains(t, resp.Body.String(), personalTask)
+
+	resp = sendJSON(http.MethodPut, "/tasks/"+delegatedTask, map[string]string{"status": "complete"}, teammate["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestAccountExport(t *testing.T) {
+	session := registerAndLogin(t, "export")
+	teammate := registerAndLogin(t, "export-teammate")
+	taskID := createTestTask(t, session["token"], "medium", "pending")
+
+	resp := sendJSON(http.MethodPut, "/tasks/"+taskID, map[string]string{"status": "in_progress"}, session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	sharedID := createAssignedTask(t, teammate["token"], userIDFromToken(t, session["token"]))
+	createComment(t, sharedID, "Over to you @"+session["email"], teammate["token"])
+
+	resp = getJSON("/me/export", session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Header().Get("Content-Disposition"), "attachment")
+
+	var export dto.AccountExport
+	json.Unmarshal(resp.Body.Bytes(), &export)
+	assert.Equal(t, session["email"], export.Profile.Email)
+	if assert.Len(t, export.Tasks, 2) {
+		assert.Equal(t, taskID, export.Tasks[0].ID)
+	}
+
+	// Activity lists the changes the user made, not those made by others to their tasks
+	if assert.Len(t, export.Activity, 2) {
+		assert.Equal(t, "created", export.Activity[0].Action)
+		assert.Equal(t, dto.FieldChange{Before: "pending", After: "in_progress"}, export.Activity[1].Changes["status"])
+		assert.Equal(t, taskID, export.Activity[1].TaskID)
+	}
+	if assert.Len(t, export.Notifications, 1) {
+		assert.Equal(t, "comment_mention", export.Notifications[0].Kind)
+		assert.Equal(t, sharedID, export.Notifications[0].TaskID)
+		if assert.NotNil(t, export.Notifications[0].ActorID) {
+			assert.Equal(t, userIDFromToken(t, teammate["token"]), *export.Notifications[0].ActorID)
+		}
+	}
+	assert.NotEmpty(t, export.Sessions)
+	assert.NotNil(t, export.PersonalAccessTokens)
+}
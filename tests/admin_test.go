@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminRoutesRequireAdminRole(t *testing.T) {
+	member := registerAndLogin(t, "admin-member")
+
+	resp := getJSON("/admin/users", member["token"])
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	admin := registerAdmin(t, "admin-list")
+	resp = getJSON("/admin/users?query=admin-list", admin["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var page map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &page)
+	assert.GreaterOrEqual(t, page["total"], float64(1))
+
+	var me map[string]interface{}
+	resp = getJSON("/me", admin["token"])
+	json.Unmarshal(resp.Body.Bytes(), &me)
+	assert.Equal(t, "admin", me["role"])
+}
+
+func TestAdminCanManageAnyTask(t *testing.T) {
+	member := registerAndLogin(t, "admin-task-owner")
+	admin := registerAdmin(t, "admin-task-cleaner")
+	taskID := createTestTask(t, member["token"], "medium", "pending")
+
+	resp := getJSON("/admin/users/"+userIDFromToken(t, member["token"])+"/tasks", admin["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), taskID)
+
+	resp = getJSON("/tasks/"+taskID, admin["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = sendJSON(http.MethodPut, "/tasks/"+taskID, map[string]string{"status": "complete"}, admin["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = sendJSON(http.MethodDelete, "/tasks/"+taskID, nil, admin["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
+
+func TestAdminDisableUser(t *testing.T) {
+	admin := registerAdmin(t, "admin-disabler")
+	member := registerAndLogin(t, "admin-disabled")
+	memberID := userIDFromToken(t, member["token"])
+
+	resp := postJSON("/admin/users/"+userIDFromToken(t, admin["token"])+"/disable", nil, admin["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = postJSON("/admin/users/"+memberID+"/disable", nil, admin["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, http.StatusUnauthorized, getJSON("/me", member["token"]).Code)
+
+	// The right password fails like a wrong one
+	wrong := postJSON("/auth/login", map[string]string{"email": member["email"], "password": "wrong-password"}, "")
+	resp = postJSON("/auth/login", map[string]string{"email": member["email"], "password": testPass}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, wrong.Body.String(), resp.Body.String())
+	resp = postJSON("/auth/refresh", map[string]string{"refresh_token": member["refresh_token"]}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = postJSON("/admin/users/"+memberID+"/enable", nil, admin["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = postJSON("/auth/login", map[string]string{"email": member["email"], "password": testPass}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestViewerRoleIsReadOnly(t *testing.T) {
+	admin := registerAdmin(t, "admin-roles")
+	member := registerAndLogin(t, "admin-viewer")
+	memberID := userIDFromToken(t, member["token"])
+
+	resp := sendJSON(http.MethodPut, "/admin/users/"+memberID+"/role", map[string]string{"role": "owner"}, admin["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = sendJSON(http.MethodPut, "/admin/users/"+memberID+"/role", map[string]string{"role": "viewer"}, admin["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// Access tokens carrying the old role are rejected, even those issued within the same second
+	resp = getJSON("/tasks", member["token"])
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	// The new role applies once the access token is refreshed
+	resp = postJSON("/auth/refresh", map[string]string{"refresh_token": member["refresh_token"]}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var tokens map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &tokens)
+
+	resp = getJSON("/tasks", tokens["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = postJSON("/tasks", map[string]interface{}{
+		"title":       "Viewer Task",
+		"description": "Should be rejected",
+		"due_date":    "2030-01-01T00:00:00Z",
+	}, tokens["token"])
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+func TestAdminForcePasswordReset(t *testing.T) {
+	admin := registerAdmin(t, "admin-resetter")
+	member := registerAndLogin(t, "admin-reset")
+	pat := createPersonalAccessToken(t, member["token"], "tasks:read", "tasks:write")
+
+	resp := postJSON("/admin/users/"+userIDFromToken(t, member["token"])+"/password-reset", nil, admin["token"])
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	// Tokens created with the old password stop working
+	assert.Equal(t, http.StatusUnauthorized, getJSON("/tasks", pat).Code)
+
+	resp = postJSON("/auth/login", map[string]string{"email": member["email"], "password": testPass}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	token := tokenFromLastMail(t, member["email"])
+	resp = postJSON("/auth/password/reset", map[string]string{"token": token, "password": "brandnewpass"}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = postJSON("/auth/login", map[string]string{"email": member["email"], "password": "brandnewpass"}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// Admins cannot lock themselves out
+	resp = postJSON("/admin/users/"+userIDFromToken(t, admin["token"])+"/password-reset", nil, admin["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, http.StatusOK, getJSON("/me", admin["token"]).Code)
+}
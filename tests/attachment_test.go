@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+// pngHeader makes content sniff as image/png.
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+// uploadAttachment uploads content as a multipart file to a task.
+func uploadAttachment(taskID string, fileName string, content []byte, token string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", fileName)
+	part.Write(content)
+	form.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, "/tasks/"+taskID+"/attachments", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	Router.ServeHTTP(resp, req)
+	return resp
+}
+
+// downloadAttachment downloads an attachment, optionally only the given byte range.
+func downloadAttachment(taskID string, attachmentID string, byteRange string, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodGet, "/tasks/"+taskID+"/attachments/"+attachmentID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+	resp := httptest.NewRecorder()
+	Router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestAttachments(t *testing.T) {
+	creator := registerAndLogin(t, "attachments")
+	assignee := registerAndLogin(t, "attachments-assignee")
+	taskID := createAssignedTask(t, creator["token"], userIDFromToken(t, assignee["token"]))
+
+	content := []byte(pngHeader + "not really an image, but it sniffs as one")
+	resp := uploadAttachment(taskID, "../Screen shot.png", content, assignee["token"])
+	if !assert.Equal(t, http.StatusCreated, resp.Code, resp.Body.String()) {
+		return
+	}
+	var attachment dto.AttachmentResponse
+	json.Unmarshal(resp.Body.Bytes(), &attachment)
+	assert.Equal(t, "Screen shot.png", attachment.FileName)
+	assert.Equal(t, "image/png", attachment.ContentType)
+	assert.EqualValues(t, len(content), attachment.Size)
+	assert.Len(t, attachment.SHA256, 64)
+
+	resp = getJSON("/tasks/"+taskID+"/attachments", creator["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var list []dto.AttachmentResponse
+	json.Unmarshal(resp.Body.Bytes(), &list)
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, attachment.ID, list[0].ID)
+	}
+
+	// Downloads are never rendered inline
+	resp = downloadAttachment(taskID, attachment.ID, "", creator["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, content, resp.Body.Bytes())
+	assert.Equal(t, "image/png", resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Header().Get("Content-Disposition"), "attachment")
+	assert.Equal(t, "bytes", resp.Header().Get("Accept-Ranges"))
+
+	// Range requests resume downloads
+	resp = downloadAttachment(taskID, attachment.ID, "bytes=8-19", creator["token"])
+	assert.Equal(t, http.StatusPartialContent, resp.Code)
+	assert.Equal(t, content[8:20], resp.Body.Bytes())
+	resp = downloadAttachment(taskID, attachment.ID, "bytes=-5", creator["token"])
+	assert.Equal(t, http.StatusPartialContent, resp.Code)
+	assert.Equal(t, content[len(content)-5:], resp.Body.Bytes())
+	resp = downloadAttachment(taskID, attachment.ID, "bytes=1000-", creator["token"])
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, resp.Code)
+
+	// Other users cannot see the task's attachments
+	outsider := registerAndLogin(t, "attachments-outsider")
+	resp = downloadAttachment(taskID, attachment.ID, "", outsider["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	resp = uploadAttachment(taskID, "notes.txt", []byte("hello"), outsider["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// The task's creator may delete attachments uploaded by others
+	resp = sendJSON(http.MethodDelete, "/tasks/"+taskID+"/attachments/"+attachment.ID, nil, creator["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	resp = downloadAttachment(taskID, attachment.ID, "", creator["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestAttachmentDeletePermissions(t *testing.T) {
+	creator := registerAndLogin(t, "attachments-owner")
+	assignee := registerAndLogin(t, "attachments-uploader")
+	taskID := createAssignedTask(t, creator["token"], userIDFromToken(t, assignee["token"]))
+
+	resp := uploadAttachment(taskID, "spec.txt", []byte("The creator's spec"), creator["token"])
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	var attachment dto.AttachmentResponse
+	json.Unmarshal(resp.Body.Bytes(), &attachment)
+
+	// Assignees can only delete their own uploads
+	resp = sendJSON(http.MethodDelete, "/tasks/"+taskID+"/attachments/"+attachment.ID, nil, assignee["token"])
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	resp = sendJSON(http.MethodDelete, "/tasks/"+taskID+"/attachments/"+attachment.ID, nil, creator["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
+
+func TestAttachmentLimits(t *testing.T) {
+	session := registerAndLogin(t, "attachments-limits")
+	taskID := createTestTask(t, session["token"], "medium", "pending")
+
+	// The type is detected from the contents, not the file name
+	resp := uploadAttachment(taskID, "invoice.pdf", []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00"), session["token"])
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+	resp = uploadAttachment(taskID, "page.txt", []byte("<html><script>alert(1)</script></html>"), session["token"])
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+
+	t.Setenv("ATTACHMENT_MAX_SIZE", "1024")
+	resp = uploadAttachment(taskID, "big.txt", bytes.Repeat([]byte("a"), 2048), session["token"])
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+	resp = uploadAttachment(taskID, "small.txt", bytes.Repeat([]byte("a"), 1024), session["token"])
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	resp = getJSON("/tasks/"+taskID+"/attachments", session["token"])
+	var list []dto.AttachmentResponse
+	json.Unmarshal(resp.Body.Bytes(), &list)
+	assert.Len(t, list, 1)
+}
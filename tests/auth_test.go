@@ -106,3 +106,79 @@ func TestLoginWithValidCredentials(t *testing.T) {
 	assert.NotEmpty(t, response["token"])
 	assert.Empty(t, response["error"])
 }
+
+func TestRefreshTokenRotation(t *testing.T) {
+	session := registerAndLogin(t, "refresh")
+	assert.NotEmpty(t, session["refresh_token"])
+
+	resp := postJSON("/auth/refresh", map[string]string{"refresh_token": session["refresh_token"]}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var rotated map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &rotated)
+
+	assert.NotEmpty(t, rotated["token"])
+	assert.NotEmpty(t, rotated["refresh_token"])
+	assert.NotEqual(t, session["refresh_token"], rotated["refresh_token"])
+
+	// The rotated token works for protected routes
+	req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+rotated["token"])
+	resp = httptest.NewRecorder()
+	Router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	session := registerAndLogin(t, "reuse")
+
+	resp := postJSON("/auth/refresh", map[string]string{"refresh_token": session["refresh_token"]}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var rotated map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &rotated)
+
+	// Replaying the old token is rejected...
+	resp = postJSON("/auth/refresh", map[string]string{"refresh_token": session["refresh_token"]}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	// ...and revokes the token that replaced it
+	resp = postJSON("/auth/refresh", map[string]string{"refresh_token": rotated["refresh_token"]}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func TestRefreshWithInvalidToken(t *testing.T) {
+	resp := postJSON("/auth/refresh", map[string]string{"refresh_token": "not-a-real-token"}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func TestLogoutRevokesAccessAndRefreshToken(t *testing.T) {
+	session := registerAndLogin(t, "logout")
+
+	resp := postJSON("/auth/logout", map[string]string{"refresh_token": session["refresh_token"]}, session["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	resp = getJSON("/tasks", session["token"])
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = postJSON("/auth/refresh", map[string]string{"refresh_token": session["refresh_token"]}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func TestLogoutAllRevokesEverySession(t *testing.T) {
+	first := registerAndLogin(t, "logoutall")
+
+	resp := postJSON("/auth/login", map[string]string{"email": first["email"], "password": testPass}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var second map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &second)
+
+	resp = postJSON("/auth/logout-all", nil, first["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	resp = postJSON("/auth/refresh", map[string]string{"refresh_token": second["refresh_token"]}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = getJSON("/tasks", first["token"])
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
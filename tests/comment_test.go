@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// createComment comments on a task and returns the comment.
+func createComment(t *testing.T, taskID string, body string, token string) dto.CommentResponse {
+	resp := postJSON("/tasks/"+taskID+"/comments", map[string]string{"body": body}, token)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Failed to create comment: %s", resp.Body.String())
+	}
+
+	var comment dto.CommentResponse
+	json.Unmarshal(resp.Body.Bytes(), &comment)
+	return comment
+}
+
+// listComments returns one page of a task's comments as seen by the given token.
+func listComments(t *testing.T, path string, token string) dto.CommentListResponse {
+	resp := getJSON(path, token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Failed to list comments: %s", resp.Body.String())
+	}
+
+	var page dto.CommentListResponse
+	json.Unmarshal(resp.Body.Bytes(), &page)
+	return page
+}
+
+func TestComments(t *testing.T) {
+	creator := registerAndLogin(t, "comments")
+	assignee := registerAndLogin(t, "comments-assignee")
+	taskID := createAssignedTask(t, creator["token"], userIDFromToken(t, assignee["token"]))
+
+	body := "Needs a **review** <script>alert(1)</script>\n\n- [ ] docs"
+	first := createComment(t, taskID, body, creator["token"])
+	assert.Equal(t, body, first.Body)
+	if assert.NotNil(t, first.Author.ID) {
+		assert.Equal(t, userIDFromToken(t, creator["token"]), *first.Author.ID)
+	}
+	assert.Nil(t, first.EditedAt)
+	createComment(t, taskID, "On it", assignee["token"])
+
+	page := listComments(t, "/tasks/"+taskID+"/comments", assignee["token"])
+	if assert.Len(t, page.Comments, 2) {
+		assert.Equal(t, first.ID, page.Comments[0].ID)
+		assert.Equal(t, "On it", page.Comments[1].Body)
+	}
+
+	// Only the author can edit or delete a comment
+	resp := sendJSON(http.MethodPatch, "/comments/"+first.ID, map[string]string{"body": "Hijacked"}, assignee["token"])
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	resp = sendJSON(http.MethodDelete, "/comments/"+first.ID, nil, assignee["token"])
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp = sendJSON(http.MethodPatch, "/comments/"+first.ID, map[string]string{"body": "Needs a review"}, creator["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var edited dto.CommentResponse
+	json.Unmarshal(resp.Body.Bytes(), &edited)
+	assert.Equal(t, "Needs a review", edited.Body)
+	assert.NotNil(t, edited.EditedAt)
+
+	resp = sendJSON(http.MethodDelete, "/comments/"+first.ID, nil, creator["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Len(t, listComments(t, "/tasks/"+taskID+"/comments", creator["token"]).Comments, 1)
+
+	resp = sendJSON(http.MethodDelete, "/comments/"+first.ID, nil, creator["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestCommentsVisibility(t *testing.T) {
+	session := registerAndLogin(t, "comments-visibility")
+	outsider := registerAndLogin(t, "comments-visibility-outsider")
+	taskID := createTestTask(t, session["token"], "medium", "pending")
+	comment := createComment(t, taskID, "Private note", session["token"])
+
+	resp := getJSON("/tasks/"+taskID+"/comments", outsider["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	resp = postJSON("/tasks/"+taskID+"/comments", map[string]string{"body": "Hello"}, outsider["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	resp = sendJSON(http.MethodPatch, "/comments/"+comment.ID, map[string]string{"body": "Hello"}, outsider["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	for _, payload := range []map[string]string{{"body": "  \n "}, {"body": strings.Repeat("a", 10001)}} {
+		resp = postJSON("/tasks/"+taskID+"/comments", payload, session["token"])
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	}
+	resp = sendJSON(http.MethodPatch, "/comments/not-a-uuid", map[string]string{"body": "Hello"}, session["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestCommentsPagination(t *testing.T) {
+	session := registerAndLogin(t, "comments-pagination")
+	taskID := createTestTask(t, session["token"], "medium", "pending")
+
+	var created []string
+	for _, body := range []string{"one", "two", "three"} {
+		created = append(created, createComment(t, taskID, body, session["token"]).ID)
+	}
+
+	var seen []string
+	path := "/tasks/" + taskID + "/comments?limit=2"
+	for {
+		page := listComments(t, path, session["token"])
+		for _, c := range page.Comments {
+			seen = append(seen, c.ID)
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		path = "/tasks/" + taskID + "/comments?limit=2&cursor=" + *page.NextCursor
+	}
+	assert.Equal(t, created, seen)
+
+	for _, query := range []string{"?limit=0", "?cursor=bogus"} {
+		resp := getJSON("/tasks/"+taskID+"/comments"+query, session["token"])
+		assert.Equal(t, http.StatusBadRequest, resp.Code, query)
+	}
+}
+
+func TestCommentsSurviveAccountPurge(t *testing.T) {
+	creator := registerAndLogin(t, "comments-purge-creator")
+	leaving := registerAndLogin(t, "comments-purge-leaving")
+	taskID := createAssignedTask(t, creator["token"], userIDFromToken(t, leaving["token"]))
+	comment := createComment(t, taskID, "Handing this back, see my notes", leaving["token"])
+
+	resp := sendJSON(http.MethodDelete, "/me", map[string]string{"password": testPass}, leaving["token"])
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	_, err := services.PurgeDeletedAccounts(time.Now().Add(365 * 24 * time.Hour))
+	assert.NoError(t, err)
+
+	// The comment stays in the thread, attributed to a deleted user
+	page := listComments(t, "/tasks/"+taskID+"/comments", creator["token"])
+	if assert.Len(t, page.Comments, 1) {
+		assert.Equal(t, comment.ID, page.Comments[0].ID)
+		assert.Equal(t, comment.Body, page.Comments[0].Body)
+		assert.Nil(t, page.Comments[0].Author.ID)
+		assert.Equal(t, "Deleted user", page.Comments[0].Author.FirstName)
+	}
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+// addDependency marks taskID as blocked by blockedByID.
+func addDependency(t *testing.T, taskID string, blockedByID string, token string) {
+	resp := postJSON("/tasks/"+taskID+"/dependencies", map[string]string{"blocked_by_id": blockedByID}, token)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Failed to add dependency: %s", resp.Body.String())
+	}
+}
+
+// referenceIDs returns the IDs of the referenced tasks.
+func referenceIDs(refs []dto.TaskReference) []string {
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, ref.ID)
+	}
+	return ids
+}
+
+func TestTaskDependencies(t *testing.T) {
+	session := registerAndLogin(t, "dependencies")
+	design := createTestTask(t, session["token"], "high", "pending")
+	build := createTestTask(t, session["token"], "medium", "pending")
+
+	addDependency(t, build, design, session["token"])
+	addDependency(t, build, design, session["token"])
+
+	task := getTask(t, build, session["token"])
+	assert.Equal(t, []string{design}, referenceIDs(task.BlockedBy))
+	assert.Equal(t, []string{build}, referenceIDs(getTask(t, design, session["token"]).Blocking))
+
+	// A blocked task cannot start or be completed until its blockers are complete, unless forced
+	for _, status := range []string{"in_progress", "complete"} {
+		resp := sendJSON(http.MethodPut, "/tasks/"+build, map[string]string{"status": status}, session["token"])
+		assert.Equal(t, http.StatusConflict, resp.Code, status)
+	}
+	resp := sendJSON(http.MethodPut, "/tasks/"+build, map[string]interface{}{"status": "in_progress", "force": true}, session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = sendJSON(http.MethodPut, "/tasks/"+design, map[string]string{"status": "complete"}, session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = sendJSON(http.MethodPut, "/tasks/"+build, map[string]string{"status": "complete"}, session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = sendJSON(http.MethodDelete, "/tasks/"+build+"/dependencies/"+design, nil, session["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Empty(t, getTask(t, build, session["token"]).BlockedBy)
+
+	resp = sendJSON(http.MethodDelete, "/tasks/"+build+"/dependencies/"+design, nil, session["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestTaskDependencyRules(t *testing.T) {
+	session := registerAndLogin(t, "dependencies-rules")
+	other := registerAndLogin(t, "dependencies-rules-other")
+	first := createTestTask(t, session["token"], "medium", "pending")
+	second := createTestTask(t, session["token"], "medium", "pending")
+	third := createTestTask(t, session["token"], "medium", "pending")
+
+	// Dependencies cannot form cycles, directly or through other tasks
+	addDependency(t, second, first, session["token"])
+	addDependency(t, third, second, session["token"])
+	for _, pair := range [][2]string{{first, first}, {first, second}, {first, third}} {
+		resp := postJSON("/tasks/"+pair[0]+"/dependencies", map[string]string{"blocked_by_id": pair[1]}, session["token"])
+		assert.Equal(t, http.StatusBadRequest, resp.Code, pair)
+	}
+
+	// Both tasks must be visible to the user
+	theirs := createTestTask(t, other["token"], "medium", "pending")
+	resp := postJSON("/tasks/"+first+"/dependencies", map[string]string{"blocked_by_id": theirs}, session["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	resp = postJSON("/tasks/"+theirs+"/dependencies", map[string]string{"blocked_by_id": first}, session["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	for _, payload := range []map[string]string{{}, {"blocked_by_id": "not-a-uuid"}} {
+		resp = postJSON("/tasks/"+first+"/dependencies", payload, session["token"])
+		assert.Equal(t, http.StatusBadRequest, resp.Code, payload)
+	}
+
+	// Deleting a task removes its dependencies
+	resp = sendJSON(http.MethodDelete, "/tasks/"+second, nil, session["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Empty(t, getTask(t, third, session["token"]).BlockedBy)
+}
+
+func TestTaskDependenciesFollowVisibility(t *testing.T) {
+	creator := registerAndLogin(t, "dependencies-visibility")
+	assignee := registerAndLogin(t, "dependencies-visibility-assignee")
+	admin := registerAdmin(t, "dependencies-visibility-admin")
+	private := createTestTask(t, creator["token"], "medium", "pending")
+	shared := createAssignedTask(t, creator["token"], userIDFromToken(t, assignee["token"]))
+	addDependency(t, shared, private, creator["token"])
+
+	// Blockers the user cannot read are left out
+	assert.Empty(t, getTask(t, shared, assignee["token"]).BlockedBy)
+	assert.Equal(t, []string{private}, referenceIDs(getTask(t, shared, admin["token"]).BlockedBy))
+
+	// Mentioned users can read the task, so they see it in full
+	resp := sendJSON(http.MethodPut, "/tasks/"+private, map[string]string{"description": "cc @" + assignee["email"]}, creator["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	blockers := getTask(t, shared, assignee["token"]).BlockedBy
+	if assert.Len(t, blockers, 1) {
+		assert.Equal(t, private, blockers[0].ID)
+		assert.Equal(t, "Test Task", blockers[0].Title)
+		assert.Equal(t, "pending", blockers[0].Status)
+	}
+}
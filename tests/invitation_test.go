@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+// invite sends an invitation as the given session and returns the invite token from the email.
+func invite(t *testing.T, token string, email string, role string) string {
+	resp := postJSON("/invitations", map[string]string{"email": email, "role": role}, token)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Failed to invite %s: %s", email, resp.Body.String())
+	}
+	return tokenFromLastMail(t, email)
+}
+
+// registerInvited registers email with an invite token.
+func registerInvited(email string, inviteToken string) *httptest.ResponseRecorder {
+	return postJSON("/auth/register", map[string]string{
+		"first_name":   "Invited",
+		"last_name":    "User",
+		"email":        email,
+		"password":     testPass,
+		"invite_token": inviteToken,
+	}, "")
+}
+
+func TestInvitationRegistration(t *testing.T) {
+	admin := registerAdmin(t, "invite-admin")
+	email := uniqueEmail("invitee")
+	inviteToken := invite(t, admin["token"], email, "viewer")
+
+	resp := getJSON("/auth/invitation?token="+inviteToken, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var invitation dto.InvitationResponse
+	json.Unmarshal(resp.Body.Bytes(), &invitation)
+	assert.Equal(t, email, invitation.Email)
+	assert.Equal(t, "viewer", invitation.Role)
+
+	// The invitation only works for the invited address
+	resp = registerInvited(uniqueEmail("someone-else"), inviteToken)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = registerInvited(email, inviteToken)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var tokens map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &tokens)
+
+	resp = getJSON("/me", tokens["token"])
+	var profile dto.UserResponse
+	json.Unmarshal(resp.Body.Bytes(), &profile)
+	assert.Equal(t, "viewer", profile.Role)
+	assert.True(t, profile.EmailVerified)
+
+	// Invite links are single-use
+	resp = getJSON("/auth/invitation?token="+inviteToken, "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = postJSON("/invitations", map[string]string{"email": email}, admin["token"])
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestInvitationPermissions(t *testing.T) {
+	member := registerAndLogin(t, "invite-member")
+
+	resp := postJSON("/invitations", map[string]string{"email": uniqueEmail("invitee"), "role": "admin"}, member["token"])
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp = postJSON("/invitations", map[string]string{"email": uniqueEmail("invitee"), "role": "superuser"}, member["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// Viewers cannot invite anyone
+	viewerEmail := uniqueEmail("invite-viewer")
+	resp = registerInvited(viewerEmail, invite(t, member["token"], viewerEmail, "viewer"))
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var viewer map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &viewer)
+	resp = postJSON("/invitations", map[string]string{"email": uniqueEmail("invitee")}, viewer["token"])
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+func TestRevokeInvitation(t *testing.T) {
+	member := registerAndLogin(t, "revoke-member")
+	other := registerAndLogin(t, "revoke-other")
+	email := uniqueEmail("revoked-invitee")
+	inviteToken := invite(t, member["token"], email, "")
+
+	resp := getJSON("/invitations", member["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var invitations []dto.InvitationResponse
+	json.Unmarshal(resp.Body.Bytes(), &invitations)
+	if assert.Len(t, invitations, 1) {
+		assert.Equal(t, email, invitations[0].Email)
+		assert.Equal(t, "member", invitations[0].Role)
+	}
+
+	// Members only see and manage their own invitations
+	resp = getJSON("/invitations", other["token"])
+	var othersInvitations []dto.InvitationResponse
+	json.Unmarshal(resp.Body.Bytes(), &othersInvitations)
+	assert.Empty(t, othersInvitations)
+
+	resp = sendJSON(http.MethodDelete, "/invitations/"+invitations[0].ID, nil, other["token"])
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp = sendJSON(http.MethodDelete, "/invitations/"+invitations[0].ID, nil, member["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	resp = registerInvited(email, inviteToken)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestInviteOnlyRegistration(t *testing.T) {
+	admin := registerAdmin(t, "invite-only-admin")
+	t.Setenv("REGISTRATION_MODE", "invite_only")
+
+	resp := postJSON("/auth/register", map[string]string{
+		"first_name": "Open",
+		"last_name":  "User",
+		"email":      uniqueEmail("uninvited"),
+		"password":   testPass,
+	}, "")
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	email := uniqueEmail("invite-only")
+	resp = registerInvited(email, invite(t, admin["token"], email, ""))
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	// Single sign-on cannot be used to bypass invitations either
+	provider := newFakeOIDCProvider(t)
+
+	ssoEmail := uniqueEmail("invite-only-sso")
+	provider.SetUser(fakeOIDCUser{Subject: "sub-" + ssoEmail, Email: ssoEmail, EmailVerified: true})
+	resp = oidcLogin(t)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	invite(t, admin["token"], ssoEmail, "viewer")
+	resp = oidcLogin(t)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var tokens map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &tokens)
+	resp = getJSON("/me", tokens["token"])
+	var profile dto.UserResponse
+	json.Unmarshal(resp.Body.Bytes(), &profile)
+	assert.Equal(t, "viewer", profile.Role)
+}
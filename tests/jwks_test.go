@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsymmetricSigningAndJWKS(t *testing.T) {
+	// Sessions started before the switch keep working while JWT_SECRET is set
+	legacy := registerAndLogin(t, "jwks-legacy")
+
+	dir := t.TempDir()
+	kid, err := utils.GenerateSigningKey(dir, "ed25519")
+	assert.NoError(t, err)
+	t.Setenv("JWT_KEYS_DIR", dir)
+
+	session := registerAndLogin(t, "jwks")
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(session["token"], &utils.Claims{})
+	assert.NoError(t, err)
+	assert.Equal(t, kid, parsed.Header["kid"])
+	assert.Equal(t, "EdDSA", parsed.Header["alg"])
+
+	resp := getJSON("/me", session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = getJSON("/me", legacy["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// Other services can verify tokens with the published key alone
+	resp = getJSON("/.well-known/jwks.json", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var jwks dto.JWKSResponse
+	json.Unmarshal(resp.Body.Bytes(), &jwks)
+	if assert.Len(t, jwks.Keys, 1) {
+		key := jwks.Keys[0]
+		assert.Equal(t, kid, key.Kid)
+		assert.Equal(t, "OKP", key.Kty)
+
+		x, err := base64.RawURLEncoding.DecodeString(key.X)
+		assert.NoError(t, err)
+
+		_, err = jwt.ParseWithClaims(session["token"], &utils.Claims{}, func(*jwt.Token) (interface{}, error) {
+			return ed25519.PublicKey(x), nil
+		}, jwt.WithValidMethods([]string{"EdDSA"}))
+		assert.NoError(t, err)
+	}
+}
+
+func TestTokenWithUnknownKeyIsRejected(t *testing.T) {
+	dir := t.TempDir()
+	_, err := utils.GenerateSigningKey(dir, "ed25519")
+	assert.NoError(t, err)
+	t.Setenv("JWT_KEYS_DIR", dir)
+
+	session := registerAndLogin(t, "jwks-unknown")
+
+	// Once the signing key is removed from the key set, its tokens are no longer accepted
+	rotated := t.TempDir()
+	_, err = utils.GenerateSigningKey(rotated, "ed25519")
+	assert.NoError(t, err)
+	t.Setenv("JWT_KEYS_DIR", rotated)
+
+	resp := getJSON("/me", session["token"])
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
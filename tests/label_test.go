@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+// createLabel creates a label with the given name and scope and returns it.
+func createLabel(t *testing.T, token string, name string, scope string) dto.LabelResponse {
+	resp := postJSON("/labels", map[string]string{"name": name, "color": "#2563EB", "scope": scope}, token)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Failed to create label: %s", resp.Body.String())
+	}
+
+	var label dto.LabelResponse
+	json.Unmarshal(resp.Body.Bytes(), &label)
+	return label
+}
+
+// uniqueLabelName returns a team label name that has not been used by previous test runs.
+func uniqueLabelName(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+}
+
+// taskLabelNames returns the names of the labels of a task, as seen by the given token.
+func taskLabelNames(t *testing.T, taskID string, token string) []string {
+	resp := getJSON("/tasks/"+taskID, token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Failed to get task: %s", resp.Body.String())
+	}
+
+	var task dto.TaskResponse
+	json.Unmarshal(resp.Body.Bytes(), &task)
+	names := make([]string, 0, len(task.Labels))
+	for _, l := range task.Labels {
+		names = append(names, l.Name)
+	}
+	return names
+}
+
+func TestLabelCRUD(t *testing.T) {
+	session := registerAndLogin(t, "labels-crud")
+	other := registerAndLogin(t, "labels-crud-other")
+
+	personal := createLabel(t, session["token"], "backend", "")
+	assert.Equal(t, "personal", personal.Scope)
+	assert.Equal(t, "#2563eb", personal.Color)
+
+	// Names are unique per scope, ignoring case; other users have their own personal labels
+	resp := postJSON("/labels", map[string]string{"name": "Backend"}, session["token"])
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	createLabel(t, other["token"], "backend", "personal")
+
+	team := createLabel(t, session["token"], uniqueLabelName("team"), "team")
+	assert.Equal(t, "team", team.Scope)
+
+	// Other users see team labels but not personal ones
+	resp = getJSON("/labels", other["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), team.ID)
+	assert.NotContains(t, resp.Body.String(), personal.ID)
+
+	resp = sendJSON(http.MethodPut, "/labels/"+personal.ID, map[string]string{"name": "api"}, other["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// Team labels can only be changed by their creator or an admin
+	resp = sendJSON(http.MethodPut, "/labels/"+team.ID, map[string]string{"color": "#000000"}, other["token"])
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp = sendJSON(http.MethodPut, "/labels/"+personal.ID, map[string]string{"name": "api", "color": "#16a34a"}, session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"name":"api"`)
+
+	for _, payload := range []map[string]string{
+		{"name": ""},
+		{"name": "too-long-label-name-that-goes-on-and-on-and-on-and-on"},
+		{"name": "valid", "color": "blue"},
+		{"name": "valid", "scope": "global"},
+	} {
+		resp = postJSON("/labels", payload, session["token"])
+		assert.Equal(t, http.StatusBadRequest, resp.Code, payload)
+	}
+
+	admin := registerAdmin(t, "labels-crud-admin")
+	resp = sendJSON(http.MethodDelete, "/labels/"+team.ID, nil, admin["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	resp = sendJSON(http.MethodDelete, "/labels/"+team.ID, nil, session["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestTaskLabels(t *testing.T) {
+	creator := registerAndLogin(t, "labels-tasks")
+	assignee := registerAndLogin(t, "labels-tasks-assignee")
+	taskID := createAssignedTask(t, creator["token"], userIDFromToken(t, assignee["token"]))
+
+	team := createLabel(t, creator["token"], uniqueLabelName("backend"), "team")
+	private := createLabel(t, creator["token"], "later", "personal")
+	theirs := createLabel(t, assignee["token"], "mine", "personal")
+
+	for _, labelID := range []string{team.ID, private.ID, team.ID} {
+		resp := sendJSON(http.MethodPut, "/tasks/"+taskID+"/labels/"+labelID, nil, creator["token"])
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+	}
+
+	// The assignee can label the task too, but not with labels they cannot see
+	resp := sendJSON(http.MethodPut, "/tasks/"+taskID+"/labels/"+theirs.ID, nil, assignee["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	resp = sendJSON(http.MethodPut, "/tasks/"+taskID+"/labels/"+private.ID, nil, assignee["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// Each user only sees the team labels and their own personal labels
+	assert.ElementsMatch(t, []string{team.Name, "later"}, taskLabelNames(t, taskID, creator["token"]))
+	assert.ElementsMatch(t, []string{team.Name, "mine"}, taskLabelNames(t, taskID, assignee["token"]))
+
+	outsider := registerAndLogin(t, "labels-tasks-outsider")
+	resp = sendJSON(http.MethodPut, "/tasks/"+taskID+"/labels/"+team.ID, nil, outsider["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// Users mentioned in the task can read its labels but not change them
+	mentioned := registerAndLogin(t, "labels-tasks-mentioned")
+	resp = sendJSON(http.MethodPut, "/tasks/"+taskID, map[string]string{"description": "cc @" + mentioned["email"]}, creator["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.ElementsMatch(t, []string{team.Name}, taskLabelNames(t, taskID, mentioned["token"]))
+	resp = sendJSON(http.MethodPut, "/tasks/"+taskID+"/labels/"+team.ID, nil, mentioned["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	resp = sendJSON(http.MethodDelete, "/tasks/"+taskID+"/labels/"+team.ID, nil, mentioned["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = sendJSON(http.MethodDelete, "/tasks/"+taskID+"/labels/"+private.ID, nil, creator["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, []string{team.Name}, taskLabelNames(t, taskID, creator["token"]))
+
+	// Deleting a label removes it from its tasks
+	resp = sendJSON(http.MethodDelete, "/labels/"+team.ID, nil, creator["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Empty(t, taskLabelNames(t, taskID, creator["token"]))
+}
+
+func TestGetTasksLabelFilter(t *testing.T) {
+	session := registerAndLogin(t, "labels-filter")
+	backend := createLabel(t, session["token"], "backend", "personal")
+	urgent := createLabel(t, session["token"], "urgent", "personal")
+
+	both := createTestTask(t, session["token"], "high", "pending")
+	backendOnly := createTestTask(t, session["token"], "medium", "pending")
+	unlabeled := createTestTask(t, session["token"], "low", "pending")
+	for taskID, labels := range map[string][]string{both: {backend.ID, urgent.ID}, backendOnly: {backend.ID}} {
+		for _, labelID := range labels {
+			resp := sendJSON(http.MethodPut, "/tasks/"+taskID+"/labels/"+labelID, nil, session["token"])
+			assert.Equal(t, http.StatusNoContent, resp.Code)
+		}
+	}
+
+	ids := listTaskIDs(t, "/tasks?label="+backend.ID+","+urgent.ID, session["token"])
+	assert.ElementsMatch(t, []string{both, backendOnly}, ids)
+	assert.NotContains(t, ids, unlabeled)
+
+	ids = listTaskIDs(t, "/tasks?label="+backend.ID+","+urgent.ID+"&label_match=all", session["token"])
+	assert.Equal(t, []string{both}, ids)
+
+	for _, path := range []string{"/tasks?label=backend", "/tasks?label=" + backend.ID + "&label_match=some"} {
+		resp := getJSON(path, session["token"])
+		assert.Equal(t, http.StatusBadRequest, resp.Code, path)
+	}
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/lockout"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginBackoffAfterRepeatedFailures(t *testing.T) {
+	session := registerAndLogin(t, "bruteforce")
+	wrong := map[string]string{"email": session["email"], "password": "wrongpassword"}
+
+	// The first three failures are free; the fourth starts backing off
+	for i := 0; i < 4; i++ {
+		resp := postJSON("/auth/login", wrong, "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	}
+
+	// Even the right password is rejected while backing off
+	resp := postJSON("/auth/login", map[string]string{"email": session["email"], "password": testPass}, "")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+
+	retryAfter, err := strconv.Atoi(resp.Header().Get("Retry-After"))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, retryAfter, 1)
+}
+
+func TestLockoutStores(t *testing.T) {
+	policy := lockout.Policy{
+		FreeAttempts:    2,
+		MaxFailures:     5,
+		BaseDelay:       time.Second,
+		LockoutDuration: time.Minute,
+		Window:          time.Minute,
+	}
+
+	stores := map[string]lockout.Store{
+		"memory":   lockout.NewMemoryStore(),
+		"postgres": lockout.NewPostgresStore(database.DB),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			key := fmt.Sprintf("email:%s", uniqueEmail("lockout-"+name))
+			now := time.Now()
+
+			// Free attempts are not delayed, up to and including the last one
+			state, err := store.RegisterFailure(key, policy, now)
+			assert.NoError(t, err)
+			assert.Zero(t, state.RetryAfter(now))
+			state, _ = store.RegisterFailure(key, policy, now)
+			assert.Equal(t, 2, state.Failures)
+			assert.Zero(t, state.RetryAfter(now))
+
+			// Then the delay doubles
+			state, _ = store.RegisterFailure(key, policy, now)
+			assert.Equal(t, time.Second, state.RetryAfter(now))
+			state, _ = store.RegisterFailure(key, policy, now)
+			assert.Equal(t, 2*time.Second, state.RetryAfter(now))
+			assert.False(t, policy.LockedOut(state))
+
+			// Until the key is locked out
+			state, _ = store.RegisterFailure(key, policy, now)
+			assert.True(t, policy.LockedOut(state))
+			assert.Equal(t, time.Minute, state.RetryAfter(now))
+
+			stored, err := store.Get(key)
+			assert.NoError(t, err)
+			assert.Equal(t, 5, stored.Failures)
+
+			// Failures outside the window start over once the lock has expired
+			later := now.Add(3 * time.Minute)
+			state, _ = store.RegisterFailure(key, policy, later)
+			assert.Equal(t, 1, state.Failures)
+
+			assert.NoError(t, store.Reset(key))
+			stored, _ = store.Get(key)
+			assert.Equal(t, 0, stored.Failures)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+// setUsername picks a unique username for the user and returns it.
+func setUsername(t *testing.T, token string) string {
+	username := fmt.Sprintf("user%d", time.Now().UnixNano())
+	resp := sendJSON(http.MethodPatch, "/me", map[string]string{"username": username}, token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Failed to set username: %s", resp.Body.String())
+	}
+	return username
+}
+
+// listNotifications returns the notifications of the user at path.
+func listNotifications(t *testing.T, path string, token string) dto.NotificationListResponse {
+	resp := getJSON(path, token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Failed to list notifications: %s", resp.Body.String())
+	}
+
+	var page dto.NotificationListResponse
+	json.Unmarshal(resp.Body.Bytes(), &page)
+	return page
+}
+
+func TestUsername(t *testing.T) {
+	session := registerAndLogin(t, "username")
+	other := registerAndLogin(t, "username-other")
+
+	username := setUsername(t, session["token"])
+	resp := getJSON("/me", session["token"])
+	assert.Contains(t, resp.Body.String(), `"username":"`+username+`"`)
+
+	// Usernames are unique, ignoring case
+	resp = sendJSON(http.MethodPatch, "/me", map[string]string{"username": strings.ToUpper(username)}, other["token"])
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	for _, username := range []string{"ab", "-dash", "trailing.", "with space", "at@sign"} {
+		resp = sendJSON(http.MethodPatch, "/me", map[string]string{"username": username}, other["token"])
+		assert.Equal(t, http.StatusBadRequest, resp.Code, username)
+	}
+}
+
+func TestMentionNotifications(t *testing.T) {
+	author := registerAndLogin(t, "mentions")
+	byName := registerAndLogin(t, "mentions-name")
+	byEmail := registerAndLogin(t, "mentions-email")
+	username := setUsername(t, byName["token"])
+	taskID := createTestTask(t, author["token"], "medium", "pending")
+
+	// Mentioned users are notified and can read the task, but not change it
+	comment := createComment(t, taskID, fmt.Sprintf("@%s and @%s, please review. Not me@example.com or @%s.",
+		strings.ToUpper(username), byEmail["email"], author["email"]), author["token"])
+
+	page := listNotifications(t, "/me/notifications", byName["token"])
+	if assert.Len(t, page.Notifications, 1) {
+		n := page.Notifications[0]
+		assert.Equal(t, "comment_mention", n.Kind)
+		assert.Equal(t, taskID, n.Task.ID)
+		assert.Equal(t, comment.ID, *n.CommentID)
+		assert.Equal(t, userIDFromToken(t, author["token"]), n.Actor.ID)
+		assert.Nil(t, n.ReadAt)
+	}
+	assert.Equal(t, int64(1), page.UnreadCount)
+	assert.Len(t, listNotifications(t, "/me/notifications", byEmail["token"]).Notifications, 1)
+	assert.Empty(t, listNotifications(t, "/me/notifications", author["token"]).Notifications)
+
+	resp := getJSON("/tasks/"+taskID, byName["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = getJSON("/tasks/"+taskID+"/comments", byName["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = postJSON("/tasks/"+taskID+"/comments", map[string]string{"body": "Looks good"}, byName["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	resp = sendJSON(http.MethodPut, "/tasks/"+taskID, map[string]string{"status": "complete"}, byName["token"])
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	// Edits only notify users who were not mentioned before
+	resp = sendJSON(http.MethodPatch, "/comments/"+comment.ID, map[string]string{"body": "@" + username + " please review"}, author["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, listNotifications(t, "/me/notifications", byName["token"]).Notifications, 1)
+
+	resp = sendJSON(http.MethodPut, "/tasks/"+taskID, map[string]string{"description": "Owner: @" + username}, author["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	page = listNotifications(t, "/me/notifications", byName["token"])
+	if assert.Len(t, page.Notifications, 2) {
+		assert.Equal(t, "task_mention", page.Notifications[0].Kind)
+		assert.Nil(t, page.Notifications[0].CommentID)
+	}
+}
+
+func TestNotificationInbox(t *testing.T) {
+	author := registerAndLogin(t, "inbox-author")
+	session := registerAndLogin(t, "inbox")
+	other := registerAndLogin(t, "inbox-other")
+	taskID := createTestTask(t, author["token"], "medium", "pending")
+	for i := 0; i < 3; i++ {
+		createComment(t, taskID, fmt.Sprintf("Ping %d @%s", i, session["email"]), author["token"])
+	}
+
+	page := listNotifications(t, "/me/notifications?limit=2", session["token"])
+	assert.Len(t, page.Notifications, 2)
+	assert.Equal(t, int64(3), page.UnreadCount)
+	if assert.NotNil(t, page.NextCursor) {
+		rest := listNotifications(t, "/me/notifications?limit=2&cursor="+*page.NextCursor, session["token"])
+		assert.Len(t, rest.Notifications, 1)
+		assert.Nil(t, rest.NextCursor)
+	}
+
+	// Notifications belong to their recipient
+	first := page.Notifications[0].ID
+	resp := postJSON("/me/notifications/"+first+"/read", nil, other["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = postJSON("/me/notifications/"+first+"/read", nil, session["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	unread := listNotifications(t, "/me/notifications?unread=true", session["token"])
+	assert.Len(t, unread.Notifications, 2)
+	assert.Equal(t, int64(2), unread.UnreadCount)
+
+	// Read-only personal access tokens can list notifications but not mark them read
+	readOnly := createPersonalAccessToken(t, session["token"], "tasks:read")
+	assert.Len(t, listNotifications(t, "/me/notifications?unread=true", readOnly).Notifications, 2)
+	resp = postJSON("/me/notifications/read-all", nil, readOnly)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	resp = postJSON("/me/notifications/"+unread.Notifications[0].ID+"/read", nil, readOnly)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp = postJSON("/me/notifications/read-all", nil, createPersonalAccessToken(t, session["token"], "tasks:write"))
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Empty(t, listNotifications(t, "/me/notifications?unread=true", session["token"]).Notifications)
+	assert.Len(t, listNotifications(t, "/me/notifications", session["token"]).Notifications, 3)
+
+	for _, path := range []string{"/me/notifications?unread=maybe", "/me/notifications?cursor=bogus"} {
+		resp = getJSON(path, session["token"])
+		assert.Equal(t, http.StatusBadRequest, resp.Code, path)
+	}
+	resp = postJSON("/me/notifications/not-a-uuid/read", nil, session["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,230 @@
+package tests
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kfeuerschvenger/task-manager-api/oidc"
+)
+
+// Credentials the API is registered with at the fake provider.
+const (
+	fakeOIDCClientID     = "task-manager"
+	fakeOIDCClientSecret = "fake-secret"
+	fakeOIDCKeyID        = "fake-key"
+)
+
+// fakeOIDCUser is the identity the fake provider asserts for the next login.
+type fakeOIDCUser struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+}
+
+// fakeOIDCGrant is an authorization code issued by the fake provider.
+type fakeOIDCGrant struct {
+	user          fakeOIDCUser
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+}
+
+// fakeOIDCProvider is an in-process OpenID Provider. It approves every authorization request
+// for the current user, enforces PKCE at the token endpoint and signs ID tokens with an RSA key.
+type fakeOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	user   fakeOIDCUser
+	grants map[string]fakeOIDCGrant
+}
+
+// newFakeOIDCProvider starts a fake provider and registers it with the API as "fake" for the duration of the test.
+func newFakeOIDCProvider(t *testing.T) *fakeOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate provider key: %v", err)
+	}
+
+	p := &fakeOIDCProvider{key: key, grants: map[string]fakeOIDCGrant{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	mux.HandleFunc("/jwks", p.jwks)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	t.Setenv("OIDC_PROVIDERS", "fake")
+	t.Setenv("OIDC_FAKE_ISSUER", p.server.URL)
+	t.Setenv("OIDC_FAKE_CLIENT_ID", fakeOIDCClientID)
+	t.Setenv("OIDC_FAKE_CLIENT_SECRET", fakeOIDCClientSecret)
+	return p
+}
+
+// SetUser sets the identity asserted by subsequent logins.
+func (p *fakeOIDCProvider) SetUser(user fakeOIDCUser) {
+	p.mu.Lock()
+	p.user = user
+	p.mu.Unlock()
+}
+
+func (p *fakeOIDCProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeFakeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 p.server.URL,
+		"authorization_endpoint": p.server.URL + "/authorize",
+		"token_endpoint":         p.server.URL + "/token",
+		"jwks_uri":               p.server.URL + "/jwks",
+	})
+}
+
+// authorize approves the request without a login page and redirects back with a code.
+func (p *fakeOIDCProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != fakeOIDCClientID ||
+		q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" || q.Get("nonce") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code, err := oidc.RandomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	p.mu.Lock()
+	p.grants[code] = fakeOIDCGrant{
+		user:          p.user,
+		redirectURI:   q.Get("redirect_uri"),
+		nonce:         q.Get("nonce"),
+		codeChallenge: q.Get("code_challenge"),
+	}
+	p.mu.Unlock()
+
+	callback, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	params := callback.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	callback.RawQuery = params.Encode()
+	http.Redirect(w, r, callback.String(), http.StatusFound)
+}
+
+// token redeems a code once, checking the client credentials, redirect URI and PKCE verifier.
+func (p *fakeOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeFakeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	if r.PostForm.Get("client_id") != fakeOIDCClientID || r.PostForm.Get("client_secret") != fakeOIDCClientSecret {
+		writeFakeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	p.mu.Lock()
+	grant, ok := p.grants[r.PostForm.Get("code")]
+	delete(p.grants, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	if !ok || grant.redirectURI != r.PostForm.Get("redirect_uri") ||
+		oidc.S256Challenge(r.PostForm.Get("code_verifier")) != grant.codeChallenge {
+		writeFakeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            p.server.URL,
+		"aud":            fakeOIDCClientID,
+		"sub":            grant.user.Subject,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          grant.nonce,
+		"email":          grant.user.Email,
+		"email_verified": grant.user.EmailVerified,
+		"given_name":     grant.user.GivenName,
+		"family_name":    grant.user.FamilyName,
+	})
+	idToken.Header["kid"] = fakeOIDCKeyID
+	signed, err := idToken.SignedString(p.key)
+	if err != nil {
+		writeFakeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": "unused",
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     signed,
+	})
+}
+
+func (p *fakeOIDCProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": fakeOIDCKeyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// oidcCallbackPath starts a login with the fake provider through the API and follows the
+// provider's redirect, returning the API callback path (with its query) without calling it.
+func oidcCallbackPath(t *testing.T) string {
+	resp := getJSON("/auth/oidc/fake/login", "")
+	if resp.Code != http.StatusFound {
+		t.Fatalf("Expected a redirect to the provider, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	authorize, err := client.Get(resp.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("Authorization request failed: %v", err)
+	}
+	authorize.Body.Close()
+	if authorize.StatusCode != http.StatusFound {
+		t.Fatalf("Provider rejected the authorization request: %d", authorize.StatusCode)
+	}
+
+	callback, err := url.Parse(authorize.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("Invalid callback URL: %v", err)
+	}
+	return callback.RequestURI()
+}
+
+// oidcLogin signs in with the fake provider as its current user and returns the callback response.
+func oidcLogin(t *testing.T) *httptest.ResponseRecorder {
+	return getJSON(oidcCallbackPath(t), "")
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOIDCLoginCreatesVerifiedUser(t *testing.T) {
+	provider := newFakeOIDCProvider(t)
+	email := uniqueEmail("oidc-new")
+	provider.SetUser(fakeOIDCUser{Subject: "sub-" + email, Email: email, EmailVerified: true, GivenName: "Ada", FamilyName: "Lovelace"})
+
+	resp := oidcLogin(t)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var tokens map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &tokens)
+	assert.NotEmpty(t, tokens["refresh_token"])
+
+	resp = getJSON("/me", tokens["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var profile dto.UserResponse
+	json.Unmarshal(resp.Body.Bytes(), &profile)
+	assert.Equal(t, email, profile.Email)
+	assert.True(t, profile.EmailVerified)
+	assert.Equal(t, "Ada", profile.FirstName)
+	assert.Equal(t, "Lovelace", profile.LastName)
+
+	// Signing in again uses the linked identity
+	resp = oidcLogin(t)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var again map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &again)
+	assert.Equal(t, profile.ID, userIDFromToken(t, again["token"]))
+}
+
+func TestOIDCLoginLinksVerifiedAccount(t *testing.T) {
+	provider := newFakeOIDCProvider(t)
+	session := registerAndLogin(t, "oidc-link")
+	resp := getJSON("/auth/verify?token="+tokenFromLastMail(t, session["email"]), "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	provider.SetUser(fakeOIDCUser{Subject: "sub-" + session["email"], Email: session["email"], EmailVerified: true})
+
+	resp = oidcLogin(t)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var tokens map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &tokens)
+	assert.Equal(t, userIDFromToken(t, session["token"]), userIDFromToken(t, tokens["token"]))
+
+	// The password keeps working
+	resp = postJSON("/auth/login", map[string]string{"email": session["email"], "password": testPass}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestOIDCLoginClaimsUnverifiedAccount(t *testing.T) {
+	provider := newFakeOIDCProvider(t)
+	session := registerAndLogin(t, "oidc-claim")
+	provider.SetUser(fakeOIDCUser{Subject: "sub-" + session["email"], Email: session["email"], EmailVerified: true})
+
+	resp := oidcLogin(t)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var tokens map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &tokens)
+	assert.Equal(t, userIDFromToken(t, session["token"]), userIDFromToken(t, tokens["token"]))
+
+	// Whoever registered without proving ownership of the address loses access
+	resp = postJSON("/auth/login", map[string]string{"email": session["email"], "password": testPass}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	resp = postJSON("/auth/refresh", map[string]string{"refresh_token": session["refresh_token"]}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func TestOIDCLoginRequiresVerifiedEmail(t *testing.T) {
+	provider := newFakeOIDCProvider(t)
+	session := registerAndLogin(t, "oidc-unverified")
+	provider.SetUser(fakeOIDCUser{Subject: "sub-" + session["email"], Email: session["email"], EmailVerified: false})
+
+	resp := oidcLogin(t)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	// The account is left untouched
+	resp = postJSON("/auth/login", map[string]string{"email": session["email"], "password": testPass}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestOIDCCallbackState(t *testing.T) {
+	provider := newFakeOIDCProvider(t)
+	email := uniqueEmail("oidc-state")
+	provider.SetUser(fakeOIDCUser{Subject: "sub-" + email, Email: email, EmailVerified: true})
+
+	resp := getJSON("/auth/oidc/fake/callback?code=anything&state=forged", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	callback := oidcCallbackPath(t)
+	resp = getJSON(callback, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// Each state can be used once
+	resp = getJSON(callback, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = getJSON("/auth/oidc/fake/callback?error=access_denied&state=x", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func TestOIDCUnknownProvider(t *testing.T) {
+	newFakeOIDCProvider(t)
+
+	resp := getJSON("/auth/oidc/unknown/login", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
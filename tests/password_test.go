@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordResetFlow(t *testing.T) {
+	session := registerAndLogin(t, "reset")
+	email := session["email"]
+	pat := createPersonalAccessToken(t, session["token"], "tasks:read")
+
+	resp := postJSON("/auth/password/forgot", map[string]string{"email": email}, "")
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	token := tokenFromLastMail(t, email)
+
+	resp = postJSON("/auth/password/reset", map[string]string{"token": token, "password": "brandnewpass"}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// The token is single-use
+	resp = postJSON("/auth/password/reset", map[string]string{"token": token, "password": "anotherpass"}, "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// Existing sessions and personal access tokens are revoked
+	resp = postJSON("/auth/refresh", map[string]string{"refresh_token": session["refresh_token"]}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, http.StatusUnauthorized, getJSON("/tasks", pat).Code)
+
+	// Only the new password works
+	resp = postJSON("/auth/login", map[string]string{"email": email, "password": testPass}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = postJSON("/auth/login", map[string]string{"email": email, "password": "brandnewpass"}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestForgotPasswordDoesNotRevealUnknownEmail(t *testing.T) {
+	email := uniqueEmail("nobody")
+
+	// Unknown addresses take as long as registered ones
+	start := time.Now()
+	resp := postJSON("/auth/password/forgot", map[string]string{"email": email}, "")
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+
+	_, sent := Outbox.LastTo(email)
+	assert.False(t, sent)
+}
+
+func TestForgotPasswordIsRateLimited(t *testing.T) {
+	t.Setenv("PASSWORD_RESET_RESPONSE_TIME", "1ms")
+	email := registerAndLogin(t, "reset-limit")["email"]
+
+	for i := 0; i < 4; i++ {
+		resp := postJSON("/auth/password/forgot", map[string]string{"email": email}, "")
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+	}
+
+	// Further requests for the address are rejected, whether or not it is registered
+	resp := postJSON("/auth/password/forgot", map[string]string{"email": email}, "")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.NotEmpty(t, resp.Header().Get("Retry-After"))
+
+	unknown := uniqueEmail("reset-limit-nobody")
+	for i := 0; i < 4; i++ {
+		postJSON("/auth/password/forgot", map[string]string{"email": unknown}, "")
+	}
+	resp = postJSON("/auth/password/forgot", map[string]string{"email": unknown}, "")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+}
+
+func TestResetPasswordWithInvalidToken(t *testing.T) {
+	resp := postJSON("/auth/password/reset", map[string]string{"token": "bogus", "password": "brandnewpass"}, "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
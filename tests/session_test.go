@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// loginFrom logs in from a client with the given user agent and returns the login response body.
+func loginFrom(t *testing.T, email string, userAgent string) map[string]string {
+	body, _ := json.Marshal(map[string]string{"email": email, "password": testPass})
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp := httptest.NewRecorder()
+	Router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Failed to log in: %s", resp.Body.String())
+	}
+
+	var data map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &data)
+	return data
+}
+
+// listSessions returns the sessions visible to the given access token.
+func listSessions(t *testing.T, token string) []dto.SessionResponse {
+	resp := getJSON("/me/sessions", token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Failed to list sessions: %s", resp.Body.String())
+	}
+
+	var sessions []dto.SessionResponse
+	json.Unmarshal(resp.Body.Bytes(), &sessions)
+	return sessions
+}
+
+func TestListSessions(t *testing.T) {
+	first := registerAndLogin(t, "sessions-list")
+	laptop := loginFrom(t, first["email"], "Laptop Browser/1.0")
+
+	getJSON("/me", first["token"])
+	services.FlushSessionActivity()
+
+	// Registering and each login start their own session
+	sessions := listSessions(t, laptop["token"])
+	if !assert.Len(t, sessions, 3) {
+		return
+	}
+
+	current := 0
+	for _, s := range sessions {
+		assert.Equal(t, "192.0.2.1", s.IPAddress)
+		if s.Current {
+			current++
+			assert.Equal(t, "Laptop Browser/1.0", s.UserAgent)
+		}
+	}
+	assert.Equal(t, 1, current)
+
+	// Refreshing keeps the session
+	resp := postJSON("/auth/refresh", map[string]string{"refresh_token": laptop["refresh_token"]}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var refreshed map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &refreshed)
+	assert.Len(t, listSessions(t, refreshed["token"]), 3)
+}
+
+func TestRevokeSession(t *testing.T) {
+	phone := registerAndLogin(t, "sessions-revoke")
+	laptop := loginFrom(t, phone["email"], "Laptop Browser/1.0")
+	other := registerAndLogin(t, "sessions-other")
+
+	var phoneSession string
+	for _, s := range listSessions(t, phone["token"]) {
+		if s.Current {
+			phoneSession = s.ID
+		}
+	}
+	if !assert.NotEmpty(t, phoneSession) {
+		return
+	}
+
+	// Sessions of other users are not visible
+	resp := sendJSON(http.MethodDelete, "/me/sessions/"+phoneSession, nil, other["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = sendJSON(http.MethodDelete, "/me/sessions/not-a-uuid", nil, laptop["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = sendJSON(http.MethodDelete, "/me/sessions/"+phoneSession, nil, laptop["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	// Both the access and the refresh token of the revoked session stop working
+	resp = getJSON("/me", phone["token"])
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = postJSON("/auth/refresh", map[string]string{"refresh_token": phone["refresh_token"]}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = getJSON("/me", laptop["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	for _, s := range listSessions(t, laptop["token"]) {
+		assert.NotEqual(t, phoneSession, s.ID)
+	}
+
+	resp = sendJSON(http.MethodDelete, "/me/sessions/"+phoneSession, nil, laptop["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+// subtaskInput returns the payload creating a subtask of parentID.
+func subtaskInput(parentID string) map[string]string {
+	return map[string]string{
+		"title":       "Subtask",
+		"description": "Test Description",
+		"due_date":    time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+		"parent_id":   parentID,
+	}
+}
+
+// createSubtask creates a subtask of parentID and returns its ID.
+func createSubtask(t *testing.T, token string, parentID string) string {
+	resp := postJSON("/tasks", subtaskInput(parentID), token)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Failed to create subtask: %s", resp.Body.String())
+	}
+
+	var task dto.TaskResponse
+	json.Unmarshal(resp.Body.Bytes(), &task)
+	return task.ID
+}
+
+// getTask returns a task as seen by the given token.
+func getTask(t *testing.T, taskID string, token string) dto.TaskResponse {
+	resp := getJSON("/tasks/"+taskID, token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Failed to get task: %s", resp.Body.String())
+	}
+
+	var task dto.TaskResponse
+	json.Unmarshal(resp.Body.Bytes(), &task)
+	return task
+}
+
+func TestSubtasks(t *testing.T) {
+	session := registerAndLogin(t, "subtasks")
+	parent := createTestTask(t, session["token"], "medium", "pending")
+	first := createSubtask(t, session["token"], parent)
+	second := createSubtask(t, session["token"], parent)
+
+	sub := getTask(t, first, session["token"])
+	if assert.NotNil(t, sub.ParentID) {
+		assert.Equal(t, parent, *sub.ParentID)
+	}
+
+	resp := getJSON("/tasks/"+parent+"/subtasks", session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var subtasks []dto.TaskResponse
+	json.Unmarshal(resp.Body.Bytes(), &subtasks)
+	assert.Len(t, subtasks, 2)
+
+	// The parent cannot be completed while subtasks are open, unless forced
+	resp = sendJSON(http.MethodPut, "/tasks/"+first, map[string]string{"status": "complete"}, session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, dto.TaskProgress{Done: 1, Total: 2}, getTask(t, parent, session["token"]).Subtasks)
+
+	resp = sendJSON(http.MethodPut, "/tasks/"+parent, map[string]string{"status": "complete"}, session["token"])
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	resp = sendJSON(http.MethodPut, "/tasks/"+parent, map[string]interface{}{"status": "complete", "force": true}, session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// Moving a subtask to the top level
+	resp = sendJSON(http.MethodPut, "/tasks/"+second, map[string]string{"parent_id": ""}, session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Nil(t, getTask(t, second, session["token"]).ParentID)
+	assert.Equal(t, dto.TaskProgress{Done: 1, Total: 1}, getTask(t, parent, session["token"]).Subtasks)
+
+	// Deleting the parent keeps its subtasks
+	resp = sendJSON(http.MethodDelete, "/tasks/"+parent, nil, session["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Nil(t, getTask(t, first, session["token"]).ParentID)
+}
+
+func TestSubtaskHierarchyRules(t *testing.T) {
+	session := registerAndLogin(t, "subtasks-rules")
+	other := registerAndLogin(t, "subtasks-rules-other")
+
+	// The default maximum depth is 3 levels
+	top := createTestTask(t, session["token"], "medium", "pending")
+	middle := createSubtask(t, session["token"], top)
+	bottom := createSubtask(t, session["token"], middle)
+
+	resp := postJSON("/tasks", subtaskInput(bottom), session["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// Moving a task under one of its own subtasks would create a cycle
+	resp = sendJSON(http.MethodPut, "/tasks/"+top, map[string]string{"parent_id": bottom}, session["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = sendJSON(http.MethodPut, "/tasks/"+top, map[string]string{"parent_id": top}, session["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// Moving a task with subtasks counts their levels too
+	standalone := createTestTask(t, session["token"], "medium", "pending")
+	resp = sendJSON(http.MethodPut, "/tasks/"+middle, map[string]string{"parent_id": standalone}, session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = sendJSON(http.MethodPut, "/tasks/"+standalone, map[string]string{"parent_id": top}, session["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// The parent must be visible to the user
+	resp = postJSON("/tasks", subtaskInput(top), other["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = postJSON("/tasks", subtaskInput("not-a-uuid"), session["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestChecklist(t *testing.T) {
+	session := registerAndLogin(t, "checklist")
+	other := registerAndLogin(t, "checklist-other")
+	taskID := createTestTask(t, session["token"], "medium", "pending")
+
+	add := func(payload map[string]interface{}) dto.ChecklistItemResponse {
+		resp := postJSON("/tasks/"+taskID+"/checklist", payload, session["token"])
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("Failed to add checklist item: %s", resp.Body.String())
+		}
+		var item dto.ChecklistItemResponse
+		json.Unmarshal(resp.Body.Bytes(), &item)
+		return item
+	}
+	texts := func() []string {
+		resp := getJSON("/tasks/"+taskID+"/checklist", session["token"])
+		var items []dto.ChecklistItemResponse
+		json.Unmarshal(resp.Body.Bytes(), &items)
+		result := make([]string, 0, len(items))
+		for _, item := range items {
+			result = append(result, item.Text)
+		}
+		return result
+	}
+
+	write := add(map[string]interface{}{"text": "write"})
+	add(map[string]interface{}{"text": "review"})
+	plan := add(map[string]interface{}{"text": "plan", "position": 0})
+	ship := add(map[string]interface{}{"text": "ship", "position": 99})
+	assert.Equal(t, 0, plan.Position)
+	assert.Equal(t, 3, ship.Position)
+	assert.Equal(t, []string{"plan", "write", "review", "ship"}, texts())
+
+	// Moving an item shifts the others
+	resp := sendJSON(http.MethodPut, "/tasks/"+taskID+"/checklist/"+ship.ID, map[string]interface{}{"position": 1}, session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []string{"plan", "ship", "write", "review"}, texts())
+
+	resp = sendJSON(http.MethodPut, "/tasks/"+taskID+"/checklist/"+plan.ID, map[string]interface{}{"done": true, "position": 3}, session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []string{"ship", "write", "review", "plan"}, texts())
+
+	resp = sendJSON(http.MethodDelete, "/tasks/"+taskID+"/checklist/"+write.ID, nil, session["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, []string{"ship", "review", "plan"}, texts())
+
+	assert.Equal(t, dto.TaskProgress{Done: 1, Total: 3}, getTask(t, taskID, session["token"]).Checklist)
+
+	// Only users who can see the task can read or edit its checklist
+	resp = getJSON("/tasks/"+taskID+"/checklist", other["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	resp = sendJSON(http.MethodDelete, "/tasks/"+taskID+"/checklist/"+ship.ID, nil, other["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	for _, payload := range []map[string]interface{}{{"text": " "}, {"text": "ok", "position": -1}} {
+		resp = postJSON("/tasks/"+taskID+"/checklist", payload, session["token"])
+		assert.Equal(t, http.StatusBadRequest, resp.Code, payload)
+	}
+	resp = sendJSON(http.MethodPut, "/tasks/"+taskID+"/checklist/"+ship.ID, map[string]interface{}{}, session["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+// createTaskDue creates a task with the given priority, status and due date and returns its ID.
+func createTaskDue(t *testing.T, token string, priority string, status string, dueDate time.Time) string {
+	resp := postJSON("/tasks", map[string]string{
+		"title":       "Filtered Task",
+		"description": "Test Description",
+		"due_date":    dueDate.Format(time.RFC3339),
+		"priority":    priority,
+		"status":      status,
+	}, token)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Failed to create task: %s", resp.Body.String())
+	}
+
+	var task dto.TaskResponse
+	json.Unmarshal(resp.Body.Bytes(), &task)
+	return task.ID
+}
+
+// listTaskIDs returns the IDs of the tasks on the first page of the listing at path.
+func listTaskIDs(t *testing.T, path string, token string) []string {
+	resp := getJSON(path, token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Failed to list tasks: %s", resp.Body.String())
+	}
+
+	var page dto.TaskListResponse
+	json.Unmarshal(resp.Body.Bytes(), &page)
+	ids := make([]string, 0, len(page.Tasks))
+	for _, task := range page.Tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func TestGetTasksFilters(t *testing.T) {
+	session := registerAndLogin(t, "tasks-filters")
+	teammate := registerAndLogin(t, "tasks-filters-teammate")
+	now := time.Now().UTC()
+
+	overdueHigh := createTaskDue(t, session["token"], "high", "pending", now.Add(-48*time.Hour))
+	doneHigh := createTaskDue(t, session["token"], "high", "complete", now.Add(-24*time.Hour))
+	upcomingHigh := createTaskDue(t, session["token"], "high", "in_progress", now.Add(24*time.Hour))
+	upcomingLow := createTaskDue(t, session["token"], "low", "pending", now.Add(72*time.Hour))
+	received := createAssignedTask(t, teammate["token"], userIDFromToken(t, session["token"]))
+
+	// "My overdue high-priority tasks"
+	ids := listTaskIDs(t, "/tasks?overdue=true&priority=high", session["token"])
+	assert.Equal(t, []string{overdueHigh}, ids)
+
+	ids = listTaskIDs(t, "/tasks?status=pending,in_progress&priority=high,low", session["token"])
+	assert.ElementsMatch(t, []string{overdueHigh, upcomingHigh, upcomingLow}, ids)
+
+	ids = listTaskIDs(t, "/tasks?due_after="+url.QueryEscape(now.Add(-36*time.Hour).Format(time.RFC3339))+
+		"&due_before="+url.QueryEscape(now.Add(48*time.Hour).Format(time.RFC3339)), session["token"])
+	assert.ElementsMatch(t, []string{doneHigh, upcomingHigh, received}, ids)
+
+	// The received task was created by the teammate
+	ids = listTaskIDs(t, "/tasks?role=creator", session["token"])
+	assert.NotContains(t, ids, received)
+	assert.Len(t, ids, 4)
+
+	ids = listTaskIDs(t, "/tasks?role=assignee&created_after="+url.QueryEscape(now.Add(-time.Hour).Format(time.RFC3339)), session["token"])
+	assert.Contains(t, ids, received)
+	assert.Len(t, ids, 5)
+
+	for _, path := range []string{
+		"/tasks?status=done",
+		"/tasks?priority=urgent",
+		"/tasks?role=owner",
+		"/tasks?assignee_id=nobody",
+		"/tasks?overdue=maybe",
+		"/tasks?due_before=tomorrow",
+	} {
+		resp := getJSON(path, session["token"])
+		assert.Equal(t, http.StatusBadRequest, resp.Code, path)
+	}
+}
+
+func TestGetTasksSort(t *testing.T) {
+	session := registerAndLogin(t, "tasks-sort")
+	due := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	// Priority sorts by rank, so "medium" comes between "high" and "low"
+	low := createTaskDue(t, session["token"], "low", "pending", due)
+	highLater := createTaskDue(t, session["token"], "high", "pending", due.Add(time.Hour))
+	medium := createTaskDue(t, session["token"], "medium", "pending", due)
+	highSooner := createTaskDue(t, session["token"], "high", "pending", due)
+
+	var ids []string
+	path := "/tasks?sort=-priority,due_date&limit=1"
+	for {
+		resp := getJSON(path, session["token"])
+		if !assert.Equal(t, http.StatusOK, resp.Code) {
+			return
+		}
+
+		var page dto.TaskListResponse
+		json.Unmarshal(resp.Body.Bytes(), &page)
+		for _, task := range page.Tasks {
+			ids = append(ids, task.ID)
+		}
+		if page.NextCursor == nil {
+			break
+		}
+
+		// A cursor only continues the sort order it was issued for
+		resp = getJSON("/tasks?sort=due_date&limit=1&cursor="+url.QueryEscape(*page.NextCursor), session["token"])
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		path = "/tasks?sort=-priority,due_date&limit=1&cursor=" + url.QueryEscape(*page.NextCursor)
+	}
+	assert.Equal(t, []string{highSooner, highLater, medium, low}, ids)
+
+	resp := getJSON("/tasks?sort=password", session["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// getTaskHistory returns one page of a task's history as seen by the given token.
+func getTaskHistory(t *testing.T, path string, token string) dto.TaskHistoryResponse {
+	resp := getJSON(path, token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Failed to get task history: %s", resp.Body.String())
+	}
+
+	var page dto.TaskHistoryResponse
+	json.Unmarshal(resp.Body.Bytes(), &page)
+	return page
+}
+
+func TestTaskHistory(t *testing.T) {
+	creator := registerAndLogin(t, "history")
+	assignee := registerAndLogin(t, "history-assignee")
+	creatorID := userIDFromToken(t, creator["token"])
+	assigneeID := userIDFromToken(t, assignee["token"])
+	taskID := createTestTask(t, creator["token"], "medium", "pending")
+
+	resp := sendJSON(http.MethodPut, "/tasks/"+taskID, map[string]string{"status": "complete"}, creator["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = sendJSON(http.MethodPut, "/tasks/"+taskID, map[string]string{"status": "pending", "assignee_id": assigneeID}, creator["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// Updates that change nothing are not recorded
+	resp = sendJSON(http.MethodPut, "/tasks/"+taskID, map[string]string{"priority": "medium"}, creator["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	page := getTaskHistory(t, "/tasks/"+taskID+"/history", assignee["token"])
+	if !assert.Len(t, page.Events, 3) {
+		return
+	}
+
+	// Newest first: who moved it back to pending, and when
+	reopened := page.Events[0]
+	assert.Equal(t, "updated", reopened.Action)
+	if assert.NotNil(t, reopened.Actor) {
+		assert.Equal(t, creatorID, reopened.Actor.ID)
+	}
+	assert.Equal(t, dto.FieldChange{Before: "complete", After: "pending"}, reopened.Changes["status"])
+	assert.Equal(t, dto.FieldChange{Before: creatorID, After: assigneeID}, reopened.Changes["assignee_id"])
+	assert.Len(t, reopened.Changes, 2)
+	assert.WithinDuration(t, time.Now(), reopened.CreatedAt, time.Minute)
+
+	assert.Equal(t, dto.FieldChange{Before: "pending", After: "complete"}, page.Events[1].Changes["status"])
+
+	created := page.Events[2]
+	assert.Equal(t, "created", created.Action)
+	assert.Equal(t, dto.FieldChange{Before: nil, After: "medium"}, created.Changes["priority"])
+	assert.Contains(t, created.Changes, "title")
+
+	// Pages are keyset-paginated
+	first := getTaskHistory(t, "/tasks/"+taskID+"/history?limit=2", creator["token"])
+	assert.Len(t, first.Events, 2)
+	if assert.NotNil(t, first.NextCursor) {
+		second := getTaskHistory(t, "/tasks/"+taskID+"/history?limit=2&cursor="+*first.NextCursor, creator["token"])
+		if assert.Len(t, second.Events, 1) {
+			assert.Equal(t, created.ID, second.Events[0].ID)
+		}
+		assert.Nil(t, second.NextCursor)
+	}
+
+	outsider := registerAndLogin(t, "history-outsider")
+	resp = getJSON("/tasks/"+taskID+"/history", outsider["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestTaskHistoryOutlivesTask(t *testing.T) {
+	creator := registerAndLogin(t, "history-deleted")
+	admin := registerAdmin(t, "history-auditor")
+	taskID := createTestTask(t, creator["token"], "high", "in_progress")
+
+	resp := sendJSON(http.MethodDelete, "/tasks/"+taskID, nil, creator["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	// Only admins can read the history of deleted tasks
+	resp = getJSON("/tasks/"+taskID+"/history", creator["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	page := getTaskHistory(t, "/tasks/"+taskID+"/history", admin["token"])
+	if assert.Len(t, page.Events, 2) {
+		assert.Equal(t, "deleted", page.Events[0].Action)
+		assert.Equal(t, dto.FieldChange{Before: "in_progress", After: nil}, page.Events[0].Changes["status"])
+	}
+
+	// Deleting a parent task detaches its subtasks, which their history records
+	parentID := createTestTask(t, creator["token"], "medium", "pending")
+	subtaskID := createSubtask(t, creator["token"], parentID)
+	resp = sendJSON(http.MethodDelete, "/tasks/"+parentID, nil, creator["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	subtaskHistory := getTaskHistory(t, "/tasks/"+subtaskID+"/history", creator["token"])
+	if assert.Len(t, subtaskHistory.Events, 2) {
+		assert.Equal(t, "updated", subtaskHistory.Events[0].Action)
+		assert.Equal(t, dto.FieldChange{Before: parentID, After: nil}, subtaskHistory.Events[0].Changes["parent_id"])
+		assert.Len(t, subtaskHistory.Events[0].Changes, 1)
+	}
+
+	// Events cannot be changed or removed, even directly in the database
+	err := database.DB.Exec("UPDATE task_events SET action = 'created' WHERE task_id = ?", taskID).Error
+	assert.Error(t, err)
+	err = database.DB.Exec("DELETE FROM task_events WHERE task_id = ?", taskID).Error
+	assert.Error(t, err)
+	assert.Len(t, getTaskHistory(t, "/tasks/"+taskID+"/history", admin["token"]).Events, 2)
+}
+
+func TestTaskHistoryRecordsAccountHandover(t *testing.T) {
+	leaving := registerAndLogin(t, "history-leaving")
+	teammate := registerAndLogin(t, "history-teammate")
+	leavingID := userIDFromToken(t, leaving["token"])
+	teammateID := userIDFromToken(t, teammate["token"])
+	taskID := createAssignedTask(t, teammate["token"], leavingID)
+
+	// A subtask handed to the teammate under a task the leaving user keeps to themselves
+	parentID := createTestTask(t, leaving["token"], "low", "pending")
+	input := subtaskInput(parentID)
+	input["assignee_id"] = teammateID
+	resp := postJSON("/tasks", input, leaving["token"])
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Failed to create subtask: %s", resp.Body.String())
+	}
+	var subtask dto.TaskResponse
+	json.Unmarshal(resp.Body.Bytes(), &subtask)
+
+	resp = sendJSON(http.MethodDelete, "/me", map[string]string{"password": testPass}, leaving["token"])
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	_, err := services.PurgeDeletedAccounts(time.Now().Add(365 * 24 * time.Hour))
+	assert.NoError(t, err)
+
+	// The task went back to its creator, with no actor
+	page := getTaskHistory(t, "/tasks/"+taskID+"/history", teammate["token"])
+	if assert.Len(t, page.Events, 2) {
+		assert.Nil(t, page.Events[0].Actor)
+		assert.Equal(t, dto.FieldChange{Before: leavingID, After: teammateID}, page.Events[0].Changes["assignee_id"])
+	}
+
+	// The subtask was handed over and lost its deleted parent
+	page = getTaskHistory(t, "/tasks/"+subtask.ID+"/history", teammate["token"])
+	if assert.Len(t, page.Events, 3) {
+		changes := map[string]dto.FieldChange{}
+		for _, event := range page.Events[:2] {
+			assert.Nil(t, event.Actor)
+			for field, change := range event.Changes {
+				changes[field] = change
+			}
+		}
+		assert.Equal(t, dto.FieldChange{Before: parentID, After: nil}, changes["parent_id"])
+		assert.Equal(t, dto.FieldChange{Before: leavingID, After: teammateID}, changes["creator_id"])
+	}
+}
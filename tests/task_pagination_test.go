@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTasksPagination(t *testing.T) {
+	session := registerAndLogin(t, "tasks-pages")
+
+	// Tasks sharing a due date are ordered by ID, so none is skipped between pages
+	created := map[string]bool{}
+	for i := 0; i < 5; i++ {
+		resp := postJSON("/tasks", map[string]string{
+			"title":       "Paged Task",
+			"description": "Test Description",
+			"due_date":    "2030-01-01T09:00:00Z",
+		}, session["token"])
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("Failed to create task: %s", resp.Body.String())
+		}
+		var task dto.TaskResponse
+		json.Unmarshal(resp.Body.Bytes(), &task)
+		created[task.ID] = true
+	}
+
+	seen := map[string]bool{}
+	path := "/tasks?limit=2"
+	pages := 0
+	for {
+		resp := getJSON(path, session["token"])
+		if !assert.Equal(t, http.StatusOK, resp.Code) {
+			return
+		}
+		pages++
+
+		var page dto.TaskListResponse
+		json.Unmarshal(resp.Body.Bytes(), &page)
+		assert.Equal(t, 2, page.Limit)
+		for _, task := range page.Tasks {
+			assert.False(t, seen[task.ID], "task %s returned twice", task.ID)
+			seen[task.ID] = true
+		}
+
+		if page.NextCursor == nil {
+			assert.NotContains(t, resp.Header().Get("Link"), `rel="next"`)
+			break
+		}
+		assert.Len(t, page.Tasks, 2)
+		assert.Contains(t, resp.Header().Get("Link"), `rel="next"`)
+		path = "/tasks?limit=2&cursor=" + url.QueryEscape(*page.NextCursor)
+	}
+
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, created, seen)
+}
+
+func TestGetTasksRejectsInvalidPagination(t *testing.T) {
+	session := registerAndLogin(t, "tasks-bad-cursor")
+
+	resp := getJSON("/tasks?cursor=not-a-cursor", session["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = getJSON("/tasks?limit=0", session["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
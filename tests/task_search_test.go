@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+// createTaskText creates a task with the given title and description and returns its ID.
+func createTaskText(t *testing.T, token string, title string, description string) string {
+	resp := postJSON("/tasks", map[string]string{
+		"title":       title,
+		"description": description,
+		"due_date":    time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+		"priority":    "medium",
+		"status":      "pending",
+	}, token)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Failed to create task: %s", resp.Body.String())
+	}
+
+	var task dto.TaskResponse
+	json.Unmarshal(resp.Body.Bytes(), &task)
+	return task.ID
+}
+
+// searchTasks returns the first page of results of a search.
+func searchTasks(t *testing.T, q string, token string) dto.TaskSearchResponse {
+	resp := getJSON("/tasks/search?q="+url.QueryEscape(q), token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Failed to search tasks: %s", resp.Body.String())
+	}
+
+	var page dto.TaskSearchResponse
+	json.Unmarshal(resp.Body.Bytes(), &page)
+	return page
+}
+
+// resultIDs returns the IDs of the tasks in a page of search results, in order.
+func resultIDs(page dto.TaskSearchResponse) []string {
+	ids := make([]string, 0, len(page.Tasks))
+	for _, task := range page.Tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func TestSearchTasks(t *testing.T) {
+	session := registerAndLogin(t, "tasks-search")
+	other := registerAndLogin(t, "tasks-search-other")
+
+	inTitle := createTaskText(t, session["token"], "Write release notes", "Summarize the changes for customers")
+	inDescription := createTaskText(t, session["token"], "Prepare deployment", "Publish the release notes once the deploy succeeds")
+	scattered := createTaskText(t, session["token"], "Notes from the meeting", "Discuss the next release date")
+	createTaskText(t, other["token"], "Write release notes", "Tasks of other users are never found")
+
+	// Title matches rank above description matches; stemming matches "notes" with "note"
+	page := searchTasks(t, "release note", session["token"])
+	ids := resultIDs(page)
+	if !assert.Len(t, ids, 3) {
+		return
+	}
+	assert.Equal(t, inTitle, ids[0])
+	assert.ElementsMatch(t, []string{inTitle, inDescription, scattered}, ids)
+	assert.Greater(t, page.Tasks[0].Rank, page.Tasks[2].Rank)
+	assert.Contains(t, page.Tasks[0].Highlights.Title, "<mark>release</mark>")
+	assert.Nil(t, page.NextCursor)
+
+	// Phrases only match words next to each other
+	ids = resultIDs(searchTasks(t, `"release notes"`, session["token"]))
+	assert.ElementsMatch(t, []string{inTitle, inDescription}, ids)
+
+	// Prefix matches
+	ids = resultIDs(searchTasks(t, "deploy*", session["token"]))
+	assert.Equal(t, []string{inDescription}, ids)
+
+	ids = resultIDs(searchTasks(t, "meet*", session["token"]))
+	assert.Equal(t, []string{scattered}, ids)
+
+	// q also filters the regular listing, where it can be combined with other sort orders
+	ids = listTaskIDs(t, "/tasks?q="+url.QueryEscape(`"release notes"`)+"&sort=-rank", session["token"])
+	assert.Equal(t, inTitle, ids[0])
+	assert.Len(t, ids, 2)
+}
+
+func TestSearchTasksHighlightsAreEscaped(t *testing.T) {
+	session := registerAndLogin(t, "tasks-search-escape")
+	createTaskText(t, session["token"], "Fix <script> injection", "Escape the <b>markup</b> of the injection report")
+
+	page := searchTasks(t, "injection", session["token"])
+	if !assert.Len(t, page.Tasks, 1) {
+		return
+	}
+	assert.Equal(t, "Fix &lt;script&gt; <mark>injection</mark>", page.Tasks[0].Highlights.Title)
+	assert.Contains(t, page.Tasks[0].Highlights.Description, "&lt;b&gt;")
+	assert.Contains(t, page.Tasks[0].Highlights.Description, "<mark>injection</mark>")
+	assert.NotContains(t, page.Tasks[0].Highlights.Description, "<b>")
+}
+
+func TestSearchTasksPagination(t *testing.T) {
+	session := registerAndLogin(t, "tasks-search-pages")
+	for i := 0; i < 3; i++ {
+		createTaskText(t, session["token"], "Review invoice", "Check the invoice totals")
+	}
+
+	var ids []string
+	path := "/tasks/search?q=invoice&limit=2"
+	for {
+		resp := getJSON(path, session["token"])
+		if !assert.Equal(t, http.StatusOK, resp.Code) {
+			return
+		}
+
+		var page dto.TaskSearchResponse
+		json.Unmarshal(resp.Body.Bytes(), &page)
+		ids = append(ids, resultIDs(page)...)
+		if page.NextCursor == nil {
+			break
+		}
+		path = "/tasks/search?q=invoice&limit=2&cursor=" + url.QueryEscape(*page.NextCursor)
+	}
+	assert.Len(t, ids, 3)
+}
+
+func TestSearchTasksInvalidQuery(t *testing.T) {
+	session := registerAndLogin(t, "tasks-search-invalid")
+
+	for _, path := range []string{
+		"/tasks/search",
+		"/tasks/search?q=%20%20",
+		"/tasks/search?q=***",
+		"/tasks/search?q=" + strings.Repeat("a", 201),
+		"/tasks/search?q=notes&sort=password",
+		"/tasks?sort=-rank",
+	} {
+		resp := getJSON(path, session["token"])
+		assert.Equal(t, http.StatusBadRequest, resp.Code, path)
+	}
+}
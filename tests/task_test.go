@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/kfeuerschvenger/task-manager-api/dto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -73,12 +74,12 @@ func TestGetTasksWithFilters(t *testing.T) {
     
     assert.Equal(t, http.StatusOK, resp.Code)
     
-    var response []map[string]interface{}
+    var response dto.TaskListResponse
     json.Unmarshal(resp.Body.Bytes(), &response)
     
-    assert.Len(t, response, 1)
-    assert.Equal(t, "high", response[0]["priority"])
-    assert.Equal(t, "pending", response[0]["status"])
+    assert.Len(t, response.Tasks, 1)
+    assert.Equal(t, "high", response.Tasks[0].Priority)
+    assert.Equal(t, "pending", response.Tasks[0].Status)
 }
 
 func TestUpdateTaskAsCreator(t *testing.T) {
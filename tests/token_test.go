@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// createPersonalAccessToken creates a personal access token with the given scopes and returns it.
+func createPersonalAccessToken(t *testing.T, token string, scopes ...string) string {
+	resp := postJSON("/me/tokens", map[string]interface{}{"name": "script", "scopes": scopes}, token)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Failed to create personal access token: %s", resp.Body.String())
+	}
+
+	var created map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &created)
+	return created["token"].(string)
+}
+
+func TestPersonalAccessTokenScopes(t *testing.T) {
+	session := registerAndLogin(t, "pat")
+
+	resp := postJSON("/me/tokens", map[string]interface{}{
+		"name":   "read-only script",
+		"scopes": []string{"tasks:read"},
+	}, session["token"])
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var created map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &created)
+	pat := created["token"].(string)
+	assert.Contains(t, pat, "tmpat_")
+
+	// Read access is granted
+	assert.Equal(t, http.StatusOK, getJSON("/tasks", pat).Code)
+
+	// Write access is not
+	resp = postJSON("/tasks", map[string]interface{}{
+		"title":       "From a script",
+		"description": "Should be rejected",
+		"due_date":    time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+	}, pat)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	// Tokens cannot manage tokens
+	assert.Equal(t, http.StatusForbidden, getJSON("/me/tokens", pat).Code)
+
+	// Listing never exposes the secret
+	resp = getJSON("/me/tokens", session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var tokens []map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &tokens)
+	assert.Len(t, tokens, 1)
+	assert.Nil(t, tokens[0]["token"])
+	assert.NotNil(t, tokens[0]["last_used_at"])
+
+	// Revoked tokens stop working
+	resp = sendJSON(http.MethodDelete, "/me/tokens/"+created["id"].(string), nil, session["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, http.StatusUnauthorized, getJSON("/tasks", pat).Code)
+}
+
+func TestPersonalAccessTokenValidation(t *testing.T) {
+	session := registerAndLogin(t, "patvalidation")
+
+	resp := postJSON("/me/tokens", map[string]interface{}{
+		"name":   "bad scope",
+		"scopes": []string{"admin:everything"},
+	}, session["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = postJSON("/me/tokens", map[string]interface{}{
+		"name":       "expired",
+		"scopes":     []string{"tasks:read"},
+		"expires_at": time.Now().Add(-time.Hour).Format(time.RFC3339),
+	}, session["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
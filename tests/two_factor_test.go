@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoFactorEnrollmentAndLogin(t *testing.T) {
+	session := registerAndLogin(t, "twofactor")
+	credentials := map[string]string{"email": session["email"], "password": testPass}
+
+	// Start setup
+	resp := postJSON("/auth/2fa/setup", nil, session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var setup map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &setup)
+	assert.NotEmpty(t, setup["secret"])
+	assert.Contains(t, setup["otpauth_uri"], "otpauth://totp/")
+
+	// Confirm with a valid code
+	step := utils.TOTPStep(time.Now())
+	code, _ := utils.TOTPCode(setup["secret"], step)
+	resp = postJSON("/auth/2fa/confirm", map[string]string{"code": code}, session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var recovery map[string][]string
+	json.Unmarshal(resp.Body.Bytes(), &recovery)
+	assert.Len(t, recovery["recovery_codes"], 10)
+
+	// Password login now returns a challenge
+	resp = postJSON("/auth/login", credentials, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var challenge map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &challenge)
+	assert.Equal(t, true, challenge["mfa_required"])
+	assert.Nil(t, challenge["token"])
+
+	// The challenge token is not an access token
+	challengeToken := challenge["challenge_token"].(string)
+	assert.Equal(t, http.StatusUnauthorized, getJSON("/tasks", challengeToken).Code)
+
+	// The code used for confirmation cannot be replayed
+	resp = postJSON("/auth/2fa/verify", map[string]string{"challenge_token": challengeToken, "code": code}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	// A code from the next time step is accepted
+	next, _ := utils.TOTPCode(setup["secret"], step+1)
+	resp = postJSON("/auth/2fa/verify", map[string]string{"challenge_token": challengeToken, "code": next}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var tokens map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &tokens)
+	assert.NotEmpty(t, tokens["token"])
+
+	// Challenges are single-use
+	resp = postJSON("/auth/2fa/verify", map[string]string{"challenge_token": challengeToken, "code": recovery["recovery_codes"][0]}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func TestTwoFactorRecoveryCodesAndDisable(t *testing.T) {
+	session := registerAndLogin(t, "recovery")
+	credentials := map[string]string{"email": session["email"], "password": testPass}
+
+	resp := postJSON("/auth/2fa/setup", nil, session["token"])
+	var setup map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &setup)
+
+	code, _ := utils.TOTPCode(setup["secret"], utils.TOTPStep(time.Now()))
+	resp = postJSON("/auth/2fa/confirm", map[string]string{"code": code}, session["token"])
+	var recovery map[string][]string
+	json.Unmarshal(resp.Body.Bytes(), &recovery)
+	recoveryCode := recovery["recovery_codes"][0]
+
+	// Log in with a recovery code
+	resp = postJSON("/auth/login", credentials, "")
+	var challenge map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &challenge)
+
+	resp = postJSON("/auth/2fa/verify", map[string]string{"challenge_token": challenge["challenge_token"].(string), "code": recoveryCode}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// Recovery codes are one-time
+	resp = postJSON("/auth/login", credentials, "")
+	json.Unmarshal(resp.Body.Bytes(), &challenge)
+	resp = postJSON("/auth/2fa/verify", map[string]string{"challenge_token": challenge["challenge_token"].(string), "code": recoveryCode}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	// Disabling requires the password
+	resp = sendJSON(http.MethodDelete, "/auth/2fa", map[string]string{"password": "wrongpass", "code": recovery["recovery_codes"][1]}, session["token"])
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = sendJSON(http.MethodDelete, "/auth/2fa", map[string]string{"password": testPass, "code": recovery["recovery_codes"][1]}, session["token"])
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	// Login issues tokens directly again
+	resp = postJSON("/auth/login", credentials, "")
+	var tokens map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &tokens)
+	assert.NotEmpty(t, tokens["token"])
+}
+
+func TestTwoFactorChallengeAttemptLimit(t *testing.T) {
+	t.Setenv("MFA_CHALLENGE_MAX_ATTEMPTS", "2")
+
+	session := registerAndLogin(t, "twofactor-guess")
+	credentials := map[string]string{"email": session["email"], "password": testPass}
+
+	resp := postJSON("/auth/2fa/setup", nil, session["token"])
+	var setup map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &setup)
+	step := utils.TOTPStep(time.Now())
+	code, _ := utils.TOTPCode(setup["secret"], step)
+	resp = postJSON("/auth/2fa/confirm", map[string]string{"code": code}, session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// The secret is encrypted at rest
+	var stored string
+	database.DB.Model(&models.User{}).Select("totp_secret").Where("email = ?", session["email"]).Scan(&stored)
+	assert.NotContains(t, stored, setup["secret"])
+
+	challenge := func() string {
+		resp := postJSON("/auth/login", credentials, "")
+		var body map[string]interface{}
+		json.Unmarshal(resp.Body.Bytes(), &body)
+		return body["challenge_token"].(string)
+	}
+
+	// Once its attempts are used up, a challenge is rejected even with a valid code
+	challengeToken := challenge()
+	for i := 0; i < 2; i++ {
+		resp = postJSON("/auth/2fa/verify", map[string]string{"challenge_token": challengeToken, "code": "000000"}, "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	}
+	next, _ := utils.TOTPCode(setup["secret"], step+1)
+	resp = postJSON("/auth/2fa/verify", map[string]string{"challenge_token": challengeToken, "code": next}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	// A new challenge can still be completed
+	resp = postJSON("/auth/2fa/verify", map[string]string{"challenge_token": challenge(), "code": next}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestTwoFactorSetupRequiresEncryptionKey(t *testing.T) {
+	t.Setenv("TOTP_ENCRYPTION_KEY", "")
+	session := registerAndLogin(t, "twofactor-nokey")
+
+	// Secrets are never stored in plaintext
+	resp := postJSON("/auth/2fa/setup", nil, session["token"])
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	var stored *string
+	database.DB.Model(&models.User{}).Select("totp_secret").Where("email = ?", session["email"]).Scan(&stored)
+	assert.Nil(t, stored)
+}
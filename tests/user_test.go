@@ -0,0 +1,205 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAndUpdateProfile(t *testing.T) {
+	session := registerAndLogin(t, "profile")
+
+	resp := getJSON("/me", session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var profile map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &profile)
+	assert.Equal(t, session["email"], profile["email"])
+	assert.Equal(t, "Test", profile["first_name"])
+	assert.Equal(t, "UTC", profile["timezone"])
+	assert.Equal(t, "en", profile["locale"])
+
+	resp = sendJSON(http.MethodPatch, "/me", map[string]string{
+		"first_name": "Jane",
+		"timezone":   "America/Argentina/Buenos_Aires",
+		"locale":     "es_ar",
+	}, session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var updated map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &updated)
+	assert.Equal(t, "Jane", updated["first_name"])
+	assert.Equal(t, "User", updated["last_name"])
+	assert.Equal(t, "America/Argentina/Buenos_Aires", updated["timezone"])
+	assert.Equal(t, "es-AR", updated["locale"])
+}
+
+func TestUpdateProfileWithInvalidData(t *testing.T) {
+	session := registerAndLogin(t, "profile-invalid")
+
+	cases := []map[string]string{
+		{},
+		{"first_name": "J"},
+		{"timezone": "Mars/Olympus_Mons"},
+		{"locale": "not a locale"},
+	}
+	for _, payload := range cases {
+		resp := sendJSON(http.MethodPatch, "/me", payload, session["token"])
+		assert.Equal(t, http.StatusBadRequest, resp.Code, payload)
+	}
+}
+
+func TestChangePasswordRevokesOtherSessions(t *testing.T) {
+	session := registerAndLogin(t, "change-password")
+	pat := createPersonalAccessToken(t, session["token"], "tasks:read")
+
+	resp := postJSON("/me/password", map[string]string{
+		"current_password": "wrongpassword",
+		"new_password":     "brandnewpass",
+	}, session["token"])
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = postJSON("/me/password", map[string]string{
+		"current_password": testPass,
+		"new_password":     "brandnewpass",
+	}, session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var tokens map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &tokens)
+	assert.NotEmpty(t, tokens["token"])
+
+	// The old session and personal access tokens are gone, the new session works
+	resp = getJSON("/me", session["token"])
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, http.StatusUnauthorized, getJSON("/tasks", pat).Code)
+	resp = postJSON("/auth/refresh", map[string]string{"refresh_token": session["refresh_token"]}, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	resp = getJSON("/me", tokens["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = postJSON("/auth/login", map[string]string{"email": session["email"], "password": "brandnewpass"}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestChangeEmailRequiresConfirmation(t *testing.T) {
+	session := registerAndLogin(t, "change-email")
+	newEmail := uniqueEmail("changed-email")
+
+	resp := postJSON("/me/email", map[string]string{"email": newEmail, "password": testPass}, session["token"])
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	// Nothing changes until the new address is confirmed
+	var profile map[string]interface{}
+	resp = getJSON("/me", session["token"])
+	json.Unmarshal(resp.Body.Bytes(), &profile)
+	assert.Equal(t, session["email"], profile["email"])
+	assert.Equal(t, newEmail, profile["pending_email"])
+
+	_, notified := Outbox.LastTo(session["email"])
+	assert.True(t, notified)
+
+	resp = getJSON("/auth/email/confirm?token="+tokenFromLastMail(t, newEmail), "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var updated map[string]interface{}
+	resp = getJSON("/me", session["token"])
+	json.Unmarshal(resp.Body.Bytes(), &updated)
+	assert.Equal(t, newEmail, updated["email"])
+	assert.Equal(t, true, updated["email_verified"])
+	assert.Nil(t, updated["pending_email"])
+
+	resp = postJSON("/auth/login", map[string]string{"email": newEmail, "password": testPass}, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestChangeEmailToRegisteredAddress(t *testing.T) {
+	session := registerAndLogin(t, "change-email-taken")
+	other := registerAndLogin(t, "change-email-owner")
+
+	resp := postJSON("/me/email", map[string]string{"email": other["email"], "password": testPass}, session["token"])
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestUserDirectorySearch(t *testing.T) {
+	session := registerAndLogin(t, "directory")
+
+	// A unique last name keeps the search independent from other test runs
+	lastName := fmt.Sprintf("Dir%d", time.Now().UnixNano())
+	var ids []string
+	for _, first := range []string{"Alice", "Albert", "Bob"} {
+		resp := postJSON("/auth/register", map[string]string{
+			"first_name": first,
+			"last_name":  lastName,
+			"email":      uniqueEmail(strings.ToLower(first)),
+			"password":   testPass,
+		}, "")
+		assert.Equal(t, http.StatusCreated, resp.Code)
+
+		var tokens map[string]string
+		json.Unmarshal(resp.Body.Bytes(), &tokens)
+		ids = append(ids, userIDFromToken(t, tokens["token"]))
+	}
+
+	var page map[string]interface{}
+	resp := getJSON("/users?query="+url.QueryEscape(strings.ToLower(lastName)), session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	json.Unmarshal(resp.Body.Bytes(), &page)
+	assert.Equal(t, float64(3), page["total"])
+
+	// Full-name prefixes match too, and results are paginated
+	var filtered map[string]interface{}
+	resp = getJSON("/users?limit=1&query="+url.QueryEscape("Al "+lastName), session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	json.Unmarshal(resp.Body.Bytes(), &filtered)
+	assert.Equal(t, float64(0), filtered["total"])
+
+	resp = getJSON("/users?limit=1&query="+url.QueryEscape("Alice "+lastName), session["token"])
+	json.Unmarshal(resp.Body.Bytes(), &filtered)
+	assert.Equal(t, float64(1), filtered["total"])
+	assert.Len(t, filtered["users"], 1)
+
+	// The public profile exposes no private fields
+	resp = getJSON("/users/"+ids[0], session["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var profile map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &profile)
+	assert.Equal(t, "Alice", profile["first_name"])
+	assert.NotContains(t, profile, "password")
+	assert.NotContains(t, profile, "timezone")
+
+	resp = getJSON("/users?limit=500", session["token"])
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = getJSON("/users/not-a-uuid", session["token"])
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestHiddenAccountsHaveNoPublicProfile(t *testing.T) {
+	session := registerAndLogin(t, "profile-viewer")
+	admin := registerAdmin(t, "profile-admin")
+	leaving := registerAndLogin(t, "profile-leaving")
+	disabled := registerAndLogin(t, "profile-disabled")
+	leavingID := userIDFromToken(t, leaving["token"])
+	disabledID := userIDFromToken(t, disabled["token"])
+
+	assert.Equal(t, http.StatusOK, getJSON("/users/"+leavingID, session["token"]).Code)
+
+	resp := sendJSON(http.MethodDelete, "/me", map[string]string{"password": testPass}, leaving["token"])
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	resp = postJSON("/admin/users/"+disabledID+"/disable", nil, admin["token"])
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// Accounts hidden from the directory are not found by ID either
+	for _, id := range []string{leavingID, disabledID} {
+		resp = getJSON("/users/"+id, session["token"])
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		assert.NotContains(t, resp.Body.String(), "@example.com")
+	}
+}
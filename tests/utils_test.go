@@ -8,18 +8,29 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"regexp"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/kfeuerschvenger/task-manager-api/database"
+	"github.com/kfeuerschvenger/task-manager-api/lockout"
+	"github.com/kfeuerschvenger/task-manager-api/mailer"
+	"github.com/kfeuerschvenger/task-manager-api/models"
 	"github.com/kfeuerschvenger/task-manager-api/routes"
+	"github.com/kfeuerschvenger/task-manager-api/services"
+	"github.com/kfeuerschvenger/task-manager-api/storage"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
 )
 
 var (
 	// Router is the shared HTTP router for all tests
 	Router *mux.Router
 
+	// Outbox captures every email sent by the API during tests
+	Outbox = mailer.NewOutbox("")
+
 	// once ensures the test user setup runs only once
 	once sync.Once
 
@@ -45,11 +56,30 @@ func TestMain(m *testing.M) {
 		log.Fatalf("Failed to connect to test database: %v", err)
 	}
 
+	// Two-factor setup requires an encryption key for TOTP secrets
+	if os.Getenv("TOTP_ENCRYPTION_KEY") == "" {
+		os.Setenv("TOTP_ENCRYPTION_KEY", "test-totp-key")
+	}
+
+	// Capture outgoing emails instead of delivering them
+	mailer.SetDefault(Outbox)
+
+	// Keep attachments in a scratch directory
+	blobDir, err := os.MkdirTemp("", "task-manager-blobs-")
+	if err != nil {
+		log.Fatalf("Failed to create blob directory: %v", err)
+	}
+	storage.SetDefault(storage.NewLocalStore(blobDir))
+
+	// Track failed logins per test run so earlier runs cannot throttle this one
+	services.SetLoginAttemptStore(lockout.NewMemoryStore())
+
 	// Initialize the HTTP router
 	Router = routes.SetupRoutes()
 
 	// Run the tests
 	code := m.Run()
+	os.RemoveAll(blobDir)
 	os.Exit(code)
 }
 
@@ -110,3 +140,107 @@ func doAuthRequest(path string, payload interface{}, expectToken bool) (string,
 	}
 	return "", nil
 }
+
+// uniqueEmail returns an email address that has not been used by previous test runs.
+func uniqueEmail(prefix string) string {
+	return fmt.Sprintf("%s-%d@example.com", prefix, time.Now().UnixNano())
+}
+
+// postJSON sends a JSON POST request to path, optionally authenticated with token.
+func postJSON(path string, payload interface{}, token string) *httptest.ResponseRecorder {
+	return sendJSON(http.MethodPost, path, payload, token)
+}
+
+// sendJSON sends a JSON request with the given method to path, optionally authenticated with token.
+func sendJSON(method string, path string, payload interface{}, token string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp := httptest.NewRecorder()
+	Router.ServeHTTP(resp, req)
+	return resp
+}
+
+// registerAndLogin creates a new user with a unique email and returns the login response body.
+func registerAndLogin(t *testing.T, prefix string) map[string]string {
+	email := uniqueEmail(prefix)
+	resp := postJSON("/auth/register", map[string]string{
+		"first_name": "Test",
+		"last_name":  "User",
+		"email":      email,
+		"password":   testPass,
+	}, "")
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Failed to register test user: %s", resp.Body.String())
+	}
+
+	resp = postJSON("/auth/login", map[string]string{"email": email, "password": testPass}, "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Failed to log in test user: %s", resp.Body.String())
+	}
+
+	var data map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &data)
+	data["email"] = email
+	return data
+}
+
+// getJSON sends a GET request to path, optionally authenticated with token.
+func getJSON(path string, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp := httptest.NewRecorder()
+	Router.ServeHTTP(resp, req)
+	return resp
+}
+
+// mailTokenPattern extracts the token query parameter from links in emails.
+var mailTokenPattern = regexp.MustCompile(`token=([A-Za-z0-9_.-]+)`)
+
+// tokenFromLastMail returns the token embedded in the last email sent to the given address.
+func tokenFromLastMail(t *testing.T, to string) string {
+	msg, ok := Outbox.LastTo(to)
+	if !ok {
+		t.Fatalf("No email sent to %s", to)
+	}
+	match := mailTokenPattern.FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("No token found in email to %s: %s", to, msg.Body)
+	}
+	return match[1]
+}
+
+// userIDFromToken extracts the user ID from an access token issued by the API.
+func userIDFromToken(t *testing.T, token string) string {
+	claims, err := utils.VerifyJWT(token)
+	if err != nil {
+		t.Fatalf("Invalid access token: %v", err)
+	}
+	return claims.UserID
+}
+
+// registerAdmin creates a new user, grants it the admin role and returns its login response body.
+func registerAdmin(t *testing.T, prefix string) map[string]string {
+	session := registerAndLogin(t, prefix)
+	if err := services.SetUserRoleByEmail(session["email"], models.RoleAdmin); err != nil {
+		t.Fatalf("Failed to grant admin role: %v", err)
+	}
+
+	// Log in again so the access token carries the new role
+	resp := postJSON("/auth/login", map[string]string{"email": session["email"], "password": testPass}, "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Failed to log in admin: %s", resp.Body.String())
+	}
+
+	var data map[string]string
+	json.Unmarshal(resp.Body.Bytes(), &data)
+	data["email"] = session["email"]
+	return data
+}
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailVerificationFlow(t *testing.T) {
+	session := registerAndLogin(t, "verify")
+	token := tokenFromLastMail(t, session["email"])
+
+	resp := getJSON("/auth/verify?token="+token, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// Verification tokens are single-use
+	resp = getJSON("/auth/verify?token="+token, "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = postJSON("/auth/verify/resend", nil, session["token"])
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestResendVerificationInvalidatesPreviousLink(t *testing.T) {
+	session := registerAndLogin(t, "resend")
+	first := tokenFromLastMail(t, session["email"])
+
+	resp := postJSON("/auth/verify/resend", nil, session["token"])
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	second := tokenFromLastMail(t, session["email"])
+	assert.NotEqual(t, first, second)
+
+	resp = getJSON("/auth/verify?token="+first, "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = getJSON("/auth/verify?token="+second, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestAssignmentPolicyBlocksUnverifiedAssignee(t *testing.T) {
+	t.Setenv("EMAIL_VERIFICATION_POLICY", "assign")
+
+	creator := registerAndLogin(t, "policy-creator")
+	assignee := registerAndLogin(t, "policy-assignee")
+
+	payload := map[string]interface{}{
+		"title":       "Assigned Task",
+		"description": "Needs a verified assignee",
+		"due_date":    time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+		"assignee_id": userIDFromToken(t, assignee["token"]),
+	}
+
+	resp := postJSON("/tasks", payload, creator["token"])
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp = getJSON("/auth/verify?token="+tokenFromLastMail(t, assignee["email"]), "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = postJSON("/tasks", payload, creator["token"])
+	assert.Equal(t, http.StatusCreated, resp.Code)
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DurationFromEnv reads a duration (e.g. "15m", "720h") from the given environment variable.
+// It returns def when the variable is unset or cannot be parsed.
+func DurationFromEnv(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+// AppURL returns the public base URL used to build links sent to users (APP_URL),
+// without a trailing slash.
+func AppURL() string {
+	url := os.Getenv("APP_URL")
+	if url == "" {
+		url = "http://localhost:8080"
+	}
+	return strings.TrimRight(url, "/")
+}
+
+// IntFromEnv reads a positive integer from the given environment variable.
+// It returns def when the variable is unset or cannot be parsed.
+func IntFromEnv(key string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return def
+	}
+	return value
+}
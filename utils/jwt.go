@@ -1,40 +1,100 @@
 package utils
 
 import (
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/kfeuerschvenger/task-manager-api/errors"
 )
 
-var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
+// Token purposes. Access tokens have no purpose claim; any other purpose restricts
+// the token to a single flow and it is never accepted by the auth middleware.
+const (
+	PurposeMFAChallenge = "mfa_challenge"
+)
+
+// Claims represents the custom claims embedded in access tokens.
+// The registered "jti" claim uniquely identifies each token so it can be revoked,
+// and "sid" ties it to the login session it was issued for.
+type Claims struct {
+	UserID    string `json:"user_id"`
+	Role      string `json:"role,omitempty"`
+	Purpose   string `json:"purpose,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func init() {
+	// Issue "iat" claims with millisecond precision, so revoking every token of a user
+	// also catches the ones issued earlier in the same second.
+	jwt.TimePrecision = time.Millisecond
+}
+
+// AccessTokenTTL returns the lifetime of access tokens, configurable through JWT_ACCESS_TTL.
+// Access tokens are short-lived; clients renew them with a refresh token.
+func AccessTokenTTL() time.Duration {
+	return DurationFromEnv("JWT_ACCESS_TTL", 15*time.Minute)
+}
+
+// GenerateJWT creates a new access token for the given user ID, role and session.
+// The token includes the user ID, the role, the session ID and a unique token ID in the claims and is valid for AccessTokenTTL.
+// It returns the signed token together with its expiration time.
+func GenerateJWT(userID string, role string, sessionID string) (string, time.Time, error) {
+	return generateToken(userID, role, "", sessionID, AccessTokenTTL())
+}
+
+// GenerateChallengeJWT creates a short-lived token proving that the user passed the password
+// step of a two-factor login. It can only be exchanged for an access token with a second factor.
+func GenerateChallengeJWT(userID string) (string, time.Time, error) {
+	return generateToken(userID, "", PurposeMFAChallenge, "", DurationFromEnv("MFA_CHALLENGE_TTL", 5*time.Minute))
+}
 
-// GenerateJWT creates a new JWT token for the given user ID.
-// The token is valid for 72 hours and includes the user ID in the claims.
-func GenerateJWT(userID string) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(),
+// generateToken signs a token for the given user, role, purpose and session.
+func generateToken(userID string, role string, purpose string, sessionID string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims := Claims{
+		UserID:    userID,
+		Role:      role,
+		Purpose:   purpose,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(jwtSecret))
+	signed, err := signClaims(claims)
+	return signed, expiresAt, err
+}
+
+// VerifyJWT checks the validity of an access token and returns its claims if valid.
+// It returns an error if the token is invalid, is not an access token, or if the claims are not as expected.
+func VerifyJWT(tokenString string) (*Claims, error) {
+	return verifyToken(tokenString, "")
+}
+
+// VerifyChallengeJWT checks the validity of a two-factor challenge token and returns its claims.
+func VerifyChallengeJWT(tokenString string) (*Claims, error) {
+	return verifyToken(tokenString, PurposeMFAChallenge)
 }
 
-// VerifyJWT checks the validity of a JWT token and returns the user ID if valid.
-// It returns an error if the token is invalid or if the claims are not as expected.
-func VerifyJWT(tokenString string) (string, error) {
-	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+// verifyToken parses and validates a token, requiring the given purpose.
+func verifyToken(tokenString string, purpose string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, verificationKey, jwt.WithValidMethods([]string{
+		jwt.SigningMethodRS256.Alg(),
+		jwt.SigningMethodEdDSA.Alg(),
+		jwt.SigningMethodHS256.Alg(),
+	}))
 	if err != nil || !token.Valid {
-		return "", errors.ErrInvalidField("token")
+		return nil, errors.ErrInvalidField("token")
 	}
 
-	claims := token.Claims.(jwt.MapClaims)
-	userID, ok := claims["user_id"].(string)
-	if !ok {
-		return "", errors.ErrInvalidField("claims")
+	if claims.UserID == "" || claims.ID == "" || claims.Purpose != purpose {
+		return nil, errors.ErrInvalidField("claims")
 	}
-	return userID, nil
-}
\ No newline at end of file
+	return claims, nil
+}
@@ -0,0 +1,343 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+)
+
+// Asymmetric signing keys are PEM files in JWT_KEYS_DIR; the file name without ".pem" is the key ID (kid).
+// Private keys (PKCS#8, or PKCS#1 for RSA) can sign and verify; public keys ("PUBLIC KEY") only verify,
+// which lets a retired key keep validating tokens until they expire. RSA keys sign with RS256 and
+// Ed25519 keys with EdDSA.
+//
+// Rotation: add a new key to the directory. It is published in the JWKS right away but only used for
+// signing once it is older than JWT_KEY_PUBLISH_DELAY, so verifiers have time to fetch it. Remove the
+// previous key once every token it signed has expired. The directory is re-read every
+// JWT_KEYS_RELOAD_INTERVAL, so rotations need no restart.
+//
+// Without a key directory, tokens are signed with HS256 using JWT_SECRET. HS256 tokens keep being
+// accepted while JWT_SECRET is set, which allows migrating to asymmetric keys without logging users out.
+
+// minRSAKeyBits is the smallest RSA key accepted for signing or verification.
+const minRSAKeyBits = 2048
+
+// signingKey is a key loaded from the key directory.
+type signingKey struct {
+	kid     string
+	alg     string
+	private crypto.Signer    // nil for verification-only keys
+	public  crypto.PublicKey // *rsa.PublicKey or ed25519.PublicKey
+	modTime time.Time
+}
+
+// method returns the JWT signing method for the key.
+func (k *signingKey) method() jwt.SigningMethod {
+	if k.alg == jwt.SigningMethodEdDSA.Alg() {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// keySet is the in-process copy of the key directory.
+type keySet struct {
+	mu       sync.RWMutex
+	dir      string
+	keys     map[string]*signingKey
+	active   *signingKey
+	loadedAt time.Time
+}
+
+var signingKeys = &keySet{}
+
+// jwtKeysDir returns the configured key directory (JWT_KEYS_DIR), or "" for HS256 signing.
+func jwtKeysDir() string {
+	return os.Getenv("JWT_KEYS_DIR")
+}
+
+// hmacSecret returns the HS256 secret (JWT_SECRET). It is read on use so values loaded from .env apply.
+func hmacSecret() []byte {
+	return []byte(os.Getenv("JWT_SECRET"))
+}
+
+// current returns the key set, reloading the key directory when it changed or the reload interval elapsed.
+func (s *keySet) current() *keySet {
+	dir := jwtKeysDir()
+
+	s.mu.RLock()
+	fresh := s.dir == dir && time.Since(s.loadedAt) < DurationFromEnv("JWT_KEYS_RELOAD_INTERVAL", time.Minute)
+	s.mu.RUnlock()
+	if !fresh {
+		s.reload(dir)
+	}
+	return s
+}
+
+// reload reads the key directory. On failure the previously loaded keys are kept.
+func (s *keySet) reload(dir string) {
+	keys := map[string]*signingKey{}
+	if dir != "" {
+		var err error
+		keys, err = loadSigningKeys(dir)
+		if err != nil {
+			log.Printf("Failed to load JWT signing keys: %v", err)
+			s.mu.Lock()
+			if s.dir != dir {
+				// Never fall back to keys from another directory or to HS256.
+				s.keys, s.active = nil, nil
+			}
+			s.dir = dir
+			s.loadedAt = time.Now()
+			s.mu.Unlock()
+			return
+		}
+	}
+
+	active := selectActiveKey(keys, DurationFromEnv("JWT_KEY_PUBLISH_DELAY", 10*time.Minute))
+
+	s.mu.Lock()
+	s.dir = dir
+	s.keys = keys
+	s.active = active
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+}
+
+// ReloadSigningKeys forces the key directory to be read again on the next use.
+func ReloadSigningKeys() {
+	signingKeys.mu.Lock()
+	signingKeys.loadedAt = time.Time{}
+	signingKeys.mu.Unlock()
+}
+
+// selectActiveKey returns the newest private key published for at least delay.
+// If no key is old enough (e.g. on first start), the newest private key is used.
+func selectActiveKey(keys map[string]*signingKey, delay time.Duration) *signingKey {
+	var newest, published *signingKey
+	cutoff := time.Now().Add(-delay)
+
+	for _, k := range keys {
+		if k.private == nil {
+			continue
+		}
+		if newest == nil || k.modTime.After(newest.modTime) {
+			newest = k
+		}
+		if !k.modTime.After(cutoff) && (published == nil || k.modTime.After(published.modTime)) {
+			published = k
+		}
+	}
+
+	if published != nil {
+		return published
+	}
+	return newest
+}
+
+// loadSigningKeys parses every *.pem file in dir.
+func loadSigningKeys(dir string) (map[string]*signingKey, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*signingKey, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+		key, err := parseSigningKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		key.modTime = info.ModTime()
+		keys[kid] = key
+	}
+	return keys, nil
+}
+
+// parseSigningKey decodes a PEM encoded RSA or Ed25519 key.
+func parseSigningKey(kid string, data []byte) (*signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{kid: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.alg, key.private, key.public = jwt.SigningMethodRS256.Alg(), k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.alg, key.public = jwt.SigningMethodRS256.Alg(), k
+	case ed25519.PrivateKey:
+		key.alg, key.private, key.public = jwt.SigningMethodEdDSA.Alg(), k, k.Public()
+	case ed25519.PublicKey:
+		key.alg, key.public = jwt.SigningMethodEdDSA.Alg(), k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	if rsaKey, ok := key.public.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("RSA keys must be at least %d bits", minRSAKeyBits)
+	}
+	return key, nil
+}
+
+// signClaims signs the claims with the active key, or with the HS256 secret when no key directory is configured.
+func signClaims(claims jwt.Claims) (string, error) {
+	set := signingKeys.current()
+	set.mu.RLock()
+	active, dir := set.active, set.dir
+	set.mu.RUnlock()
+
+	if active != nil {
+		token := jwt.NewWithClaims(active.method(), claims)
+		token.Header["kid"] = active.kid
+		return token.SignedString(active.private)
+	}
+
+	if dir != "" {
+		return "", fmt.Errorf("no signing key found in %s", dir)
+	}
+	secret := hmacSecret()
+	if len(secret) == 0 {
+		return "", fmt.Errorf("no JWT signing key configured")
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+}
+
+// verificationKey returns the key that must have signed the token, based on its kid and alg headers.
+func verificationKey(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	if kid == "" {
+		if t.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+			return nil, fmt.Errorf("missing kid")
+		}
+		secret := hmacSecret()
+		if len(secret) == 0 {
+			return nil, fmt.Errorf("HS256 tokens are not accepted")
+		}
+		return secret, nil
+	}
+
+	set := signingKeys.current()
+	set.mu.RLock()
+	key, ok := set.keys[kid]
+	set.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	if t.Method.Alg() != key.alg {
+		return nil, fmt.Errorf("algorithm %s does not match key %q", t.Method.Alg(), kid)
+	}
+	return key.public, nil
+}
+
+// PublicJWKs returns every verification key from the key directory, sorted by kid.
+// HS256 secrets are never published.
+func PublicJWKs() []dto.JWK {
+	set := signingKeys.current()
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+
+	jwks := make([]dto.JWK, 0, len(set.keys))
+	for _, key := range set.keys {
+		jwk := dto.JWK{Kid: key.kid, Use: "sig", Alg: key.alg}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		jwks = append(jwks, jwk)
+	}
+
+	// Keep the document stable across reloads
+	sort.Slice(jwks, func(i, j int) bool { return jwks[i].Kid < jwks[j].Kid })
+	return jwks
+}
+
+// GenerateSigningKey creates a new private key of the given type ("ed25519" or "rsa") in dir
+// and returns its kid, derived from the current time so newer keys sort last.
+func GenerateSigningKey(dir string, keyType string) (string, error) {
+	var private interface{}
+	var err error
+	switch keyType {
+	case "ed25519":
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	case "rsa":
+		private, err = rsa.GenerateKey(rand.Reader, 3072)
+	default:
+		return "", fmt.Errorf("unsupported key type %q (use ed25519 or rsa)", keyType)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	kid := time.Now().UTC().Format("20060102T150405Z")
+	path := filepath.Join(dir, kid+".pem")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if err := pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		return "", err
+	}
+	return kid, nil
+}
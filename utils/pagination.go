@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+)
+
+// Page size limits for paginated endpoints.
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ParsePagination reads the page (1-based) and limit query parameters, applying defaults when absent.
+func ParsePagination(r *http.Request) (page int, limit int, err error) {
+	page, limit = 1, DefaultPageSize
+
+	if v := r.URL.Query().Get("page"); v != "" {
+		page, err = strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return 0, 0, errors.NewValidationError("page must be a positive integer")
+		}
+	}
+
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > MaxPageSize {
+			return 0, 0, errors.NewValidationError("limit must be between 1 and " + strconv.Itoa(MaxPageSize))
+		}
+	}
+
+	return page, limit, nil
+}
+
+// ParseCursorPagination reads the opaque cursor and limit query parameters of a keyset-paginated endpoint.
+// An empty cursor requests the first page.
+func ParseCursorPagination(r *http.Request) (cursor string, limit int, err error) {
+	limit = DefaultPageSize
+
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > MaxPageSize {
+			return "", 0, errors.NewValidationError("limit must be between 1 and " + strconv.Itoa(MaxPageSize))
+		}
+	}
+
+	return r.URL.Query().Get("cursor"), limit, nil
+}
+
+// EncodeCursor serializes the sort key of the last item of a page into an opaque cursor.
+func EncodeCursor(key interface{}) (string, error) {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor into key.
+func DecodeCursor(cursor string, key interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return errors.NewValidationError("invalid cursor")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(key); err != nil {
+		return errors.NewValidationError("invalid cursor")
+	}
+	return nil
+}
+
+// SetPaginationLinks sets the Link header of a keyset-paginated response (RFC 8288): a "first" link
+// and, unless this is the last page, a "next" link. Other query parameters of the request are kept.
+func SetPaginationLinks(w http.ResponseWriter, r *http.Request, nextCursor string) {
+	link := func(cursor string, rel string) string {
+		query := r.URL.Query()
+		query.Del("cursor")
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		target := AppURL() + r.URL.Path
+		if encoded := query.Encode(); encoded != "" {
+			target += "?" + encoded
+		}
+		return fmt.Sprintf(`<%s>; rel="%s"`, target, rel)
+	}
+
+	links := []string{link("", "first")}
+	if nextCursor != "" {
+		links = append(links, link(nextCursor, "next"))
+	}
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ClientIP returns the IP address of the client that sent the request.
+// X-Forwarded-For is only trusted when TRUST_PROXY_HEADERS=true, i.e. when the API
+// runs behind a reverse proxy that sets it.
+func ClientIP(r *http.Request) string {
+	if os.Getenv("TRUST_PROXY_HEADERS") == "true" {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ClientInfo identifies the client a login was made from.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// Client returns the IP address and user agent of the client that sent the request.
+func Client(r *http.Request) ClientInfo {
+	return ClientInfo{IP: ClientIP(r), UserAgent: r.UserAgent()}
+}
@@ -2,7 +2,10 @@ package utils
 
 import (
 	"encoding/json"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/kfeuerschvenger/task-manager-api/dto"
 )
@@ -28,3 +31,13 @@ func Error(w http.ResponseWriter, status int, message string) {
 	resp := dto.ErrorResponse{Code: status, Message: message}
 	JSON(w, status, resp)
 }
+
+// TooManyRequests sends a 429 error response with a Retry-After header (in whole seconds, rounded up).
+func TooManyRequests(w http.ResponseWriter, retryAfter time.Duration, message string) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	Error(w, http.StatusTooManyRequests, message)
+}
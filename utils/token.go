@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// GenerateOpaqueToken returns a random URL-safe token together with its SHA-256 hash.
+// Only the hash should be persisted; the plain token is handed to the client once.
+func GenerateOpaqueToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the hex-encoded SHA-256 hash of an opaque token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// PersonalAccessTokenPrefix marks personal access tokens so they can be told apart from JWTs.
+const PersonalAccessTokenPrefix = "tmpat_"
+
+// GeneratePersonalAccessToken returns a new personal access token of the form
+// "tmpat_<lookup>_<secret>", together with its lookup prefix and the SHA-256 hash of the full token.
+func GeneratePersonalAccessToken() (string, string, string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	lookup := hex.EncodeToString(b)
+
+	secret, _, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	token := PersonalAccessTokenPrefix + lookup + "_" + secret
+	return token, lookup, HashToken(token), nil
+}
+
+// ParsePersonalAccessToken extracts the lookup prefix from a personal access token.
+// It returns false if the value is not shaped like one.
+func ParsePersonalAccessToken(token string) (string, bool) {
+	if !strings.HasPrefix(token, PersonalAccessTokenPrefix) {
+		return "", false
+	}
+	rest := strings.TrimPrefix(token, PersonalAccessTokenPrefix)
+	lookup, secret, found := strings.Cut(rest, "_")
+	if !found || len(lookup) != 12 || secret == "" {
+		return "", false
+	}
+	return lookup, true
+}
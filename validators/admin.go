@@ -0,0 +1,17 @@
+package validators
+
+import (
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+)
+
+// ValidateUpdateRoleInput checks that the requested role is one of the known roles.
+func ValidateUpdateRoleInput(req dto.UpdateRoleRequest) error {
+	for _, role := range models.Roles {
+		if req.Role == role {
+			return nil
+		}
+	}
+	return errors.NewValidationError("role must be one of: admin, member, viewer")
+}
@@ -50,4 +50,26 @@ func ValidateLoginInput(req dto.LoginRequest) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+// ValidateForgotPasswordInput checks that the password reset request carries a valid email.
+func ValidateForgotPasswordInput(req dto.ForgotPasswordRequest) error {
+	if _, err := mail.ParseAddress(utils.CleanEmail(req.Email)); err != nil {
+		return errors.NewValidationError("invalid email format")
+	}
+
+	return nil
+}
+
+// ValidateResetPasswordInput checks the validity of the password reset input.
+// It ensures a token is present and the new password is at least 6 characters long.
+func ValidateResetPasswordInput(req dto.ResetPasswordRequest) error {
+	if strings.TrimSpace(req.Token) == "" {
+		return errors.NewValidationError("token is required")
+	}
+
+	if len(req.Password) < 6 {
+		return errors.NewValidationError("password must be at least 6 characters")
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package validators
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+)
+
+// maxChecklistItemLength caps the length of checklist items, in characters.
+const maxChecklistItemLength = 500
+
+// ValidateCreateChecklistItemInput checks the text and the optional position of a new checklist item.
+func ValidateCreateChecklistItemInput(req dto.CreateChecklistItemRequest) error {
+	if err := validateChecklistItemText(req.Text); err != nil {
+		return err
+	}
+	if req.Position != nil && *req.Position < 0 {
+		return errors.NewValidationError("position must not be negative")
+	}
+	return nil
+}
+
+// ValidateUpdateChecklistItemInput checks the fields of a checklist item update, at least one of which must be set.
+func ValidateUpdateChecklistItemInput(req dto.UpdateChecklistItemRequest) error {
+	if req.Text == "" && req.Done == nil && req.Position == nil {
+		return errors.NewValidationError("text, done or position is required")
+	}
+	if req.Text != "" {
+		if err := validateChecklistItemText(req.Text); err != nil {
+			return err
+		}
+	}
+	if req.Position != nil && *req.Position < 0 {
+		return errors.NewValidationError("position must not be negative")
+	}
+	return nil
+}
+
+// validateChecklistItemText checks that the text of a checklist item is not blank and not too long.
+func validateChecklistItemText(text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return errors.NewValidationError("text is required")
+	}
+	if utf8.RuneCountInString(text) > maxChecklistItemLength {
+		return errors.NewValidationError("text must be at most 500 characters")
+	}
+	return nil
+}
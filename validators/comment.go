@@ -0,0 +1,23 @@
+package validators
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+)
+
+// maxCommentLength caps the length of comment bodies, in characters.
+const maxCommentLength = 10000
+
+// ValidateCommentInput checks that the body of a comment is not blank and not too long.
+func ValidateCommentInput(req dto.CommentRequest) error {
+	if strings.TrimSpace(req.Body) == "" {
+		return errors.NewValidationError("body is required")
+	}
+	if utf8.RuneCountInString(req.Body) > maxCommentLength {
+		return errors.NewValidationError("body must be at most 10000 characters")
+	}
+	return nil
+}
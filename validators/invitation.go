@@ -0,0 +1,21 @@
+package validators
+
+import (
+	"net/mail"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+)
+
+// ValidateCreateInvitationInput checks the invitee's email and the optional role.
+func ValidateCreateInvitationInput(req dto.CreateInvitationRequest) error {
+	if _, err := mail.ParseAddress(utils.CleanEmail(req.Email)); err != nil {
+		return errors.NewValidationError("invalid email format")
+	}
+
+	if req.Role != "" {
+		return ValidateUpdateRoleInput(dto.UpdateRoleRequest{Role: req.Role})
+	}
+	return nil
+}
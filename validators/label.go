@@ -0,0 +1,59 @@
+package validators
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+)
+
+// maxLabelNameLength caps the length of label names, in characters.
+const maxLabelNameLength = 50
+
+// labelColorPattern matches hex colors such as #2563eb.
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// ValidateCreateLabelInput checks the name, the optional color and the optional scope of a new label.
+func ValidateCreateLabelInput(req dto.CreateLabelRequest) error {
+	if err := validateLabelName(req.Name); err != nil {
+		return err
+	}
+	if req.Color != "" && !labelColorPattern.MatchString(req.Color) {
+		return errors.NewValidationError("color must be a hex color such as #2563eb")
+	}
+	if req.Scope != "" && req.Scope != models.LabelScopePersonal && req.Scope != models.LabelScopeTeam {
+		return errors.NewValidationError("scope must be personal or team")
+	}
+	return nil
+}
+
+// ValidateUpdateLabelInput checks the fields of a label update, at least one of which must be set.
+func ValidateUpdateLabelInput(req dto.UpdateLabelRequest) error {
+	if req.Name == "" && req.Color == "" {
+		return errors.NewValidationError("name or color is required")
+	}
+	if req.Name != "" {
+		if err := validateLabelName(req.Name); err != nil {
+			return err
+		}
+	}
+	if req.Color != "" && !labelColorPattern.MatchString(req.Color) {
+		return errors.NewValidationError("color must be a hex color such as #2563eb")
+	}
+	return nil
+}
+
+// validateLabelName checks that a label name is not blank and not too long.
+func validateLabelName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.NewValidationError("name is required")
+	}
+	if utf8.RuneCountInString(name) > maxLabelNameLength {
+		return errors.NewValidationError("name must be at most 50 characters")
+	}
+	return nil
+}
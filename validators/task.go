@@ -0,0 +1,62 @@
+package validators
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+)
+
+// ValidateTaskListQuery checks the filter values of a task listing.
+// The sort order is checked by the task service, which owns the list of sortable fields.
+func ValidateTaskListQuery(q dto.TaskListQuery) error {
+	for _, status := range q.Statuses {
+		if !contains(models.TaskStatuses, status) {
+			return errors.NewValidationError("status must be one of: " + strings.Join(models.TaskStatuses, ", "))
+		}
+	}
+
+	for _, priority := range q.Priorities {
+		if !contains(models.TaskPriorities, priority) {
+			return errors.NewValidationError("priority must be one of: " + strings.Join(models.TaskPriorities, ", "))
+		}
+	}
+
+	if q.Role != "" && q.Role != dto.TaskRoleCreator && q.Role != dto.TaskRoleAssignee {
+		return errors.NewValidationError("role must be creator or assignee")
+	}
+
+	if q.AssigneeID != "" {
+		if _, err := uuid.Parse(q.AssigneeID); err != nil {
+			return errors.ErrInvalidID("assignee")
+		}
+	}
+
+	for _, labelID := range q.Labels {
+		if _, err := uuid.Parse(labelID); err != nil {
+			return errors.ErrInvalidID("label")
+		}
+	}
+
+	if q.LabelMatch != "" && q.LabelMatch != dto.TaskLabelMatchAny && q.LabelMatch != dto.TaskLabelMatchAll {
+		return errors.NewValidationError("label_match must be any or all")
+	}
+
+	if q.DueAfter != nil && q.DueBefore != nil && !q.DueAfter.Before(*q.DueBefore) {
+		return errors.NewValidationError("due_after must be before due_before")
+	}
+
+	return nil
+}
+
+// contains reports whether values includes value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
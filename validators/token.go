@@ -0,0 +1,50 @@
+package validators
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/models"
+)
+
+// ValidateCreatePersonalAccessTokenInput checks the validity of a personal access token request.
+// It ensures the token has a name, at least one known scope, and an expiration in the future if set.
+func ValidateCreatePersonalAccessTokenInput(req dto.CreatePersonalAccessTokenRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return errors.NewValidationError("name must be between 1 and 100 characters")
+	}
+
+	if len(req.Scopes) == 0 {
+		return errors.NewValidationError("at least one scope is required")
+	}
+	for _, scope := range req.Scopes {
+		if !isKnownScope(scope) {
+			return errors.NewValidationError("unknown scope: " + scope)
+		}
+	}
+
+	if req.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return errors.NewValidationError("invalid expires_at")
+		}
+		if !expiresAt.After(time.Now()) {
+			return errors.NewValidationError("expires_at must be in the future")
+		}
+	}
+
+	return nil
+}
+
+// isKnownScope reports whether scope can be granted to a personal access token.
+func isKnownScope(scope string) bool {
+	for _, known := range models.AllScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
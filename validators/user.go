@@ -0,0 +1,82 @@
+package validators
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kfeuerschvenger/task-manager-api/dto"
+	"github.com/kfeuerschvenger/task-manager-api/errors"
+	"github.com/kfeuerschvenger/task-manager-api/utils"
+	"golang.org/x/text/language"
+)
+
+// usernamePattern matches the handles users can be @mentioned by, in lowercase. Usernames do not
+// end with a dot or hyphen, so that punctuation after a mention is not taken as part of it.
+var usernamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{1,28}[a-z0-9_]$`)
+
+// ValidateUpdateProfileInput checks the fields present in a profile update.
+// Names must be at least 2 characters, the username a valid handle, the timezone an IANA zone name and the locale a BCP 47 tag.
+func ValidateUpdateProfileInput(req dto.UpdateProfileRequest) error {
+	if req.FirstName == "" && req.LastName == "" && req.Username == "" && req.Timezone == "" && req.Locale == "" {
+		return errors.NewValidationError("no fields to update")
+	}
+
+	if req.FirstName != "" && len(strings.TrimSpace(req.FirstName)) < 2 {
+		return errors.NewValidationError("first name must be at least 2 characters")
+	}
+
+	if req.LastName != "" && len(strings.TrimSpace(req.LastName)) < 2 {
+		return errors.NewValidationError("last name must be at least 2 characters")
+	}
+
+	if req.Username != "" && !usernamePattern.MatchString(strings.ToLower(req.Username)) {
+		return errors.NewValidationError("username must be 3-30 letters, digits, dots, hyphens or underscores, starting with a letter or digit")
+	}
+
+	if req.Timezone != "" {
+		// LoadLocation also accepts "" and "Local", which are not portable zone names.
+		if req.Timezone == "Local" {
+			return errors.NewValidationError("invalid timezone")
+		}
+		if _, err := time.LoadLocation(req.Timezone); err != nil {
+			return errors.NewValidationError("invalid timezone")
+		}
+	}
+
+	if req.Locale != "" {
+		if _, err := language.Parse(req.Locale); err != nil {
+			return errors.NewValidationError("invalid locale")
+		}
+	}
+
+	return nil
+}
+
+// ValidateChangePasswordInput checks that the current password is present
+// and the new password is at least 6 characters long.
+func ValidateChangePasswordInput(req dto.ChangePasswordRequest) error {
+	if len(req.CurrentPassword) == 0 {
+		return errors.NewValidationError("current password cannot be empty")
+	}
+
+	if len(req.NewPassword) < 6 {
+		return errors.NewValidationError("password must be at least 6 characters")
+	}
+
+	return nil
+}
+
+// ValidateChangeEmailInput checks the new email format and that the password is present.
+func ValidateChangeEmailInput(req dto.ChangeEmailRequest) error {
+	if _, err := mail.ParseAddress(utils.CleanEmail(req.Email)); err != nil {
+		return errors.NewValidationError("invalid email format")
+	}
+
+	if len(req.Password) == 0 {
+		return errors.NewValidationError("password cannot be empty")
+	}
+
+	return nil
+}